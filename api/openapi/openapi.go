@@ -0,0 +1,9 @@
+// Package openapi — описание REST API (/api/v2) в формате OpenAPI 2.0, сгенерированное из api/proto (buf generate)
+package openapi
+
+import _ "embed"
+
+// Spec — содержимое tr181_api.swagger.json
+//
+//go:embed tr181_api.swagger.json
+var Spec []byte
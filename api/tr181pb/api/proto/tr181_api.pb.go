@@ -7,6 +7,7 @@
 package tr181pb
 
 import (
+	_ "google.golang.org/genproto/googleapis/api/annotations"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -21,12 +22,116 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Ошибки API. gRPC: код статуса плюс details google.rpc.ErrorInfo (reason — имя кода без префикса
+// ERROR_CODE_, domain — tr181.api) и google.rpc.BadRequest с полями запроса для ошибок валидации.
+// HTTP: тело {"error": "<сообщение>", "code": "<reason>", "details": [{"field": ..., "description": ...}]}.
+//
+// Соответствие кодов:
+//
+//	MISSING_PARAMETER, INVALID_PARAMETER, INVALID_METRIC_TYPE,
+//	INVALID_ALERT_TYPE, INVALID_TIME_RANGE, LIMIT_EXCEEDED  → INVALID_ARGUMENT / 400
+//	UNAUTHENTICATED                                         → UNAUTHENTICATED / 401
+//	PERMISSION_DENIED                                       → PERMISSION_DENIED / 403
+//	NOT_FOUND                                               → NOT_FOUND / 404
+//	RATE_LIMITED                                            → RESOURCE_EXHAUSTED / 429
+//	INTERNAL                                                → INTERNAL / 500
+//	STORAGE_UNAVAILABLE                                     → UNAVAILABLE / 503 (можно повторить)
+//	DEADLINE_EXCEEDED                                       → DEADLINE_EXCEEDED / 504 (можно повторить с меньшим периодом)
+type ErrorCode int32
+
+const (
+	ErrorCode_ERROR_CODE_UNSPECIFIED         ErrorCode = 0
+	ErrorCode_ERROR_CODE_MISSING_PARAMETER   ErrorCode = 1  // не задан обязательный параметр
+	ErrorCode_ERROR_CODE_INVALID_PARAMETER   ErrorCode = 2  // значение параметра не разбирается
+	ErrorCode_ERROR_CODE_INVALID_METRIC_TYPE ErrorCode = 3  // неизвестный тип метрики
+	ErrorCode_ERROR_CODE_INVALID_ALERT_TYPE  ErrorCode = 4  // неизвестный тип алерта
+	ErrorCode_ERROR_CODE_INVALID_TIME_RANGE  ErrorCode = 5  // некорректный период (from/to)
+	ErrorCode_ERROR_CODE_LIMIT_EXCEEDED      ErrorCode = 6  // превышены лимиты запроса (точки, период, число устройств)
+	ErrorCode_ERROR_CODE_UNAUTHENTICATED     ErrorCode = 7  // нет учётных данных или они недействительны
+	ErrorCode_ERROR_CODE_PERMISSION_DENIED   ErrorCode = 8  // нет доступа к действию или устройству
+	ErrorCode_ERROR_CODE_NOT_FOUND           ErrorCode = 9  // данные не найдены
+	ErrorCode_ERROR_CODE_RATE_LIMITED        ErrorCode = 10 // превышена квота запросов
+	ErrorCode_ERROR_CODE_INTERNAL            ErrorCode = 11 // внутренняя ошибка сервера
+	ErrorCode_ERROR_CODE_STORAGE_UNAVAILABLE ErrorCode = 12 // PostgreSQL или Redis недоступны
+	ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED   ErrorCode = 13 // запрос не уложился в отведённое время
+)
+
+// Enum value maps for ErrorCode.
+var (
+	ErrorCode_name = map[int32]string{
+		0:  "ERROR_CODE_UNSPECIFIED",
+		1:  "ERROR_CODE_MISSING_PARAMETER",
+		2:  "ERROR_CODE_INVALID_PARAMETER",
+		3:  "ERROR_CODE_INVALID_METRIC_TYPE",
+		4:  "ERROR_CODE_INVALID_ALERT_TYPE",
+		5:  "ERROR_CODE_INVALID_TIME_RANGE",
+		6:  "ERROR_CODE_LIMIT_EXCEEDED",
+		7:  "ERROR_CODE_UNAUTHENTICATED",
+		8:  "ERROR_CODE_PERMISSION_DENIED",
+		9:  "ERROR_CODE_NOT_FOUND",
+		10: "ERROR_CODE_RATE_LIMITED",
+		11: "ERROR_CODE_INTERNAL",
+		12: "ERROR_CODE_STORAGE_UNAVAILABLE",
+		13: "ERROR_CODE_DEADLINE_EXCEEDED",
+	}
+	ErrorCode_value = map[string]int32{
+		"ERROR_CODE_UNSPECIFIED":         0,
+		"ERROR_CODE_MISSING_PARAMETER":   1,
+		"ERROR_CODE_INVALID_PARAMETER":   2,
+		"ERROR_CODE_INVALID_METRIC_TYPE": 3,
+		"ERROR_CODE_INVALID_ALERT_TYPE":  4,
+		"ERROR_CODE_INVALID_TIME_RANGE":  5,
+		"ERROR_CODE_LIMIT_EXCEEDED":      6,
+		"ERROR_CODE_UNAUTHENTICATED":     7,
+		"ERROR_CODE_PERMISSION_DENIED":   8,
+		"ERROR_CODE_NOT_FOUND":           9,
+		"ERROR_CODE_RATE_LIMITED":        10,
+		"ERROR_CODE_INTERNAL":            11,
+		"ERROR_CODE_STORAGE_UNAVAILABLE": 12,
+		"ERROR_CODE_DEADLINE_EXCEEDED":   13,
+	}
+)
+
+func (x ErrorCode) Enum() *ErrorCode {
+	p := new(ErrorCode)
+	*p = x
+	return p
+}
+
+func (x ErrorCode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCode) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_tr181_api_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCode) Type() protoreflect.EnumType {
+	return &file_api_proto_tr181_api_proto_enumTypes[0]
+}
+
+func (x ErrorCode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCode.Descriptor instead.
+func (ErrorCode) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{0}
+}
+
 type MetricRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	MetricType    string                 `protobuf:"bytes,1,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`       // например cpu-usage
 	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"` // серийный номер устройства
-	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`                                    // Unix timestamp начала периода
-	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                                        // Unix timestamp конца периода
+	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`                                    // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                                        // Unix timestamp конца периода (0 — сейчас)
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                                  // максимум точек в ответе (0 — лимит сервера по умолчанию)
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`          // next_page_token из предыдущего ответа
+	Downsample    bool                   `protobuf:"varint,7,opt,name=downsample,proto3" json:"downsample,omitempty"`                        // true — усреднить по интервалам вместо постраничной выдачи
+	FromExpr      string                 `protobuf:"bytes,8,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"`             // начало периода строкой, как from в HTTP: RFC3339, now-6h, now-7d/d (вместо from)
+	ToExpr        string                 `protobuf:"bytes,9,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`                   // конец периода строкой, как to в HTTP (вместо to)
+	Range         string                 `protobuf:"bytes,10,opt,name=range,proto3" json:"range,omitempty"`                                  // именованный период: today, yesterday, this-week, last-week, this-month, last-month
+	Tz            string                 `protobuf:"bytes,11,opt,name=tz,proto3" json:"tz,omitempty"`                                        // часовой пояс IANA для округления и именованных периодов (по умолчанию UTC)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -89,6 +194,55 @@ func (x *MetricRequest) GetTo() int64 {
 	return 0
 }
 
+func (x *MetricRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *MetricRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *MetricRequest) GetDownsample() bool {
+	if x != nil {
+		return x.Downsample
+	}
+	return false
+}
+
+func (x *MetricRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *MetricRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *MetricRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *MetricRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
 type MetricValue struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         int32                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`
@@ -144,6 +298,8 @@ func (x *MetricValue) GetTime() int64 {
 type MetricResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Metrics       []*MetricValue         `protobuf:"bytes,1,rep,name=metrics,proto3" json:"metrics,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"` // пусто — страниц больше нет
+	BucketSeconds int64                  `protobuf:"varint,3,opt,name=bucket_seconds,json=bucketSeconds,proto3" json:"bucket_seconds,omitempty"`  // интервал усреднения при downsample (0 — сырые точки)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -185,30 +341,49 @@ func (x *MetricResponse) GetMetrics() []*MetricValue {
 	return nil
 }
 
-type AlertRequest struct {
+func (x *MetricResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+func (x *MetricResponse) GetBucketSeconds() int64 {
+	if x != nil {
+		return x.BucketSeconds
+	}
+	return 0
+}
+
+type MetricSummaryRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	AlertType     string                 `protobuf:"bytes,1,opt,name=alert_type,json=alertType,proto3" json:"alert_type,omitempty"` // например high-cpu-usage
+	MetricType    string                 `protobuf:"bytes,1,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
 	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
-	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`
-	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`
+	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`                                        // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                                            // Unix timestamp конца периода (0 — сейчас)
+	FromExpr      string                 `protobuf:"bytes,5,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"`                 // начало периода строкой (см. MetricRequest.from_expr)
+	ToExpr        string                 `protobuf:"bytes,6,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`                       // конец периода строкой
+	Range         string                 `protobuf:"bytes,7,opt,name=range,proto3" json:"range,omitempty"`                                       // именованный период
+	Tz            string                 `protobuf:"bytes,8,opt,name=tz,proto3" json:"tz,omitempty"`                                             // часовой пояс IANA
+	BucketSeconds int64                  `protobuf:"varint,9,opt,name=bucket_seconds,json=bucketSeconds,proto3" json:"bucket_seconds,omitempty"` // > 0 — дополнительно статистика по интервалам такой длины
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AlertRequest) Reset() {
-	*x = AlertRequest{}
+func (x *MetricSummaryRequest) Reset() {
+	*x = MetricSummaryRequest{}
 	mi := &file_api_proto_tr181_api_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AlertRequest) String() string {
+func (x *MetricSummaryRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AlertRequest) ProtoMessage() {}
+func (*MetricSummaryRequest) ProtoMessage() {}
 
-func (x *AlertRequest) ProtoReflect() protoreflect.Message {
+func (x *MetricSummaryRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_tr181_api_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -220,61 +395,102 @@ func (x *AlertRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AlertRequest.ProtoReflect.Descriptor instead.
-func (*AlertRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use MetricSummaryRequest.ProtoReflect.Descriptor instead.
+func (*MetricSummaryRequest) Descriptor() ([]byte, []int) {
 	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *AlertRequest) GetAlertType() string {
+func (x *MetricSummaryRequest) GetMetricType() string {
 	if x != nil {
-		return x.AlertType
+		return x.MetricType
 	}
 	return ""
 }
 
-func (x *AlertRequest) GetSerialNumber() string {
+func (x *MetricSummaryRequest) GetSerialNumber() string {
 	if x != nil {
 		return x.SerialNumber
 	}
 	return ""
 }
 
-func (x *AlertRequest) GetFrom() int64 {
+func (x *MetricSummaryRequest) GetFrom() int64 {
 	if x != nil {
 		return x.From
 	}
 	return 0
 }
 
-func (x *AlertRequest) GetTo() int64 {
+func (x *MetricSummaryRequest) GetTo() int64 {
 	if x != nil {
 		return x.To
 	}
 	return 0
 }
 
-type AlertResponse struct {
+func (x *MetricSummaryRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *MetricSummaryRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *MetricSummaryRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *MetricSummaryRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *MetricSummaryRequest) GetBucketSeconds() int64 {
+	if x != nil {
+		return x.BucketSeconds
+	}
+	return 0
+}
+
+type MetricSummary struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Value         int32                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"` // среднее значение за период
-	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // количество алертов
+	Time          int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`   // начало интервала (Unix), 0 — весь период
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"` // число точек; 0 — остальные поля не заданы
+	Min           int32                  `protobuf:"varint,3,opt,name=min,proto3" json:"min,omitempty"`
+	Max           int32                  `protobuf:"varint,4,opt,name=max,proto3" json:"max,omitempty"`
+	Mean          float64                `protobuf:"fixed64,5,opt,name=mean,proto3" json:"mean,omitempty"`
+	Median        float64                `protobuf:"fixed64,6,opt,name=median,proto3" json:"median,omitempty"`
+	P95           float64                `protobuf:"fixed64,7,opt,name=p95,proto3" json:"p95,omitempty"`
+	Stddev        float64                `protobuf:"fixed64,8,opt,name=stddev,proto3" json:"stddev,omitempty"` // выборочное стандартное отклонение
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AlertResponse) Reset() {
-	*x = AlertResponse{}
+func (x *MetricSummary) Reset() {
+	*x = MetricSummary{}
 	mi := &file_api_proto_tr181_api_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AlertResponse) String() string {
+func (x *MetricSummary) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AlertResponse) ProtoMessage() {}
+func (*MetricSummary) ProtoMessage() {}
 
-func (x *AlertResponse) ProtoReflect() protoreflect.Message {
+func (x *MetricSummary) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_tr181_api_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -286,53 +502,1653 @@ func (x *AlertResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AlertResponse.ProtoReflect.Descriptor instead.
-func (*AlertResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use MetricSummary.ProtoReflect.Descriptor instead.
+func (*MetricSummary) Descriptor() ([]byte, []int) {
 	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *AlertResponse) GetValue() int32 {
+func (x *MetricSummary) GetTime() int64 {
 	if x != nil {
-		return x.Value
+		return x.Time
 	}
 	return 0
 }
 
-func (x *AlertResponse) GetCount() int32 {
+func (x *MetricSummary) GetCount() int64 {
 	if x != nil {
 		return x.Count
 	}
 	return 0
 }
 
-var File_api_proto_tr181_api_proto protoreflect.FileDescriptor
+func (x *MetricSummary) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
 
-const file_api_proto_tr181_api_proto_rawDesc = "" +
-	"\n" +
-	"\x19api/proto/tr181_api.proto\x12\ttr181.api\"y\n" +
-	"\rMetricRequest\x12\x1f\n" +
-	"\vmetric_type\x18\x01 \x01(\tR\n" +
-	"metricType\x12#\n" +
-	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
-	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
-	"\x02to\x18\x04 \x01(\x03R\x02to\"7\n" +
-	"\vMetricValue\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\x05R\x05value\x12\x12\n" +
-	"\x04time\x18\x02 \x01(\x03R\x04time\"B\n" +
-	"\x0eMetricResponse\x120\n" +
-	"\ametrics\x18\x01 \x03(\v2\x16.tr181.api.MetricValueR\ametrics\"v\n" +
-	"\fAlertRequest\x12\x1d\n" +
-	"\n" +
-	"alert_type\x18\x01 \x01(\tR\talertType\x12#\n" +
-	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
-	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
-	"\x02to\x18\x04 \x01(\x03R\x02to\";\n" +
-	"\rAlertResponse\x12\x14\n" +
-	"\x05value\x18\x01 \x01(\x05R\x05value\x12\x14\n" +
-	"\x05count\x18\x02 \x01(\x05R\x05count2\x8b\x01\n" +
-	"\bTR181Api\x12@\n" +
-	"\tGetMetric\x12\x18.tr181.api.MetricRequest\x1a\x19.tr181.api.MetricResponse\x12=\n" +
-	"\bGetAlert\x12\x17.tr181.api.AlertRequest\x1a\x18.tr181.api.AlertResponseB\x1dZ\x1bgolang-test-dev/api/tr181pbb\x06proto3"
+func (x *MetricSummary) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
+func (x *MetricSummary) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *MetricSummary) GetMedian() float64 {
+	if x != nil {
+		return x.Median
+	}
+	return 0
+}
+
+func (x *MetricSummary) GetP95() float64 {
+	if x != nil {
+		return x.P95
+	}
+	return 0
+}
+
+func (x *MetricSummary) GetStddev() float64 {
+	if x != nil {
+		return x.Stddev
+	}
+	return 0
+}
+
+type MetricSummaryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Summary       *MetricSummary         `protobuf:"bytes,1,opt,name=summary,proto3" json:"summary,omitempty"` // за весь период
+	Buckets       []*MetricSummary       `protobuf:"bytes,2,rep,name=buckets,proto3" json:"buckets,omitempty"` // по интервалам (если задан bucket_seconds), пустые не возвращаются
+	BucketSeconds int64                  `protobuf:"varint,3,opt,name=bucket_seconds,json=bucketSeconds,proto3" json:"bucket_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricSummaryResponse) Reset() {
+	*x = MetricSummaryResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricSummaryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricSummaryResponse) ProtoMessage() {}
+
+func (x *MetricSummaryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricSummaryResponse.ProtoReflect.Descriptor instead.
+func (*MetricSummaryResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *MetricSummaryResponse) GetSummary() *MetricSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+func (x *MetricSummaryResponse) GetBuckets() []*MetricSummary {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *MetricSummaryResponse) GetBucketSeconds() int64 {
+	if x != nil {
+		return x.BucketSeconds
+	}
+	return 0
+}
+
+type MetricBaselineRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MetricType    string                 `protobuf:"bytes,1,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
+	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`                                        // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                                            // Unix timestamp конца периода (0 — сейчас)
+	FromExpr      string                 `protobuf:"bytes,5,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"`                 // начало периода строкой (см. MetricRequest.from_expr)
+	ToExpr        string                 `protobuf:"bytes,6,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`                       // конец периода строкой
+	Range         string                 `protobuf:"bytes,7,opt,name=range,proto3" json:"range,omitempty"`                                       // именованный период
+	Tz            string                 `protobuf:"bytes,8,opt,name=tz,proto3" json:"tz,omitempty"`                                             // часовой пояс IANA
+	BucketSeconds int64                  `protobuf:"varint,9,opt,name=bucket_seconds,json=bucketSeconds,proto3" json:"bucket_seconds,omitempty"` // длина интервала (0 — подбирается по периоду)
+	Group         string                 `protobuf:"bytes,10,opt,name=group,proto3" json:"group,omitempty"`                                      // группа устройств для сравнения (пусто — весь парк)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MetricBaselineRequest) Reset() {
+	*x = MetricBaselineRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricBaselineRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricBaselineRequest) ProtoMessage() {}
+
+func (x *MetricBaselineRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricBaselineRequest.ProtoReflect.Descriptor instead.
+func (*MetricBaselineRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *MetricBaselineRequest) GetMetricType() string {
+	if x != nil {
+		return x.MetricType
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *MetricBaselineRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *MetricBaselineRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *MetricBaselineRequest) GetBucketSeconds() int64 {
+	if x != nil {
+		return x.BucketSeconds
+	}
+	return 0
+}
+
+func (x *MetricBaselineRequest) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+type BaselineBand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Devices       int64                  `protobuf:"varint,1,opt,name=devices,proto3" json:"devices,omitempty"` // число устройств с данными в интервале
+	P5            float64                `protobuf:"fixed64,2,opt,name=p5,proto3" json:"p5,omitempty"`
+	P25           float64                `protobuf:"fixed64,3,opt,name=p25,proto3" json:"p25,omitempty"`
+	P50           float64                `protobuf:"fixed64,4,opt,name=p50,proto3" json:"p50,omitempty"` // медиана
+	P75           float64                `protobuf:"fixed64,5,opt,name=p75,proto3" json:"p75,omitempty"`
+	P95           float64                `protobuf:"fixed64,6,opt,name=p95,proto3" json:"p95,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BaselineBand) Reset() {
+	*x = BaselineBand{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BaselineBand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaselineBand) ProtoMessage() {}
+
+func (x *BaselineBand) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaselineBand.ProtoReflect.Descriptor instead.
+func (*BaselineBand) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *BaselineBand) GetDevices() int64 {
+	if x != nil {
+		return x.Devices
+	}
+	return 0
+}
+
+func (x *BaselineBand) GetP5() float64 {
+	if x != nil {
+		return x.P5
+	}
+	return 0
+}
+
+func (x *BaselineBand) GetP25() float64 {
+	if x != nil {
+		return x.P25
+	}
+	return 0
+}
+
+func (x *BaselineBand) GetP50() float64 {
+	if x != nil {
+		return x.P50
+	}
+	return 0
+}
+
+func (x *BaselineBand) GetP75() float64 {
+	if x != nil {
+		return x.P75
+	}
+	return 0
+}
+
+func (x *BaselineBand) GetP95() float64 {
+	if x != nil {
+		return x.P95
+	}
+	return 0
+}
+
+type BaselinePoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`          // начало интервала (Unix)
+	Value         *int32                 `protobuf:"varint,2,opt,name=value,proto3,oneof" json:"value,omitempty"`  // среднее значение устройства (нет — у устройства нет данных)
+	Fleet         *BaselineBand          `protobuf:"bytes,3,opt,name=fleet,proto3" json:"fleet,omitempty"`         // распределение по парку (нет — мало устройств с данными)
+	Score         *float64               `protobuf:"fixed64,4,opt,name=score,proto3,oneof" json:"score,omitempty"` // отклонение от медианы в робастных σ (есть, если заданы value и fleet)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BaselinePoint) Reset() {
+	*x = BaselinePoint{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BaselinePoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BaselinePoint) ProtoMessage() {}
+
+func (x *BaselinePoint) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BaselinePoint.ProtoReflect.Descriptor instead.
+func (*BaselinePoint) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *BaselinePoint) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *BaselinePoint) GetValue() int32 {
+	if x != nil && x.Value != nil {
+		return *x.Value
+	}
+	return 0
+}
+
+func (x *BaselinePoint) GetFleet() *BaselineBand {
+	if x != nil {
+		return x.Fleet
+	}
+	return nil
+}
+
+func (x *BaselinePoint) GetScore() float64 {
+	if x != nil && x.Score != nil {
+		return *x.Score
+	}
+	return 0
+}
+
+type MetricBaselineResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Points           []*BaselinePoint       `protobuf:"bytes,1,rep,name=points,proto3" json:"points,omitempty"`
+	BucketSeconds    int64                  `protobuf:"varint,2,opt,name=bucket_seconds,json=bucketSeconds,proto3" json:"bucket_seconds,omitempty"`
+	Group            string                 `protobuf:"bytes,3,opt,name=group,proto3" json:"group,omitempty"`
+	DeviationScore   float64                `protobuf:"fixed64,4,opt,name=deviation_score,json=deviationScore,proto3" json:"deviation_score,omitempty"`         // среднее score по сравнимым интервалам (> 0 — выше парка)
+	OutsideBandRatio float64                `protobuf:"fixed64,5,opt,name=outside_band_ratio,json=outsideBandRatio,proto3" json:"outside_band_ratio,omitempty"` // доля сравнимых интервалов вне полосы p5–p95
+	ComparedBuckets  int64                  `protobuf:"varint,6,opt,name=compared_buckets,json=comparedBuckets,proto3" json:"compared_buckets,omitempty"`       // число интервалов, где есть и значение устройства, и базовая линия
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MetricBaselineResponse) Reset() {
+	*x = MetricBaselineResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MetricBaselineResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MetricBaselineResponse) ProtoMessage() {}
+
+func (x *MetricBaselineResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MetricBaselineResponse.ProtoReflect.Descriptor instead.
+func (*MetricBaselineResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *MetricBaselineResponse) GetPoints() []*BaselinePoint {
+	if x != nil {
+		return x.Points
+	}
+	return nil
+}
+
+func (x *MetricBaselineResponse) GetBucketSeconds() int64 {
+	if x != nil {
+		return x.BucketSeconds
+	}
+	return 0
+}
+
+func (x *MetricBaselineResponse) GetGroup() string {
+	if x != nil {
+		return x.Group
+	}
+	return ""
+}
+
+func (x *MetricBaselineResponse) GetDeviationScore() float64 {
+	if x != nil {
+		return x.DeviationScore
+	}
+	return 0
+}
+
+func (x *MetricBaselineResponse) GetOutsideBandRatio() float64 {
+	if x != nil {
+		return x.OutsideBandRatio
+	}
+	return 0
+}
+
+func (x *MetricBaselineResponse) GetComparedBuckets() int64 {
+	if x != nil {
+		return x.ComparedBuckets
+	}
+	return 0
+}
+
+type AnomalyModelRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MetricType    string                 `protobuf:"bytes,1,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
+	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnomalyModelRequest) Reset() {
+	*x = AnomalyModelRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnomalyModelRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyModelRequest) ProtoMessage() {}
+
+func (x *AnomalyModelRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyModelRequest.ProtoReflect.Descriptor instead.
+func (*AnomalyModelRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *AnomalyModelRequest) GetMetricType() string {
+	if x != nil {
+		return x.MetricType
+	}
+	return ""
+}
+
+func (x *AnomalyModelRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+type AnomalyModel struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumber  string                 `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	MetricType    string                 `protobuf:"bytes,2,opt,name=metric_type,json=metricType,proto3" json:"metric_type,omitempty"`
+	Mean          float64                `protobuf:"fixed64,3,opt,name=mean,proto3" json:"mean,omitempty"`                           // EWMA среднего
+	Stddev        float64                `protobuf:"fixed64,4,opt,name=stddev,proto3" json:"stddev,omitempty"`                       // корень из EWMA дисперсии
+	Samples       int64                  `protobuf:"varint,5,opt,name=samples,proto3" json:"samples,omitempty"`                      // учтено сэмплов (алерты — после обучения, ANOMALY_WARMUP)
+	UpdatedAt     int64                  `protobuf:"varint,6,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"` // Unix время последнего учтённого сэмпла
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AnomalyModel) Reset() {
+	*x = AnomalyModel{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AnomalyModel) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AnomalyModel) ProtoMessage() {}
+
+func (x *AnomalyModel) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AnomalyModel.ProtoReflect.Descriptor instead.
+func (*AnomalyModel) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AnomalyModel) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *AnomalyModel) GetMetricType() string {
+	if x != nil {
+		return x.MetricType
+	}
+	return ""
+}
+
+func (x *AnomalyModel) GetMean() float64 {
+	if x != nil {
+		return x.Mean
+	}
+	return 0
+}
+
+func (x *AnomalyModel) GetStddev() float64 {
+	if x != nil {
+		return x.Stddev
+	}
+	return 0
+}
+
+func (x *AnomalyModel) GetSamples() int64 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *AnomalyModel) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+type AlertRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AlertType     string                 `protobuf:"bytes,1,opt,name=alert_type,json=alertType,proto3" json:"alert_type,omitempty"` // например high-cpu-usage
+	SerialNumber  string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	From          int64                  `protobuf:"varint,3,opt,name=from,proto3" json:"from,omitempty"`                        // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,4,opt,name=to,proto3" json:"to,omitempty"`                            // Unix timestamp конца периода (0 — сейчас)
+	FromExpr      string                 `protobuf:"bytes,5,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"` // начало периода строкой (см. MetricRequest.from_expr)
+	ToExpr        string                 `protobuf:"bytes,6,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`       // конец периода строкой
+	Range         string                 `protobuf:"bytes,7,opt,name=range,proto3" json:"range,omitempty"`                       // именованный период
+	Tz            string                 `protobuf:"bytes,8,opt,name=tz,proto3" json:"tz,omitempty"`                             // часовой пояс IANA
+	Severity      []string               `protobuf:"bytes,9,rep,name=severity,proto3" json:"severity,omitempty"`                 // только эти уровни важности (info, warning, error); пусто — все
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertRequest) Reset() {
+	*x = AlertRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertRequest) ProtoMessage() {}
+
+func (x *AlertRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertRequest.ProtoReflect.Descriptor instead.
+func (*AlertRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AlertRequest) GetAlertType() string {
+	if x != nil {
+		return x.AlertType
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *AlertRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *AlertRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *AlertRequest) GetSeverity() []string {
+	if x != nil {
+		return x.Severity
+	}
+	return nil
+}
+
+type AlertResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         int32                  `protobuf:"varint,1,opt,name=value,proto3" json:"value,omitempty"`                                                                                     // среднее значение за период
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`                                                                                     // количество алертов
+	Severities    map[string]int32       `protobuf:"bytes,3,rep,name=severities,proto3" json:"severities,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // количество алертов по уровням важности
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AlertResponse) Reset() {
+	*x = AlertResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AlertResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AlertResponse) ProtoMessage() {}
+
+func (x *AlertResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AlertResponse.ProtoReflect.Descriptor instead.
+func (*AlertResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *AlertResponse) GetValue() int32 {
+	if x != nil {
+		return x.Value
+	}
+	return 0
+}
+
+func (x *AlertResponse) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *AlertResponse) GetSeverities() map[string]int32 {
+	if x != nil {
+		return x.Severities
+	}
+	return nil
+}
+
+type IncidentRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumber  string                 `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	AlertType     string                 `protobuf:"bytes,2,opt,name=alert_type,json=alertType,proto3" json:"alert_type,omitempty"` // пусто — все типы
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`                        // open, closed или пусто — все
+	From          int64                  `protobuf:"varint,4,opt,name=from,proto3" json:"from,omitempty"`                           // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,5,opt,name=to,proto3" json:"to,omitempty"`                               // Unix timestamp конца периода (0 — сейчас)
+	FromExpr      string                 `protobuf:"bytes,6,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"`    // начало периода строкой (см. MetricRequest.from_expr)
+	ToExpr        string                 `protobuf:"bytes,7,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`          // конец периода строкой
+	Range         string                 `protobuf:"bytes,8,opt,name=range,proto3" json:"range,omitempty"`                          // именованный период
+	Tz            string                 `protobuf:"bytes,9,opt,name=tz,proto3" json:"tz,omitempty"`                                // часовой пояс IANA
+	Limit         int32                  `protobuf:"varint,10,opt,name=limit,proto3" json:"limit,omitempty"`                        // максимум инцидентов (0 — 100)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncidentRequest) Reset() {
+	*x = IncidentRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncidentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncidentRequest) ProtoMessage() {}
+
+func (x *IncidentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncidentRequest.ProtoReflect.Descriptor instead.
+func (*IncidentRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *IncidentRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetAlertType() string {
+	if x != nil {
+		return x.AlertType
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *IncidentRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *IncidentRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *IncidentRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Incident struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	SerialNumber    string                 `protobuf:"bytes,2,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	AlertType       string                 `protobuf:"bytes,3,opt,name=alert_type,json=alertType,proto3" json:"alert_type,omitempty"`
+	Severity        string                 `protobuf:"bytes,4,opt,name=severity,proto3" json:"severity,omitempty"`                          // уровень при пиковом значении
+	StartedAt       int64                  `protobuf:"varint,5,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`      // Unix
+	LastSeenAt      int64                  `protobuf:"varint,6,opt,name=last_seen_at,json=lastSeenAt,proto3" json:"last_seen_at,omitempty"` // последний нарушающий сэмпл
+	EndedAt         int64                  `protobuf:"varint,7,opt,name=ended_at,json=endedAt,proto3" json:"ended_at,omitempty"`            // 0 — инцидент открыт
+	PeakValue       int32                  `protobuf:"varint,8,opt,name=peak_value,json=peakValue,proto3" json:"peak_value,omitempty"`
+	LastValue       int32                  `protobuf:"varint,9,opt,name=last_value,json=lastValue,proto3" json:"last_value,omitempty"`
+	Samples         int32                  `protobuf:"varint,10,opt,name=samples,proto3" json:"samples,omitempty"`                                        // число нарушающих сэмплов
+	DurationSeconds int64                  `protobuf:"varint,11,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"` // до конца или до текущего момента для открытого
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Incident) Reset() {
+	*x = Incident{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Incident) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Incident) ProtoMessage() {}
+
+func (x *Incident) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Incident.ProtoReflect.Descriptor instead.
+func (*Incident) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Incident) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Incident) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *Incident) GetAlertType() string {
+	if x != nil {
+		return x.AlertType
+	}
+	return ""
+}
+
+func (x *Incident) GetSeverity() string {
+	if x != nil {
+		return x.Severity
+	}
+	return ""
+}
+
+func (x *Incident) GetStartedAt() int64 {
+	if x != nil {
+		return x.StartedAt
+	}
+	return 0
+}
+
+func (x *Incident) GetLastSeenAt() int64 {
+	if x != nil {
+		return x.LastSeenAt
+	}
+	return 0
+}
+
+func (x *Incident) GetEndedAt() int64 {
+	if x != nil {
+		return x.EndedAt
+	}
+	return 0
+}
+
+func (x *Incident) GetPeakValue() int32 {
+	if x != nil {
+		return x.PeakValue
+	}
+	return 0
+}
+
+func (x *Incident) GetLastValue() int32 {
+	if x != nil {
+		return x.LastValue
+	}
+	return 0
+}
+
+func (x *Incident) GetSamples() int32 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *Incident) GetDurationSeconds() int64 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+type IncidentResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Incidents     []*Incident            `protobuf:"bytes,1,rep,name=incidents,proto3" json:"incidents,omitempty"` // от новых к старым
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IncidentResponse) Reset() {
+	*x = IncidentResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IncidentResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IncidentResponse) ProtoMessage() {}
+
+func (x *IncidentResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IncidentResponse.ProtoReflect.Descriptor instead.
+func (*IncidentResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *IncidentResponse) GetIncidents() []*Incident {
+	if x != nil {
+		return x.Incidents
+	}
+	return nil
+}
+
+type RebootRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumber  string                 `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	From          int64                  `protobuf:"varint,2,opt,name=from,proto3" json:"from,omitempty"`                        // Unix timestamp начала периода (0 — за 24 часа до to)
+	To            int64                  `protobuf:"varint,3,opt,name=to,proto3" json:"to,omitempty"`                            // Unix timestamp конца периода (0 — сейчас)
+	FromExpr      string                 `protobuf:"bytes,4,opt,name=from_expr,json=fromExpr,proto3" json:"from_expr,omitempty"` // начало периода строкой (см. MetricRequest.from_expr)
+	ToExpr        string                 `protobuf:"bytes,5,opt,name=to_expr,json=toExpr,proto3" json:"to_expr,omitempty"`       // конец периода строкой
+	Range         string                 `protobuf:"bytes,6,opt,name=range,proto3" json:"range,omitempty"`                       // именованный период
+	Tz            string                 `protobuf:"bytes,7,opt,name=tz,proto3" json:"tz,omitempty"`                             // часовой пояс IANA
+	Limit         int32                  `protobuf:"varint,8,opt,name=limit,proto3" json:"limit,omitempty"`                      // максимум перезагрузок (0 — 100)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebootRequest) Reset() {
+	*x = RebootRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebootRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebootRequest) ProtoMessage() {}
+
+func (x *RebootRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebootRequest.ProtoReflect.Descriptor instead.
+func (*RebootRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RebootRequest) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *RebootRequest) GetFrom() int64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *RebootRequest) GetTo() int64 {
+	if x != nil {
+		return x.To
+	}
+	return 0
+}
+
+func (x *RebootRequest) GetFromExpr() string {
+	if x != nil {
+		return x.FromExpr
+	}
+	return ""
+}
+
+func (x *RebootRequest) GetToExpr() string {
+	if x != nil {
+		return x.ToExpr
+	}
+	return ""
+}
+
+func (x *RebootRequest) GetRange() string {
+	if x != nil {
+		return x.Range
+	}
+	return ""
+}
+
+func (x *RebootRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *RebootRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Reboot struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	Time                int64                  `protobuf:"varint,1,opt,name=time,proto3" json:"time,omitempty"`                                                            // Unix время сэмпла, в котором обнаружена перезагрузка
+	UptimeBeforeSeconds int64                  `protobuf:"varint,2,opt,name=uptime_before_seconds,json=uptimeBeforeSeconds,proto3" json:"uptime_before_seconds,omitempty"` // сколько устройство проработало до неё
+	Loop                bool                   `protobuf:"varint,3,opt,name=loop,proto3" json:"loop,omitempty"`                                                            // перезагрузка дала алерт reboot-loop
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Reboot) Reset() {
+	*x = Reboot{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Reboot) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Reboot) ProtoMessage() {}
+
+func (x *Reboot) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Reboot.ProtoReflect.Descriptor instead.
+func (*Reboot) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Reboot) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Reboot) GetUptimeBeforeSeconds() int64 {
+	if x != nil {
+		return x.UptimeBeforeSeconds
+	}
+	return 0
+}
+
+func (x *Reboot) GetLoop() bool {
+	if x != nil {
+		return x.Loop
+	}
+	return false
+}
+
+type RebootResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reboots       []*Reboot              `protobuf:"bytes,1,rep,name=reboots,proto3" json:"reboots,omitempty"` // от новых к старым
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RebootResponse) Reset() {
+	*x = RebootResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RebootResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RebootResponse) ProtoMessage() {}
+
+func (x *RebootResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RebootResponse.ProtoReflect.Descriptor instead.
+func (*RebootResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *RebootResponse) GetReboots() []*Reboot {
+	if x != nil {
+		return x.Reboots
+	}
+	return nil
+}
+
+type DeviceStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumbers []string               `protobuf:"bytes,1,rep,name=serial_numbers,json=serialNumbers,proto3" json:"serial_numbers,omitempty"` // один или несколько серийных номеров
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceStateRequest) Reset() {
+	*x = DeviceStateRequest{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceStateRequest) ProtoMessage() {}
+
+func (x *DeviceStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceStateRequest.ProtoReflect.Descriptor instead.
+func (*DeviceStateRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *DeviceStateRequest) GetSerialNumbers() []string {
+	if x != nil {
+		return x.SerialNumbers
+	}
+	return nil
+}
+
+type DeviceState struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	SerialNumber     string                 `protobuf:"bytes,1,opt,name=serial_number,json=serialNumber,proto3" json:"serial_number,omitempty"`
+	Time             int64                  `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`                                                                                       // Unix timestamp последнего снимка
+	StalenessSeconds int64                  `protobuf:"varint,3,opt,name=staleness_seconds,json=stalenessSeconds,proto3" json:"staleness_seconds,omitempty"`                                       // сколько секунд прошло с последнего снимка
+	Stale            bool                   `protobuf:"varint,4,opt,name=stale,proto3" json:"stale,omitempty"`                                                                                     // true — устройство давно не присылало данные
+	Parameters       map[string]int64       `protobuf:"bytes,5,rep,name=parameters,proto3" json:"parameters,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // параметр TR181 → значение
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *DeviceState) Reset() {
+	*x = DeviceState{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceState) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceState) ProtoMessage() {}
+
+func (x *DeviceState) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceState.ProtoReflect.Descriptor instead.
+func (*DeviceState) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *DeviceState) GetSerialNumber() string {
+	if x != nil {
+		return x.SerialNumber
+	}
+	return ""
+}
+
+func (x *DeviceState) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *DeviceState) GetStalenessSeconds() int64 {
+	if x != nil {
+		return x.StalenessSeconds
+	}
+	return 0
+}
+
+func (x *DeviceState) GetStale() bool {
+	if x != nil {
+		return x.Stale
+	}
+	return false
+}
+
+func (x *DeviceState) GetParameters() map[string]int64 {
+	if x != nil {
+		return x.Parameters
+	}
+	return nil
+}
+
+type DeviceStateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	States        []*DeviceState         `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
+	Missing       []string               `protobuf:"bytes,2,rep,name=missing,proto3" json:"missing,omitempty"` // устройства без известного состояния
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeviceStateResponse) Reset() {
+	*x = DeviceStateResponse{}
+	mi := &file_api_proto_tr181_api_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeviceStateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeviceStateResponse) ProtoMessage() {}
+
+func (x *DeviceStateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_tr181_api_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeviceStateResponse.ProtoReflect.Descriptor instead.
+func (*DeviceStateResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_tr181_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *DeviceStateResponse) GetStates() []*DeviceState {
+	if x != nil {
+		return x.States
+	}
+	return nil
+}
+
+func (x *DeviceStateResponse) GetMissing() []string {
+	if x != nil {
+		return x.Missing
+	}
+	return nil
+}
+
+var File_api_proto_tr181_api_proto protoreflect.FileDescriptor
+
+const file_api_proto_tr181_api_proto_rawDesc = "" +
+	"\n" +
+	"\x19api/proto/tr181_api.proto\x12\ttr181.api\x1a\x1cgoogle/api/annotations.proto\"\xaa\x02\n" +
+	"\rMetricRequest\x12\x1f\n" +
+	"\vmetric_type\x18\x01 \x01(\tR\n" +
+	"metricType\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\x12\x1e\n" +
+	"\n" +
+	"downsample\x18\a \x01(\bR\n" +
+	"downsample\x12\x1b\n" +
+	"\tfrom_expr\x18\b \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\t \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\n" +
+	" \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\v \x01(\tR\x02tz\"7\n" +
+	"\vMetricValue\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x05R\x05value\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\"\x91\x01\n" +
+	"\x0eMetricResponse\x120\n" +
+	"\ametrics\x18\x01 \x03(\v2\x16.tr181.api.MetricValueR\ametrics\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\x12%\n" +
+	"\x0ebucket_seconds\x18\x03 \x01(\x03R\rbucketSeconds\"\x83\x02\n" +
+	"\x14MetricSummaryRequest\x12\x1f\n" +
+	"\vmetric_type\x18\x01 \x01(\tR\n" +
+	"metricType\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\x12\x1b\n" +
+	"\tfrom_expr\x18\x05 \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\x06 \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\a \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\b \x01(\tR\x02tz\x12%\n" +
+	"\x0ebucket_seconds\x18\t \x01(\x03R\rbucketSeconds\"\xb3\x01\n" +
+	"\rMetricSummary\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\x12\x10\n" +
+	"\x03min\x18\x03 \x01(\x05R\x03min\x12\x10\n" +
+	"\x03max\x18\x04 \x01(\x05R\x03max\x12\x12\n" +
+	"\x04mean\x18\x05 \x01(\x01R\x04mean\x12\x16\n" +
+	"\x06median\x18\x06 \x01(\x01R\x06median\x12\x10\n" +
+	"\x03p95\x18\a \x01(\x01R\x03p95\x12\x16\n" +
+	"\x06stddev\x18\b \x01(\x01R\x06stddev\"\xa6\x01\n" +
+	"\x15MetricSummaryResponse\x122\n" +
+	"\asummary\x18\x01 \x01(\v2\x18.tr181.api.MetricSummaryR\asummary\x122\n" +
+	"\abuckets\x18\x02 \x03(\v2\x18.tr181.api.MetricSummaryR\abuckets\x12%\n" +
+	"\x0ebucket_seconds\x18\x03 \x01(\x03R\rbucketSeconds\"\x9a\x02\n" +
+	"\x15MetricBaselineRequest\x12\x1f\n" +
+	"\vmetric_type\x18\x01 \x01(\tR\n" +
+	"metricType\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\x12\x1b\n" +
+	"\tfrom_expr\x18\x05 \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\x06 \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\a \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\b \x01(\tR\x02tz\x12%\n" +
+	"\x0ebucket_seconds\x18\t \x01(\x03R\rbucketSeconds\x12\x14\n" +
+	"\x05group\x18\n" +
+	" \x01(\tR\x05group\"\x80\x01\n" +
+	"\fBaselineBand\x12\x18\n" +
+	"\adevices\x18\x01 \x01(\x03R\adevices\x12\x0e\n" +
+	"\x02p5\x18\x02 \x01(\x01R\x02p5\x12\x10\n" +
+	"\x03p25\x18\x03 \x01(\x01R\x03p25\x12\x10\n" +
+	"\x03p50\x18\x04 \x01(\x01R\x03p50\x12\x10\n" +
+	"\x03p75\x18\x05 \x01(\x01R\x03p75\x12\x10\n" +
+	"\x03p95\x18\x06 \x01(\x01R\x03p95\"\x9c\x01\n" +
+	"\rBaselinePoint\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x12\x19\n" +
+	"\x05value\x18\x02 \x01(\x05H\x00R\x05value\x88\x01\x01\x12-\n" +
+	"\x05fleet\x18\x03 \x01(\v2\x17.tr181.api.BaselineBandR\x05fleet\x12\x19\n" +
+	"\x05score\x18\x04 \x01(\x01H\x01R\x05score\x88\x01\x01B\b\n" +
+	"\x06_valueB\b\n" +
+	"\x06_score\"\x89\x02\n" +
+	"\x16MetricBaselineResponse\x120\n" +
+	"\x06points\x18\x01 \x03(\v2\x18.tr181.api.BaselinePointR\x06points\x12%\n" +
+	"\x0ebucket_seconds\x18\x02 \x01(\x03R\rbucketSeconds\x12\x14\n" +
+	"\x05group\x18\x03 \x01(\tR\x05group\x12'\n" +
+	"\x0fdeviation_score\x18\x04 \x01(\x01R\x0edeviationScore\x12,\n" +
+	"\x12outside_band_ratio\x18\x05 \x01(\x01R\x10outsideBandRatio\x12)\n" +
+	"\x10compared_buckets\x18\x06 \x01(\x03R\x0fcomparedBuckets\"[\n" +
+	"\x13AnomalyModelRequest\x12\x1f\n" +
+	"\vmetric_type\x18\x01 \x01(\tR\n" +
+	"metricType\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\"\xb9\x01\n" +
+	"\fAnomalyModel\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x1f\n" +
+	"\vmetric_type\x18\x02 \x01(\tR\n" +
+	"metricType\x12\x12\n" +
+	"\x04mean\x18\x03 \x01(\x01R\x04mean\x12\x16\n" +
+	"\x06stddev\x18\x04 \x01(\x01R\x06stddev\x12\x18\n" +
+	"\asamples\x18\x05 \x01(\x03R\asamples\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x06 \x01(\x03R\tupdatedAt\"\xee\x01\n" +
+	"\fAlertRequest\x12\x1d\n" +
+	"\n" +
+	"alert_type\x18\x01 \x01(\tR\talertType\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04from\x18\x03 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x04 \x01(\x03R\x02to\x12\x1b\n" +
+	"\tfrom_expr\x18\x05 \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\x06 \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\a \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\b \x01(\tR\x02tz\x12\x1a\n" +
+	"\bseverity\x18\t \x03(\tR\bseverity\"\xc4\x01\n" +
+	"\rAlertResponse\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\x05R\x05value\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12H\n" +
+	"\n" +
+	"severities\x18\x03 \x03(\v2(.tr181.api.AlertResponse.SeveritiesEntryR\n" +
+	"severities\x1a=\n" +
+	"\x0fSeveritiesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\x83\x02\n" +
+	"\x0fIncidentRequest\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x1d\n" +
+	"\n" +
+	"alert_type\x18\x02 \x01(\tR\talertType\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x12\n" +
+	"\x04from\x18\x04 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x05 \x01(\x03R\x02to\x12\x1b\n" +
+	"\tfrom_expr\x18\x06 \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\a \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\b \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\t \x01(\tR\x02tz\x12\x14\n" +
+	"\x05limit\x18\n" +
+	" \x01(\x05R\x05limit\"\xd9\x02\n" +
+	"\bIncident\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12#\n" +
+	"\rserial_number\x18\x02 \x01(\tR\fserialNumber\x12\x1d\n" +
+	"\n" +
+	"alert_type\x18\x03 \x01(\tR\talertType\x12\x1a\n" +
+	"\bseverity\x18\x04 \x01(\tR\bseverity\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\x05 \x01(\x03R\tstartedAt\x12 \n" +
+	"\flast_seen_at\x18\x06 \x01(\x03R\n" +
+	"lastSeenAt\x12\x19\n" +
+	"\bended_at\x18\a \x01(\x03R\aendedAt\x12\x1d\n" +
+	"\n" +
+	"peak_value\x18\b \x01(\x05R\tpeakValue\x12\x1d\n" +
+	"\n" +
+	"last_value\x18\t \x01(\x05R\tlastValue\x12\x18\n" +
+	"\asamples\x18\n" +
+	" \x01(\x05R\asamples\x12)\n" +
+	"\x10duration_seconds\x18\v \x01(\x03R\x0fdurationSeconds\"E\n" +
+	"\x10IncidentResponse\x121\n" +
+	"\tincidents\x18\x01 \x03(\v2\x13.tr181.api.IncidentR\tincidents\"\xca\x01\n" +
+	"\rRebootRequest\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\x03R\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\x03R\x02to\x12\x1b\n" +
+	"\tfrom_expr\x18\x04 \x01(\tR\bfromExpr\x12\x17\n" +
+	"\ato_expr\x18\x05 \x01(\tR\x06toExpr\x12\x14\n" +
+	"\x05range\x18\x06 \x01(\tR\x05range\x12\x0e\n" +
+	"\x02tz\x18\a \x01(\tR\x02tz\x12\x14\n" +
+	"\x05limit\x18\b \x01(\x05R\x05limit\"d\n" +
+	"\x06Reboot\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x03R\x04time\x122\n" +
+	"\x15uptime_before_seconds\x18\x02 \x01(\x03R\x13uptimeBeforeSeconds\x12\x12\n" +
+	"\x04loop\x18\x03 \x01(\bR\x04loop\"=\n" +
+	"\x0eRebootResponse\x12+\n" +
+	"\areboots\x18\x01 \x03(\v2\x11.tr181.api.RebootR\areboots\";\n" +
+	"\x12DeviceStateRequest\x12%\n" +
+	"\x0eserial_numbers\x18\x01 \x03(\tR\rserialNumbers\"\x90\x02\n" +
+	"\vDeviceState\x12#\n" +
+	"\rserial_number\x18\x01 \x01(\tR\fserialNumber\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x03R\x04time\x12+\n" +
+	"\x11staleness_seconds\x18\x03 \x01(\x03R\x10stalenessSeconds\x12\x14\n" +
+	"\x05stale\x18\x04 \x01(\bR\x05stale\x12F\n" +
+	"\n" +
+	"parameters\x18\x05 \x03(\v2&.tr181.api.DeviceState.ParametersEntryR\n" +
+	"parameters\x1a=\n" +
+	"\x0fParametersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x03R\x05value:\x028\x01\"_\n" +
+	"\x13DeviceStateResponse\x12.\n" +
+	"\x06states\x18\x01 \x03(\v2\x16.tr181.api.DeviceStateR\x06states\x12\x18\n" +
+	"\amissing\x18\x02 \x03(\tR\amissing*\xcc\x03\n" +
+	"\tErrorCode\x12\x1a\n" +
+	"\x16ERROR_CODE_UNSPECIFIED\x10\x00\x12 \n" +
+	"\x1cERROR_CODE_MISSING_PARAMETER\x10\x01\x12 \n" +
+	"\x1cERROR_CODE_INVALID_PARAMETER\x10\x02\x12\"\n" +
+	"\x1eERROR_CODE_INVALID_METRIC_TYPE\x10\x03\x12!\n" +
+	"\x1dERROR_CODE_INVALID_ALERT_TYPE\x10\x04\x12!\n" +
+	"\x1dERROR_CODE_INVALID_TIME_RANGE\x10\x05\x12\x1d\n" +
+	"\x19ERROR_CODE_LIMIT_EXCEEDED\x10\x06\x12\x1e\n" +
+	"\x1aERROR_CODE_UNAUTHENTICATED\x10\a\x12 \n" +
+	"\x1cERROR_CODE_PERMISSION_DENIED\x10\b\x12\x18\n" +
+	"\x14ERROR_CODE_NOT_FOUND\x10\t\x12\x1b\n" +
+	"\x17ERROR_CODE_RATE_LIMITED\x10\n" +
+	"\x12\x17\n" +
+	"\x13ERROR_CODE_INTERNAL\x10\v\x12\"\n" +
+	"\x1eERROR_CODE_STORAGE_UNAVAILABLE\x10\f\x12 \n" +
+	"\x1cERROR_CODE_DEADLINE_EXCEEDED\x10\r2\xbb\b\n" +
+	"\bTR181Api\x12\x7f\n" +
+	"\tGetMetric\x12\x18.tr181.api.MetricRequest\x1a\x19.tr181.api.MetricResponse\"=\x82\xd3\xe4\x93\x027\x125/api/v2/devices/{serial_number}/metrics/{metric_type}\x12\x9c\x01\n" +
+	"\x10GetMetricSummary\x12\x1f.tr181.api.MetricSummaryRequest\x1a .tr181.api.MetricSummaryResponse\"E\x82\xd3\xe4\x93\x02?\x12=/api/v2/devices/{serial_number}/metrics/{metric_type}/summary\x12\xa0\x01\n" +
+	"\x11GetMetricBaseline\x12 .tr181.api.MetricBaselineRequest\x1a!.tr181.api.MetricBaselineResponse\"F\x82\xd3\xe4\x93\x02@\x12>/api/v2/devices/{serial_number}/metrics/{metric_type}/baseline\x12\x97\x01\n" +
+	"\x0fGetAnomalyModel\x12\x1e.tr181.api.AnomalyModelRequest\x1a\x17.tr181.api.AnomalyModel\"K\x82\xd3\xe4\x93\x02E\x12C/api/v2/devices/{serial_number}/metrics/{metric_type}/anomaly-model\x12z\n" +
+	"\bGetAlert\x12\x17.tr181.api.AlertRequest\x1a\x18.tr181.api.AlertResponse\";\x82\xd3\xe4\x93\x025\x123/api/v2/devices/{serial_number}/alerts/{alert_type}\x12z\n" +
+	"\fGetIncidents\x12\x1a.tr181.api.IncidentRequest\x1a\x1b.tr181.api.IncidentResponse\"1\x82\xd3\xe4\x93\x02+\x12)/api/v2/devices/{serial_number}/incidents\x12r\n" +
+	"\n" +
+	"GetReboots\x12\x18.tr181.api.RebootRequest\x1a\x19.tr181.api.RebootResponse\"/\x82\xd3\xe4\x93\x02)\x12'/api/v2/devices/{serial_number}/reboots\x12f\n" +
+	"\x0eGetDeviceState\x12\x1d.tr181.api.DeviceStateRequest\x1a\x1e.tr181.api.DeviceStateResponse\"\x15\x82\xd3\xe4\x93\x02\x0f\x12\r/api/v2/stateB\x1dZ\x1bgolang-test-dev/api/tr181pbb\x06proto3"
 
 var (
 	file_api_proto_tr181_api_proto_rawDescOnce sync.Once
@@ -346,25 +2162,68 @@ func file_api_proto_tr181_api_proto_rawDescGZIP() []byte {
 	return file_api_proto_tr181_api_proto_rawDescData
 }
 
-var file_api_proto_tr181_api_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_api_proto_tr181_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_api_proto_tr181_api_proto_msgTypes = make([]protoimpl.MessageInfo, 25)
 var file_api_proto_tr181_api_proto_goTypes = []any{
-	(*MetricRequest)(nil),  // 0: tr181.api.MetricRequest
-	(*MetricValue)(nil),    // 1: tr181.api.MetricValue
-	(*MetricResponse)(nil), // 2: tr181.api.MetricResponse
-	(*AlertRequest)(nil),   // 3: tr181.api.AlertRequest
-	(*AlertResponse)(nil),  // 4: tr181.api.AlertResponse
+	(ErrorCode)(0),                 // 0: tr181.api.ErrorCode
+	(*MetricRequest)(nil),          // 1: tr181.api.MetricRequest
+	(*MetricValue)(nil),            // 2: tr181.api.MetricValue
+	(*MetricResponse)(nil),         // 3: tr181.api.MetricResponse
+	(*MetricSummaryRequest)(nil),   // 4: tr181.api.MetricSummaryRequest
+	(*MetricSummary)(nil),          // 5: tr181.api.MetricSummary
+	(*MetricSummaryResponse)(nil),  // 6: tr181.api.MetricSummaryResponse
+	(*MetricBaselineRequest)(nil),  // 7: tr181.api.MetricBaselineRequest
+	(*BaselineBand)(nil),           // 8: tr181.api.BaselineBand
+	(*BaselinePoint)(nil),          // 9: tr181.api.BaselinePoint
+	(*MetricBaselineResponse)(nil), // 10: tr181.api.MetricBaselineResponse
+	(*AnomalyModelRequest)(nil),    // 11: tr181.api.AnomalyModelRequest
+	(*AnomalyModel)(nil),           // 12: tr181.api.AnomalyModel
+	(*AlertRequest)(nil),           // 13: tr181.api.AlertRequest
+	(*AlertResponse)(nil),          // 14: tr181.api.AlertResponse
+	(*IncidentRequest)(nil),        // 15: tr181.api.IncidentRequest
+	(*Incident)(nil),               // 16: tr181.api.Incident
+	(*IncidentResponse)(nil),       // 17: tr181.api.IncidentResponse
+	(*RebootRequest)(nil),          // 18: tr181.api.RebootRequest
+	(*Reboot)(nil),                 // 19: tr181.api.Reboot
+	(*RebootResponse)(nil),         // 20: tr181.api.RebootResponse
+	(*DeviceStateRequest)(nil),     // 21: tr181.api.DeviceStateRequest
+	(*DeviceState)(nil),            // 22: tr181.api.DeviceState
+	(*DeviceStateResponse)(nil),    // 23: tr181.api.DeviceStateResponse
+	nil,                            // 24: tr181.api.AlertResponse.SeveritiesEntry
+	nil,                            // 25: tr181.api.DeviceState.ParametersEntry
 }
 var file_api_proto_tr181_api_proto_depIdxs = []int32{
-	1, // 0: tr181.api.MetricResponse.metrics:type_name -> tr181.api.MetricValue
-	0, // 1: tr181.api.TR181Api.GetMetric:input_type -> tr181.api.MetricRequest
-	3, // 2: tr181.api.TR181Api.GetAlert:input_type -> tr181.api.AlertRequest
-	2, // 3: tr181.api.TR181Api.GetMetric:output_type -> tr181.api.MetricResponse
-	4, // 4: tr181.api.TR181Api.GetAlert:output_type -> tr181.api.AlertResponse
-	3, // [3:5] is the sub-list for method output_type
-	1, // [1:3] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2,  // 0: tr181.api.MetricResponse.metrics:type_name -> tr181.api.MetricValue
+	5,  // 1: tr181.api.MetricSummaryResponse.summary:type_name -> tr181.api.MetricSummary
+	5,  // 2: tr181.api.MetricSummaryResponse.buckets:type_name -> tr181.api.MetricSummary
+	8,  // 3: tr181.api.BaselinePoint.fleet:type_name -> tr181.api.BaselineBand
+	9,  // 4: tr181.api.MetricBaselineResponse.points:type_name -> tr181.api.BaselinePoint
+	24, // 5: tr181.api.AlertResponse.severities:type_name -> tr181.api.AlertResponse.SeveritiesEntry
+	16, // 6: tr181.api.IncidentResponse.incidents:type_name -> tr181.api.Incident
+	19, // 7: tr181.api.RebootResponse.reboots:type_name -> tr181.api.Reboot
+	25, // 8: tr181.api.DeviceState.parameters:type_name -> tr181.api.DeviceState.ParametersEntry
+	22, // 9: tr181.api.DeviceStateResponse.states:type_name -> tr181.api.DeviceState
+	1,  // 10: tr181.api.TR181Api.GetMetric:input_type -> tr181.api.MetricRequest
+	4,  // 11: tr181.api.TR181Api.GetMetricSummary:input_type -> tr181.api.MetricSummaryRequest
+	7,  // 12: tr181.api.TR181Api.GetMetricBaseline:input_type -> tr181.api.MetricBaselineRequest
+	11, // 13: tr181.api.TR181Api.GetAnomalyModel:input_type -> tr181.api.AnomalyModelRequest
+	13, // 14: tr181.api.TR181Api.GetAlert:input_type -> tr181.api.AlertRequest
+	15, // 15: tr181.api.TR181Api.GetIncidents:input_type -> tr181.api.IncidentRequest
+	18, // 16: tr181.api.TR181Api.GetReboots:input_type -> tr181.api.RebootRequest
+	21, // 17: tr181.api.TR181Api.GetDeviceState:input_type -> tr181.api.DeviceStateRequest
+	3,  // 18: tr181.api.TR181Api.GetMetric:output_type -> tr181.api.MetricResponse
+	6,  // 19: tr181.api.TR181Api.GetMetricSummary:output_type -> tr181.api.MetricSummaryResponse
+	10, // 20: tr181.api.TR181Api.GetMetricBaseline:output_type -> tr181.api.MetricBaselineResponse
+	12, // 21: tr181.api.TR181Api.GetAnomalyModel:output_type -> tr181.api.AnomalyModel
+	14, // 22: tr181.api.TR181Api.GetAlert:output_type -> tr181.api.AlertResponse
+	17, // 23: tr181.api.TR181Api.GetIncidents:output_type -> tr181.api.IncidentResponse
+	20, // 24: tr181.api.TR181Api.GetReboots:output_type -> tr181.api.RebootResponse
+	23, // 25: tr181.api.TR181Api.GetDeviceState:output_type -> tr181.api.DeviceStateResponse
+	18, // [18:26] is the sub-list for method output_type
+	10, // [10:18] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_tr181_api_proto_init() }
@@ -372,18 +2231,20 @@ func file_api_proto_tr181_api_proto_init() {
 	if File_api_proto_tr181_api_proto != nil {
 		return
 	}
+	file_api_proto_tr181_api_proto_msgTypes[8].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_tr181_api_proto_rawDesc), len(file_api_proto_tr181_api_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   5,
+			NumEnums:      1,
+			NumMessages:   25,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_api_proto_tr181_api_proto_goTypes,
 		DependencyIndexes: file_api_proto_tr181_api_proto_depIdxs,
+		EnumInfos:         file_api_proto_tr181_api_proto_enumTypes,
 		MessageInfos:      file_api_proto_tr181_api_proto_msgTypes,
 	}.Build()
 	File_api_proto_tr181_api_proto = out.File
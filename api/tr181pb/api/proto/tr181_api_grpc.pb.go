@@ -19,20 +19,40 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	TR181Api_GetMetric_FullMethodName = "/tr181.api.TR181Api/GetMetric"
-	TR181Api_GetAlert_FullMethodName  = "/tr181.api.TR181Api/GetAlert"
+	TR181Api_GetMetric_FullMethodName         = "/tr181.api.TR181Api/GetMetric"
+	TR181Api_GetMetricSummary_FullMethodName  = "/tr181.api.TR181Api/GetMetricSummary"
+	TR181Api_GetMetricBaseline_FullMethodName = "/tr181.api.TR181Api/GetMetricBaseline"
+	TR181Api_GetAnomalyModel_FullMethodName   = "/tr181.api.TR181Api/GetAnomalyModel"
+	TR181Api_GetAlert_FullMethodName          = "/tr181.api.TR181Api/GetAlert"
+	TR181Api_GetIncidents_FullMethodName      = "/tr181.api.TR181Api/GetIncidents"
+	TR181Api_GetReboots_FullMethodName        = "/tr181.api.TR181Api/GetReboots"
+	TR181Api_GetDeviceState_FullMethodName    = "/tr181.api.TR181Api/GetDeviceState"
 )
 
 // TR181ApiClient is the client API for TR181Api service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 //
-// TR181 API Service - gRPC endpoints для метрик и алертов
+// TR181 API Service - gRPC endpoints для метрик и алертов.
+// Каждый метод с аннотацией google.api.http доступен и как REST (/api/v2, gRPC-Gateway),
+// описание REST API генерируется в api/openapi/tr181_api.swagger.json
 type TR181ApiClient interface {
 	// GetMetric - получение метрик за период
 	GetMetric(ctx context.Context, in *MetricRequest, opts ...grpc.CallOption) (*MetricResponse, error)
+	// GetMetricSummary - сводная статистика метрики за период (min, max, mean, median, p95, stddev, count)
+	GetMetricSummary(ctx context.Context, in *MetricSummaryRequest, opts ...grpc.CallOption) (*MetricSummaryResponse, error)
+	// GetMetricBaseline - сравнение метрики устройства с парком (или группой устройств): перцентили по интервалам и отклонение
+	GetMetricBaseline(ctx context.Context, in *MetricBaselineRequest, opts ...grpc.CallOption) (*MetricBaselineResponse, error)
+	// GetAnomalyModel - текущая модель нормального поведения метрики устройства (адаптер аномалий alert-processor)
+	GetAnomalyModel(ctx context.Context, in *AnomalyModelRequest, opts ...grpc.CallOption) (*AnomalyModel, error)
 	// GetAlert - получение статистики алертов за период
 	GetAlert(ctx context.Context, in *AlertRequest, opts ...grpc.CallOption) (*AlertResponse, error)
+	// GetIncidents - инциденты алертов устройства (период нарушения с началом, концом и пиковым значением)
+	GetIncidents(ctx context.Context, in *IncidentRequest, opts ...grpc.CallOption) (*IncidentResponse, error)
+	// GetReboots - история перезагрузок устройства (по уменьшению uptime)
+	GetReboots(ctx context.Context, in *RebootRequest, opts ...grpc.CallOption) (*RebootResponse, error)
+	// GetDeviceState - последнее известное состояние устройств (без запроса к hypertable)
+	GetDeviceState(ctx context.Context, in *DeviceStateRequest, opts ...grpc.CallOption) (*DeviceStateResponse, error)
 }
 
 type tR181ApiClient struct {
@@ -53,6 +73,36 @@ func (c *tR181ApiClient) GetMetric(ctx context.Context, in *MetricRequest, opts
 	return out, nil
 }
 
+func (c *tR181ApiClient) GetMetricSummary(ctx context.Context, in *MetricSummaryRequest, opts ...grpc.CallOption) (*MetricSummaryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MetricSummaryResponse)
+	err := c.cc.Invoke(ctx, TR181Api_GetMetricSummary_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tR181ApiClient) GetMetricBaseline(ctx context.Context, in *MetricBaselineRequest, opts ...grpc.CallOption) (*MetricBaselineResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MetricBaselineResponse)
+	err := c.cc.Invoke(ctx, TR181Api_GetMetricBaseline_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tR181ApiClient) GetAnomalyModel(ctx context.Context, in *AnomalyModelRequest, opts ...grpc.CallOption) (*AnomalyModel, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AnomalyModel)
+	err := c.cc.Invoke(ctx, TR181Api_GetAnomalyModel_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *tR181ApiClient) GetAlert(ctx context.Context, in *AlertRequest, opts ...grpc.CallOption) (*AlertResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AlertResponse)
@@ -63,16 +113,60 @@ func (c *tR181ApiClient) GetAlert(ctx context.Context, in *AlertRequest, opts ..
 	return out, nil
 }
 
+func (c *tR181ApiClient) GetIncidents(ctx context.Context, in *IncidentRequest, opts ...grpc.CallOption) (*IncidentResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IncidentResponse)
+	err := c.cc.Invoke(ctx, TR181Api_GetIncidents_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tR181ApiClient) GetReboots(ctx context.Context, in *RebootRequest, opts ...grpc.CallOption) (*RebootResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RebootResponse)
+	err := c.cc.Invoke(ctx, TR181Api_GetReboots_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *tR181ApiClient) GetDeviceState(ctx context.Context, in *DeviceStateRequest, opts ...grpc.CallOption) (*DeviceStateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeviceStateResponse)
+	err := c.cc.Invoke(ctx, TR181Api_GetDeviceState_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // TR181ApiServer is the server API for TR181Api service.
 // All implementations must embed UnimplementedTR181ApiServer
 // for forward compatibility.
 //
-// TR181 API Service - gRPC endpoints для метрик и алертов
+// TR181 API Service - gRPC endpoints для метрик и алертов.
+// Каждый метод с аннотацией google.api.http доступен и как REST (/api/v2, gRPC-Gateway),
+// описание REST API генерируется в api/openapi/tr181_api.swagger.json
 type TR181ApiServer interface {
 	// GetMetric - получение метрик за период
 	GetMetric(context.Context, *MetricRequest) (*MetricResponse, error)
+	// GetMetricSummary - сводная статистика метрики за период (min, max, mean, median, p95, stddev, count)
+	GetMetricSummary(context.Context, *MetricSummaryRequest) (*MetricSummaryResponse, error)
+	// GetMetricBaseline - сравнение метрики устройства с парком (или группой устройств): перцентили по интервалам и отклонение
+	GetMetricBaseline(context.Context, *MetricBaselineRequest) (*MetricBaselineResponse, error)
+	// GetAnomalyModel - текущая модель нормального поведения метрики устройства (адаптер аномалий alert-processor)
+	GetAnomalyModel(context.Context, *AnomalyModelRequest) (*AnomalyModel, error)
 	// GetAlert - получение статистики алертов за период
 	GetAlert(context.Context, *AlertRequest) (*AlertResponse, error)
+	// GetIncidents - инциденты алертов устройства (период нарушения с началом, концом и пиковым значением)
+	GetIncidents(context.Context, *IncidentRequest) (*IncidentResponse, error)
+	// GetReboots - история перезагрузок устройства (по уменьшению uptime)
+	GetReboots(context.Context, *RebootRequest) (*RebootResponse, error)
+	// GetDeviceState - последнее известное состояние устройств (без запроса к hypertable)
+	GetDeviceState(context.Context, *DeviceStateRequest) (*DeviceStateResponse, error)
 	mustEmbedUnimplementedTR181ApiServer()
 }
 
@@ -86,9 +180,27 @@ type UnimplementedTR181ApiServer struct{}
 func (UnimplementedTR181ApiServer) GetMetric(context.Context, *MetricRequest) (*MetricResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetMetric not implemented")
 }
+func (UnimplementedTR181ApiServer) GetMetricSummary(context.Context, *MetricSummaryRequest) (*MetricSummaryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMetricSummary not implemented")
+}
+func (UnimplementedTR181ApiServer) GetMetricBaseline(context.Context, *MetricBaselineRequest) (*MetricBaselineResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetMetricBaseline not implemented")
+}
+func (UnimplementedTR181ApiServer) GetAnomalyModel(context.Context, *AnomalyModelRequest) (*AnomalyModel, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetAnomalyModel not implemented")
+}
 func (UnimplementedTR181ApiServer) GetAlert(context.Context, *AlertRequest) (*AlertResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method GetAlert not implemented")
 }
+func (UnimplementedTR181ApiServer) GetIncidents(context.Context, *IncidentRequest) (*IncidentResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetIncidents not implemented")
+}
+func (UnimplementedTR181ApiServer) GetReboots(context.Context, *RebootRequest) (*RebootResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetReboots not implemented")
+}
+func (UnimplementedTR181ApiServer) GetDeviceState(context.Context, *DeviceStateRequest) (*DeviceStateResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetDeviceState not implemented")
+}
 func (UnimplementedTR181ApiServer) mustEmbedUnimplementedTR181ApiServer() {}
 func (UnimplementedTR181ApiServer) testEmbeddedByValue()                  {}
 
@@ -128,6 +240,60 @@ func _TR181Api_GetMetric_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TR181Api_GetMetricSummary_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricSummaryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetMetricSummary(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetMetricSummary_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetMetricSummary(ctx, req.(*MetricSummaryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TR181Api_GetMetricBaseline_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MetricBaselineRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetMetricBaseline(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetMetricBaseline_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetMetricBaseline(ctx, req.(*MetricBaselineRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TR181Api_GetAnomalyModel_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AnomalyModelRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetAnomalyModel(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetAnomalyModel_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetAnomalyModel(ctx, req.(*AnomalyModelRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _TR181Api_GetAlert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AlertRequest)
 	if err := dec(in); err != nil {
@@ -146,6 +312,60 @@ func _TR181Api_GetAlert_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _TR181Api_GetIncidents_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IncidentRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetIncidents(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetIncidents_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetIncidents(ctx, req.(*IncidentRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TR181Api_GetReboots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RebootRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetReboots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetReboots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetReboots(ctx, req.(*RebootRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TR181Api_GetDeviceState_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeviceStateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TR181ApiServer).GetDeviceState(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TR181Api_GetDeviceState_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TR181ApiServer).GetDeviceState(ctx, req.(*DeviceStateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // TR181Api_ServiceDesc is the grpc.ServiceDesc for TR181Api service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -157,10 +377,34 @@ var TR181Api_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMetric",
 			Handler:    _TR181Api_GetMetric_Handler,
 		},
+		{
+			MethodName: "GetMetricSummary",
+			Handler:    _TR181Api_GetMetricSummary_Handler,
+		},
+		{
+			MethodName: "GetMetricBaseline",
+			Handler:    _TR181Api_GetMetricBaseline_Handler,
+		},
+		{
+			MethodName: "GetAnomalyModel",
+			Handler:    _TR181Api_GetAnomalyModel_Handler,
+		},
 		{
 			MethodName: "GetAlert",
 			Handler:    _TR181Api_GetAlert_Handler,
 		},
+		{
+			MethodName: "GetIncidents",
+			Handler:    _TR181Api_GetIncidents_Handler,
+		},
+		{
+			MethodName: "GetReboots",
+			Handler:    _TR181Api_GetReboots_Handler,
+		},
+		{
+			MethodName: "GetDeviceState",
+			Handler:    _TR181Api_GetDeviceState_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "api/proto/tr181_api.proto",
@@ -7,6 +7,7 @@ go mod download
 go build -o bin/api-gateway.exe ./services/api-gateway
 go build -o bin/data-ingestion.exe ./services/data-ingestion
 go build -o bin/alert-processor.exe ./services/alert-processor
+go build -o bin/notifier.exe ./services/notifier
 go build -o bin/simulator.exe ./simulator
 go build -o bin/log-viewer.exe ./services/log-viewer
 
@@ -13,6 +13,7 @@ go mod download
 go build -o bin/api-gateway ./services/api-gateway
 go build -o bin/data-ingestion ./services/data-ingestion
 go build -o bin/alert-processor ./services/alert-processor
+go build -o bin/notifier ./services/notifier
 go build -o bin/simulator ./simulator
 go build -o bin/log-viewer ./services/log-viewer
 
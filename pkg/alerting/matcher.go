@@ -0,0 +1,160 @@
+// Package alerting — сопоставление алертов с матчерами: маршруты уведомлений, тишины (silences) и окна
+// обслуживания. Общий для alert-processor (оценивает алерты) и api-gateway (проверяет при создании).
+package alerting
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Метки алерта, доступные матчерам
+const (
+	LabelAlertType    = "alert_type"
+	LabelSeverity     = "severity"
+	LabelSerialNumber = "serial_number"
+	LabelMetric       = "metric"
+	LabelGroup        = "group" // группы устройства: = и =~ — входит в одну из групп, != и !~ — ни в одну
+)
+
+// labels — известные метки
+var labels = map[string]bool{
+	LabelAlertType: true, LabelSeverity: true, LabelSerialNumber: true, LabelMetric: true, LabelGroup: true,
+}
+
+// Операторы матчера (как в Alertmanager)
+const (
+	OpEqual    = "="
+	OpNotEqual = "!="
+	OpRegex    = "=~" // регулярное выражение, совпадает со всем значением
+	OpNotRegex = "!~"
+)
+
+// matcherPattern — матчер в виде строки: severity=error, alert_type=~"high-.*", group!=lab
+var matcherPattern = regexp.MustCompile(`^\s*([a-z_]+)\s*(=~|!~|!=|=)\s*(.*?)\s*$`)
+
+// Alert — алерт для сопоставления
+type Alert struct {
+	SerialNumber string
+	Type         string
+	Severity     string
+	Metric       string   // пусто — алерт без метрики (или закрытие инцидента)
+	Groups       []string // группы устройства
+}
+
+// Matcher — условие на метку алерта
+type Matcher struct {
+	Label string
+	Op    string
+	Value string
+	re    *regexp.Regexp
+}
+
+// ParseMatcher разбирает матчер вида label=value (операторы =, !=, =~, !~; значение можно взять в кавычки)
+func ParseMatcher(s string) (Matcher, error) {
+	m := matcherPattern.FindStringSubmatch(s)
+	if m == nil {
+		return Matcher{}, fmt.Errorf("invalid matcher %q: expected label=value", s)
+	}
+	if !labels[m[1]] {
+		return Matcher{}, fmt.Errorf("invalid matcher %q: unknown label %s", s, m[1])
+	}
+	value := m[3]
+	if strings.HasPrefix(value, `"`) {
+		v, err := strconv.Unquote(value)
+		if err != nil {
+			return Matcher{}, fmt.Errorf("invalid matcher %q: bad quoted value", s)
+		}
+		value = v
+	}
+	matcher := Matcher{Label: m[1], Op: m[2], Value: value}
+	if matcher.Op == OpRegex || matcher.Op == OpNotRegex {
+		re, err := regexp.Compile("^(?:" + value + ")$")
+		if err != nil {
+			return Matcher{}, fmt.Errorf("invalid matcher %q: %w", s, err)
+		}
+		matcher.re = re
+	}
+	return matcher, nil
+}
+
+// ParseMatchers разбирает список матчеров
+func ParseMatchers(ss []string) ([]Matcher, error) {
+	matchers := make([]Matcher, 0, len(ss))
+	for _, s := range ss {
+		m, err := ParseMatcher(s)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+// String — матчер в каноническом виде (значение в кавычках)
+func (m Matcher) String() string {
+	return m.Label + m.Op + strconv.Quote(m.Value)
+}
+
+// Positive — матчер выбирает конкретные алерты (= или =~ с непустым значением), а не исключает
+func (m Matcher) Positive() bool {
+	switch m.Op {
+	case OpEqual:
+		return m.Value != ""
+	case OpRegex:
+		return !m.re.MatchString("")
+	}
+	return false
+}
+
+// Matches — совпадает ли матчер с алертом
+func (m Matcher) Matches(a *Alert) bool {
+	if m.Label == LabelGroup {
+		found := false
+		for _, g := range a.Groups {
+			if m.matchValue(g) {
+				found = true
+				break
+			}
+		}
+		if m.Op == OpEqual || m.Op == OpRegex {
+			return found
+		}
+		return !found
+	}
+
+	var v string
+	switch m.Label {
+	case LabelAlertType:
+		v = a.Type
+	case LabelSeverity:
+		v = a.Severity
+	case LabelSerialNumber:
+		v = a.SerialNumber
+	case LabelMetric:
+		v = a.Metric
+	}
+	if m.Op == OpEqual || m.Op == OpRegex {
+		return m.matchValue(v)
+	}
+	return !m.matchValue(v)
+}
+
+// matchValue — значение совпадает (без учёта отрицания)
+func (m Matcher) matchValue(v string) bool {
+	if m.re != nil {
+		return m.re.MatchString(v)
+	}
+	return v == m.Value
+}
+
+// MatchAll — алерт совпадает со всеми матчерами (пустой список — с любым алертом)
+func MatchAll(matchers []Matcher, a *Alert) bool {
+	for _, m := range matchers {
+		if !m.Matches(a) {
+			return false
+		}
+	}
+	return true
+}
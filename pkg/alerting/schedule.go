@@ -0,0 +1,94 @@
+// Package alerting — см. package doc в matcher.go
+package alerting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxWindowDuration — максимальная длительность окна обслуживания
+const MaxWindowDuration = 24 * time.Hour
+
+// weekdays — дни недели в расписании окна
+var weekdays = map[string]time.Weekday{
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+// Schedule — повторяющееся окно: начинается в Start по местному времени в дни Days и длится Duration
+type Schedule struct {
+	days     map[time.Weekday]bool // пусто — каждый день
+	start    time.Duration         // от полуночи
+	duration time.Duration
+	loc      *time.Location
+}
+
+// NewSchedule разбирает расписание: days — mon..sun (пусто — каждый день), start — ЧЧ:ММ,
+// timezone — имя из базы IANA (пусто — UTC)
+func NewSchedule(days []string, start string, duration time.Duration, timezone string) (*Schedule, error) {
+	s := &Schedule{days: make(map[time.Weekday]bool, len(days)), duration: duration, loc: time.UTC}
+	for _, d := range days {
+		wd, ok := weekdays[strings.ToLower(strings.TrimSpace(d))]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q: expected mon, tue, wed, thu, fri, sat or sun", d)
+		}
+		s.days[wd] = true
+	}
+	t, err := time.Parse("15:04", start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start %q: expected HH:MM", start)
+	}
+	s.start = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	if duration <= 0 || duration > MaxWindowDuration {
+		return nil, fmt.Errorf("duration must be between 0 and %s", MaxWindowDuration)
+	}
+	if timezone != "" {
+		if s.loc, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q", timezone)
+		}
+	}
+	return s, nil
+}
+
+// Active — идёт ли окно в момент t. Окно длится не больше суток, поэтому достаточно проверить
+// начавшиеся сегодня и вчера (по местному времени)
+func (s *Schedule) Active(t time.Time) bool {
+	local := t.In(s.loc)
+	for back := 0; back <= 1; back++ {
+		day := local.AddDate(0, 0, -back)
+		if len(s.days) > 0 && !s.days[day.Weekday()] {
+			continue
+		}
+		begin := s.occurrence(day)
+		if !t.Before(begin) && t.Before(begin.Add(s.duration)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Next — начало ближайшего окна после t (или текущего, если окно идёт)
+func (s *Schedule) Next(t time.Time) time.Time {
+	local := t.In(s.loc)
+	for back := 1; back >= -7; back-- {
+		day := local.AddDate(0, 0, -back)
+		if len(s.days) > 0 && !s.days[day.Weekday()] {
+			continue
+		}
+		begin := s.occurrence(day)
+		if t.Before(begin.Add(s.duration)) {
+			return begin
+		}
+	}
+	return time.Time{}
+}
+
+// occurrence — начало окна в день day по местному времени (при переходе на летнее время окно
+// начинается в то же время по часам)
+func (s *Schedule) occurrence(day time.Time) time.Time {
+	y, m, d := day.Date()
+	hour := int(s.start / time.Hour)
+	minute := int(s.start % time.Hour / time.Minute)
+	return time.Date(y, m, d, hour, minute, 0, 0, s.loc)
+}
@@ -0,0 +1,49 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+
+	"github.com/lib/pq"
+)
+
+// AlertRuleRecord — правило алерта из таблицы alert_rules (проверяется alert-processor при загрузке)
+type AlertRuleRecord struct {
+	Name       string
+	AlertType  string
+	Metrics    []string
+	Condition  string
+	Threshold  int
+	Severity   string
+	Severities []byte // JSON: [{"severity": "error", "threshold": 80}]
+	ForSeconds int    // условие нарушено непрерывно не меньше (0 — срабатывать сразу)
+	Count      int    // условие нарушено в Count из последних Samples сэмплов (0 — не проверять)
+	Samples    int
+	Clear      *int // порог восстановления (NULL — threshold)
+	ClearFor   int  // восстановление держится не меньше, секунд
+	Enabled    bool
+}
+
+// GetAlertRules возвращает все правила алертов (включая выключенные — они выключают встроенные правила)
+func (p *PostgresDB) GetAlertRules(ctx context.Context) ([]AlertRuleRecord, error) {
+	query := `SELECT name, alert_type, metrics, condition, threshold, severity, severities, for_seconds, count, samples,
+				  clear_threshold, clear_for_seconds, enabled
+			  FROM alert_rules
+			  ORDER BY name`
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []AlertRuleRecord
+	for rows.Next() {
+		var r AlertRuleRecord
+		if err := rows.Scan(&r.Name, &r.AlertType, pq.Array(&r.Metrics), &r.Condition, &r.Threshold, &r.Severity, &r.Severities, &r.ForSeconds, &r.Count, &r.Samples,
+			&r.Clear, &r.ClearFor, &r.Enabled); err != nil {
+			return nil, err
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
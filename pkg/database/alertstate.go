@@ -0,0 +1,87 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Состояние условий правил алертов (alert-processor): для каждой пары правило + устройство —
+// начало непрерывного нарушения, маска последних сэмплов и время последнего сэмпла. Обновляется одним
+// скриптом, поэтому экземпляры alert-processor с общей подпиской видят согласованное состояние,
+// а повторная доставка и запоздавшие сэмплы его не портят.
+
+// observeConditionScript обновляет состояние условия.
+// KEYS[1] — ключ состояния; ARGV[1] — время сэмпла (Unix мс), ARGV[2] — 1, если условие нарушено,
+// ARGV[3] — размер окна в сэмплах (0 — не считать), ARGV[4] — TTL (мс): без сэмплов дольше состояние сбрасывается.
+// Тот же сэмпл повторно (повторная доставка) получает тот же ответ без изменения состояния — оно уже
+// отражает этот сэмпл; запоздавший сэмпл (раньше последнего) не учитывается и ответ {0, 0} не даёт алерта.
+// Возвращает {начало нарушения (мс, 0 — условие не нарушено), число нарушений в окне}
+var observeConditionScript = redis.NewScript(`
+local ts = tonumber(ARGV[1])
+local breach = ARGV[2] == '1'
+local n = tonumber(ARGV[3])
+local state = redis.call('HMGET', KEYS[1], 'since', 'bits', 'ts')
+local since = tonumber(state[1]) or 0
+local bits = tonumber(state[2]) or 0
+local last = tonumber(state[3])
+
+local function popcount(b)
+	local hits = 0
+	while b > 0 do
+		hits = hits + b % 2
+		b = math.floor(b / 2)
+	end
+	return hits
+end
+
+if last and ts <= last then
+	if ts == last then
+		return {since, n > 0 and popcount(bits) or 0}
+	end
+	return {0, 0}
+end
+
+if not breach then
+	since = 0
+elseif since == 0 then
+	since = ts
+end
+
+local hits = 0
+if n > 0 then
+	bits = (bits * 2 + (breach and 1 or 0)) % (2 ^ n)
+	hits = popcount(bits)
+end
+
+redis.call('HSET', KEYS[1], 'since', since, 'bits', bits, 'ts', ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {since, hits}
+`)
+
+// conditionKey — ключ состояния условия правила rule для устройства
+func conditionKey(rule, serialNumber string) string {
+	return "alert:cond:" + rule + ":" + serialNumber
+}
+
+// ObserveCondition записывает сэмпл условия правила rule устройства и возвращает начало текущего непрерывного
+// нарушения (нулевое время — условие не нарушено) и число нарушений среди последних samples сэмплов.
+// Состояние живёт ttl после последнего сэмпла: перерыв в данных длиннее ttl начинает отсчёт заново
+func (r *RedisCache) ObserveCondition(ctx context.Context, rule, serialNumber string, ts time.Time, breach bool, samples int, ttl time.Duration) (time.Time, int, error) {
+	flag := 0
+	if breach {
+		flag = 1
+	}
+	res, err := observeConditionScript.Run(ctx, r.client, []string{conditionKey(rule, serialNumber)},
+		ts.UnixMilli(), flag, samples, ttl.Milliseconds()).Int64Slice()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	var since time.Time
+	if res[0] > 0 {
+		since = time.UnixMilli(res[0])
+	}
+	return since, int(res[1]), nil
+}
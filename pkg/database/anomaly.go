@@ -0,0 +1,128 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Модели аномалий (alert-processor): для каждой пары метрика + устройство — экспоненциально взвешенные
+// среднее и дисперсия (EWMA) и число учтённых сэмплов. Модель обновляется одним скриптом, поэтому
+// экземпляры alert-processor с общей подпиской видят одну модель; api-gateway только читает её.
+
+// anomalyModelTTL — сколько хранится модель устройства без новых сэмплов
+const anomalyModelTTL = 30 * 24 * time.Hour
+
+// AnomalyModel — модель нормального поведения метрики устройства
+type AnomalyModel struct {
+	SerialNumber string    `json:"serial_number"`
+	MetricType   string    `json:"metric_type"`
+	Mean         float64   `json:"mean"`       // EWMA среднего
+	StdDev       float64   `json:"stddev"`     // корень из EWMA дисперсии
+	Samples      int       `json:"samples"`    // учтено сэмплов
+	UpdatedAt    time.Time `json:"updated_at"` // время последнего учтённого сэмпла
+}
+
+// updateAnomalyModelScript добавляет сэмпл в модель.
+// KEYS[1] — хэш модели; ARGV[1] — время сэмпла (мс), ARGV[2] — значение, ARGV[3] — вес нового сэмпла (alpha),
+// ARGV[4] — TTL (мс). Возвращает модель до сэмпла: {mean, var, n, время последнего сэмпла мс}.
+// Сэмпл с тем же временем (повторная доставка) модель не меняет и получает ту же модель, что и в первый раз
+// (хранится в pmean/pvar/pn), запоздавший — не меняет и получает текущую
+var updateAnomalyModelScript = redis.NewScript(`
+local ts = tonumber(ARGV[1])
+local x = tonumber(ARGV[2])
+local alpha = tonumber(ARGV[3])
+local s = redis.call('HMGET', KEYS[1], 'ts', 'mean', 'var', 'n', 'pmean', 'pvar', 'pn')
+local last = tonumber(s[1]) or 0
+if last > 0 and ts == last then
+	return {s[5], s[6], s[7], s[1]}
+end
+if ts < last then
+	return {s[2], s[3], s[4], s[1]}
+end
+
+local mean = tonumber(s[2]) or 0
+local var = tonumber(s[3]) or 0
+local n = tonumber(s[4]) or 0
+local nmean, nvar = x, 0
+if n > 0 then
+	local diff = x - mean
+	local incr = alpha * diff
+	nmean = mean + incr
+	nvar = (1 - alpha) * (var + diff * incr)
+end
+redis.call('HSET', KEYS[1], 'ts', ARGV[1], 'mean', tostring(nmean), 'var', tostring(nvar), 'n', tostring(n + 1),
+	'pmean', tostring(mean), 'pvar', tostring(var), 'pn', tostring(n))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {tostring(mean), tostring(var), tostring(n), tostring(last)}
+`)
+
+// anomalyModelKey — ключ модели метрики metricType устройства
+func anomalyModelKey(metricType, serialNumber string) string {
+	return "alert:anomaly:" + metricType + ":" + serialNumber
+}
+
+// UpdateAnomalyModel добавляет сэмпл value метрики в модель устройства (alpha — вес нового сэмпла)
+// и возвращает модель до этого сэмпла — с ней сэмпл и сравнивается
+func (r *RedisCache) UpdateAnomalyModel(ctx context.Context, metricType, serialNumber string, ts time.Time, value, alpha float64) (AnomalyModel, error) {
+	res, err := updateAnomalyModelScript.Run(ctx, r.client, []string{anomalyModelKey(metricType, serialNumber)},
+		ts.UnixMilli(), value, alpha, anomalyModelTTL.Milliseconds()).StringSlice()
+	if err != nil {
+		return AnomalyModel{}, err
+	}
+	return parseAnomalyModel(serialNumber, metricType, res)
+}
+
+// GetAnomalyModel возвращает текущую модель метрики устройства. nil, nil — модели нет
+func (r *RedisCache) GetAnomalyModel(ctx context.Context, metricType, serialNumber string) (*AnomalyModel, error) {
+	res, err := r.client.HMGet(ctx, anomalyModelKey(metricType, serialNumber), "mean", "var", "n", "ts").Result()
+	if err != nil {
+		return nil, err
+	}
+	fields := make([]string, len(res))
+	for i, v := range res {
+		s, ok := v.(string)
+		if !ok {
+			return nil, nil
+		}
+		fields[i] = s
+	}
+	m, err := parseAnomalyModel(serialNumber, metricType, fields)
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// parseAnomalyModel разбирает {mean, var, n, время мс} из Redis
+func parseAnomalyModel(serialNumber, metricType string, fields []string) (AnomalyModel, error) {
+	m := AnomalyModel{SerialNumber: serialNumber, MetricType: metricType}
+	if len(fields) < 4 || fields[0] == "" {
+		return m, nil // модели ещё нет
+	}
+	mean, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return m, err
+	}
+	variance, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return m, err
+	}
+	n, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return m, err
+	}
+	ms, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return m, err
+	}
+	m.Mean, m.StdDev, m.Samples = mean, math.Sqrt(math.Max(variance, 0)), n
+	if ms > 0 {
+		m.UpdatedAt = time.UnixMilli(ms)
+	}
+	return m, nil
+}
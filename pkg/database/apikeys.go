@@ -0,0 +1,86 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// APIKey — API-ключ клиента. Сам ключ не хранится, только его хэш
+type APIKey struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"` // начало ключа — чтобы узнать ключ в списке
+	KeyHash   string     `json:"-"`
+	Role      string     `json:"role"`   // viewer, operator или admin
+	Scopes    []string   `json:"scopes"` // group:<имя>, pattern:<glob> или *
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKey сохраняет новый API-ключ (хэш и префикс считает вызывающий)
+func (p *PostgresDB) CreateAPIKey(ctx context.Context, name, prefix, keyHash, role string, scopes []string) (*APIKey, error) {
+	query := `INSERT INTO api_keys (name, prefix, key_hash, role, scopes) VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at`
+	key := &APIKey{Name: name, Prefix: prefix, KeyHash: keyHash, Role: role, Scopes: scopes}
+	err := p.db.QueryRowContext(ctx, query, name, prefix, keyHash, role, pq.Array(scopes)).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// GetAPIKeyByHash ищет действующий (не отозванный) ключ по хэшу. nil,nil — не найден
+func (p *PostgresDB) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	query := `SELECT id, name, prefix, key_hash, role, scopes, created_at
+			  FROM api_keys
+			  WHERE key_hash = $1 AND revoked_at IS NULL`
+
+	var key APIKey
+	err := p.db.QueryRowContext(ctx, query, keyHash).Scan(&key.ID, &key.Name, &key.Prefix, &key.KeyHash,
+		&key.Role, pq.Array(&key.Scopes), &key.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAPIKeys возвращает все ключи (включая отозванные), новые первыми
+func (p *PostgresDB) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	query := `SELECT id, name, prefix, key_hash, role, scopes, created_at, revoked_at
+			  FROM api_keys
+			  ORDER BY id DESC`
+
+	rows, err := p.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		var key APIKey
+		if err := rows.Scan(&key.ID, &key.Name, &key.Prefix, &key.KeyHash, &key.Role, pq.Array(&key.Scopes),
+			&key.CreatedAt, &key.RevokedAt); err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey отзывает ключ. false — ключ не найден или уже отозван
+func (p *PostgresDB) RevokeAPIKey(ctx context.Context, id int64) (bool, error) {
+	res, err := p.db.ExecContext(ctx, `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
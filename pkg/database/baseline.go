@@ -0,0 +1,72 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// MinBaselineDevices — минимум устройств в интервале для базовой линии: меньше — интервал не возвращается
+// (статистически не показателен и раскрывал бы значения отдельных устройств группы)
+const MinBaselineDevices = 5
+
+// BaselineBand — распределение средних значений устройств парка (или группы) в одном интервале
+type BaselineBand struct {
+	Devices int64   `json:"devices"` // число устройств с данными в интервале
+	P5      float64 `json:"p5"`
+	P25     float64 `json:"p25"`
+	P50     float64 `json:"p50"` // медиана
+	P75     float64 `json:"p75"`
+	P95     float64 `json:"p95"`
+}
+
+// FleetBaselineBucket — базовая линия в интервале, начинающемся в Time (Unix)
+type FleetBaselineBucket struct {
+	Time int64 `json:"time"`
+	BaselineBand
+}
+
+// GetFleetBaseline считает базовую линию метрики по парку за период [from, to) в интервалах bucket, выровненных
+// по эпохе (как GetMetricsDownsampled). Сначала значения усредняются по каждому устройству в интервале, чтобы
+// устройства с большим числом точек не перевешивали, затем считаются перцентили по устройствам.
+// group != "" — только устройства группы
+func (p *PostgresDB) GetFleetBaseline(ctx context.Context, metricType, group string, from, to time.Time, bucket time.Duration) ([]FleetBaselineBucket, error) {
+	filter := ""
+	args := []interface{}{metricType, from, to, int64(bucket.Seconds()), MinBaselineDevices}
+	if group != "" {
+		filter = `AND serial_number IN (SELECT serial_number FROM device_group_members WHERE group_name = $6)`
+		args = append(args, group)
+	}
+	query := `WITH per_device AS (
+				  SELECT (FLOOR(EXTRACT(EPOCH FROM timestamp) / $4) * $4)::BIGINT as time, AVG(value)::DOUBLE PRECISION as value
+				  FROM metrics
+				  WHERE metric_type = $1 AND timestamp >= $2 AND timestamp < $3 ` + filter + `
+				  GROUP BY 1, serial_number
+			  )
+			  SELECT time, COUNT(*),
+			         percentile_cont(0.05) WITHIN GROUP (ORDER BY value),
+			         percentile_cont(0.25) WITHIN GROUP (ORDER BY value),
+			         percentile_cont(0.5) WITHIN GROUP (ORDER BY value),
+			         percentile_cont(0.75) WITHIN GROUP (ORDER BY value),
+			         percentile_cont(0.95) WITHIN GROUP (ORDER BY value)
+			  FROM per_device
+			  GROUP BY 1
+			  HAVING COUNT(*) >= $5
+			  ORDER BY 1 ASC`
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []FleetBaselineBucket{}
+	for rows.Next() {
+		var b FleetBaselineBucket
+		if err := rows.Scan(&b.Time, &b.Devices, &b.P5, &b.P25, &b.P50, &b.P75, &b.P95); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
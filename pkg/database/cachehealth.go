@@ -0,0 +1,193 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Деградированный режим кэша: пока Redis недоступен, команды сразу возвращают ErrCacheUnavailable
+// (без ожидания таймаутов соединения), запросы обслуживаются из локального кэша и PostgreSQL.
+// Фоновая проверка пингует Redis и возвращает кэш в работу после восстановления соединения.
+
+// ErrCacheUnavailable — Redis недоступен, кэш работает в деградированном режиме
+var ErrCacheUnavailable = errors.New("redis unavailable")
+
+const (
+	redisProbeInterval = 2 * time.Second // период проверки доступности Redis
+	redisProbeTimeout  = time.Second     // таймаут проверки
+	localChunkTTL      = 5 * time.Minute // чанк в локальном кэше (сброс — по сообщению от data-ingestion)
+)
+
+// metricChunkInvalidateChannel — канал Redis, в который публикуются ключи сброшенных чанков
+const metricChunkInvalidateChannel = "metric:chunk:invalidate"
+
+// CacheStats — состояние и счётчики уровней кэша
+type CacheStats struct {
+	RedisUp      bool   // Redis доступен (false — деградированный режим)
+	LocalEnabled bool   // включён локальный уровень
+	LocalEntries int    // записей в локальном кэше
+	LocalBytes   int64  // примерный объём локального кэша
+	LocalHits    uint64 // попадания в локальный кэш
+	LocalMisses  uint64
+	RedisHits    uint64 // попадания в Redis
+	RedisMisses  uint64
+	RedisErrors  uint64 // ошибки Redis при чтении кэша (в том числе в деградированном режиме)
+}
+
+// cacheCounters — счётчики попаданий по уровням
+type cacheCounters struct {
+	localHits, localMisses              atomic.Uint64
+	redisHits, redisMisses, redisErrors atomic.Uint64
+}
+
+// probeKey — метка контекста проверки доступности (проходит мимо деградированного режима)
+type probeKey struct{}
+
+// OpenRedisCache создаёт кэш без обязательного подключения при старте: если Redis недоступен,
+// кэш работает в деградированном режиме и переподключается автоматически.
+// localBytes > 0 — включить локальный LRU кэш такого объёма перед Redis
+func OpenRedisCache(addr string, localBytes int64) *RedisCache {
+	client := redis.NewClient(&redis.Options{
+		Addr:         addr,
+		PoolSize:     10,
+		MinIdleConns: 5,
+		DialTimeout:  time.Second, // недоступный Redis не должен задерживать запросы
+		ReadTimeout:  time.Second,
+		WriteTimeout: time.Second,
+	})
+	r := &RedisCache{client: client, stop: make(chan struct{})}
+	if localBytes > 0 {
+		r.local = newLocalCache(localBytes)
+	}
+	client.AddHook(healthHook{r: r})
+
+	r.probe() // начальное состояние до первого запроса
+	go r.monitor()
+	if r.local != nil {
+		go r.subscribeInvalidations()
+	}
+	return r
+}
+
+// Healthy — Redis доступен
+func (r *RedisCache) Healthy() bool {
+	return r.up.Load()
+}
+
+// Stats возвращает состояние и счётчики кэша
+func (r *RedisCache) Stats() CacheStats {
+	s := CacheStats{
+		RedisUp:     r.Healthy(),
+		LocalHits:   r.counters.localHits.Load(),
+		LocalMisses: r.counters.localMisses.Load(),
+		RedisHits:   r.counters.redisHits.Load(),
+		RedisMisses: r.counters.redisMisses.Load(),
+		RedisErrors: r.counters.redisErrors.Load(),
+	}
+	if r.local != nil {
+		s.LocalEnabled = true
+		s.LocalEntries, s.LocalBytes = r.local.usage()
+	}
+	return s
+}
+
+// monitor периодически проверяет Redis, пока кэш не закрыт
+func (r *RedisCache) monitor() {
+	ticker := time.NewTicker(redisProbeInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.probe()
+		}
+	}
+}
+
+// probe пингует Redis и обновляет состояние
+func (r *RedisCache) probe() {
+	ctx, cancel := context.WithTimeout(context.WithValue(context.Background(), probeKey{}, true), redisProbeTimeout)
+	defer cancel()
+	if err := r.client.Ping(ctx).Err(); err != nil {
+		r.markDown(err)
+		return
+	}
+	if !r.up.Swap(true) {
+		log.Printf("redis: connected, cache enabled")
+	}
+}
+
+// markDown переводит кэш в деградированный режим (в лог — только при переходе)
+func (r *RedisCache) markDown(err error) {
+	if r.up.Swap(false) || !r.downLogged.Swap(true) {
+		log.Printf("redis: unavailable, cache in degraded mode: %v", err)
+	}
+}
+
+// subscribeInvalidations удаляет из локального кэша чанки, сброшенные data-ingestion.
+// Подписка переподключается сама (go-redis)
+func (r *RedisCache) subscribeInvalidations() {
+	ps := r.client.Subscribe(context.Background(), metricChunkInvalidateChannel)
+	defer ps.Close()
+	ch := ps.Channel()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			r.local.remove(msg.Payload)
+		}
+	}
+}
+
+// healthHook — в деградированном режиме сразу отклоняет команды, при ошибке соединения включает его
+type healthHook struct {
+	r *RedisCache
+}
+
+func (h healthHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h healthHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !h.r.up.Load() && ctx.Value(probeKey{}) == nil {
+			cmd.SetErr(ErrCacheUnavailable)
+			return ErrCacheUnavailable
+		}
+		err := next(ctx, cmd)
+		h.check(ctx, err)
+		return err
+	}
+}
+
+func (h healthHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !h.r.up.Load() {
+			for _, cmd := range cmds {
+				cmd.SetErr(ErrCacheUnavailable)
+			}
+			return ErrCacheUnavailable
+		}
+		err := next(ctx, cmds)
+		h.check(ctx, err)
+		return err
+	}
+}
+
+// check включает деградированный режим при ошибке соединения (отмена запроса клиентом — не ошибка Redis)
+func (h healthHook) check(ctx context.Context, err error) {
+	if err != nil && ctx.Err() == nil && IsUnavailable(err) {
+		h.r.markDown(err)
+	}
+}
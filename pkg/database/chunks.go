@@ -0,0 +1,209 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Кэш метрик чанками: точки одной серии (устройство + тип метрики) за выровненный час.
+// Закрытый чанк неизменен, поэтому хранится долго и общий для всех запросов, попадающих в этот час.
+// Запоздавшие данные (точка в уже закрытом чанке) data-ingestion помечает надгробием:
+// чанк перечитывается из PostgreSQL, а заполнение идёт через SET NX, чтобы не затереть
+// надгробие данными, прочитанными до вставки.
+const (
+	MetricChunkSpan         = time.Hour       // длина чанка (выравнивание по UTC)
+	metricChunkSettle       = 2 * time.Minute // чанк кэшируется, когда его конец старше этого (запас на разницу часов)
+	metricChunkTTL          = 24 * time.Hour  // время жизни закрытого чанка
+	metricChunkTombstone    = "-"             // значение-надгробие: чанк устарел, не кэшировать
+	metricChunkTombstoneTTL = 2 * time.Minute // время жизни надгробия (больше времени чтения чанка из PostgreSQL)
+)
+
+// MetricPoint — точка серии с id и точным временем (для keyset-пагинации по чанкам)
+type MetricPoint struct {
+	ID       int64 `json:"i"`
+	TimeNano int64 `json:"t"` // Unix наносекунды
+	Value    int   `json:"v"`
+}
+
+// Time — время точки
+func (p MetricPoint) Time() time.Time {
+	return time.Unix(0, p.TimeNano)
+}
+
+// MetricChunk — точки серии за один чанк, начиная с Start
+type MetricChunk struct {
+	Start  time.Time
+	Points []MetricPoint
+}
+
+// MetricChunkStart — начало чанка, содержащего t
+func MetricChunkStart(t time.Time) time.Time {
+	return t.Truncate(MetricChunkSpan)
+}
+
+// ClosedMetricChunksEnd — граница закрытых чанков: чанки, начинающиеся раньше, можно кэшировать
+func ClosedMetricChunksEnd(now time.Time) time.Time {
+	return MetricChunkStart(now.Add(-metricChunkSettle))
+}
+
+// IsLateMetric — точка с временем ts попадает в уже закончившийся чанк (его кэш нужно сбросить)
+func IsLateMetric(ts, now time.Time) bool {
+	return MetricChunkStart(ts).Before(MetricChunkStart(now))
+}
+
+// metricChunkKey — ключ чанка: metric:chunk:<тип>:<устройство>:<начало, Unix>
+func metricChunkKey(metricType, serialNumber string, start time.Time) string {
+	return fmt.Sprintf("metric:chunk:%s:%s:%d", metricType, serialNumber, start.Unix())
+}
+
+// GetMetricChunks читает чанки серии: сначала из локального кэша, остальные — из Redis.
+// Элемент nil — чанка нет в кэше (или он помечен устаревшим), пустой срез — в чанке нет точек.
+// При ошибке Redis возвращает найденное локально вместе с ошибкой
+func (r *RedisCache) GetMetricChunks(ctx context.Context, metricType, serialNumber string, starts []time.Time) ([][]MetricPoint, error) {
+	chunks := make([][]MetricPoint, len(starts))
+	var keys []string
+	var idx []int // позиции keys в chunks
+	for i, start := range starts {
+		key := metricChunkKey(metricType, serialNumber, start)
+		if r.local != nil {
+			if v, ok := r.local.get(key); ok {
+				r.counters.localHits.Add(1)
+				chunks[i] = v.([]MetricPoint)
+				continue
+			}
+			r.counters.localMisses.Add(1)
+		}
+		keys = append(keys, key)
+		idx = append(idx, i)
+	}
+	if len(keys) == 0 {
+		return chunks, nil
+	}
+
+	values, err := r.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		r.counters.redisErrors.Add(uint64(len(keys)))
+		return chunks, err
+	}
+	for j, v := range values {
+		s, ok := v.(string)
+		if !ok || s == metricChunkTombstone {
+			r.counters.redisMisses.Add(1)
+			continue
+		}
+		points := []MetricPoint{}
+		if err := json.Unmarshal([]byte(s), &points); err != nil {
+			r.counters.redisMisses.Add(1)
+			continue // повреждённый чанк перечитаем из PostgreSQL
+		}
+		r.counters.redisHits.Add(1)
+		chunks[idx[j]] = points
+		r.cacheChunkLocally(keys[j], points)
+	}
+	return chunks, nil
+}
+
+// cacheChunkLocally кладёт чанк в локальный кэш (если он включён)
+func (r *RedisCache) cacheChunkLocally(key string, points []MetricPoint) {
+	if r.local != nil {
+		r.local.set(key, points, int64(len(key)+24*len(points)+64), localChunkTTL)
+	}
+}
+
+// CacheMetricChunks сохраняет закрытые чанки. Существующие ключи не перезаписываются:
+// надгробие от data-ingestion важнее данных, прочитанных до вставки запоздавшей точки.
+// Локально чанк кэшируется, только если он записан в Redis (или Redis недоступен)
+func (r *RedisCache) CacheMetricChunks(ctx context.Context, metricType, serialNumber string, chunks []MetricChunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	keys := make([]string, len(chunks))
+	cmds := make([]*redis.BoolCmd, len(chunks))
+	pipe := r.client.Pipeline()
+	for i, chunk := range chunks {
+		if chunk.Points == nil {
+			chunks[i].Points = []MetricPoint{} // в JSON — [] (пустой чанк тоже кэшируется)
+		}
+		data, err := json.Marshal(chunks[i].Points)
+		if err != nil {
+			return err
+		}
+		keys[i] = metricChunkKey(metricType, serialNumber, chunk.Start)
+		cmds[i] = pipe.SetNX(ctx, keys[i], data, metricChunkTTL)
+	}
+	_, err := pipe.Exec(ctx)
+	for i, cmd := range cmds {
+		if err != nil || cmd.Val() {
+			r.cacheChunkLocally(keys[i], chunks[i].Points)
+		}
+	}
+	return err
+}
+
+// InvalidateMetricChunk помечает чанк с точкой ts устаревшим (вызывается после вставки запоздавших данных)
+// и сообщает репликам gateway, чтобы они удалили его из локального кэша
+func (r *RedisCache) InvalidateMetricChunk(ctx context.Context, metricType, serialNumber string, ts time.Time) error {
+	key := metricChunkKey(metricType, serialNumber, MetricChunkStart(ts))
+	if r.local != nil {
+		r.local.remove(key)
+	}
+	pipe := r.client.Pipeline()
+	pipe.Set(ctx, key, metricChunkTombstone, metricChunkTombstoneTTL)
+	pipe.Publish(ctx, metricChunkInvalidateChannel, key)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// MetricChunkRef — чанк серии, кэш которого не удалось сбросить (Redis был недоступен)
+type MetricChunkRef struct {
+	MetricType   string
+	SerialNumber string
+	Start        time.Time
+	queuedAt     time.Time // время последней неудачи: удаляется только сброс, не обновлённый после чтения
+}
+
+// SaveChunkInvalidation запоминает несброшенный чанк с точкой ts. Повторная неудача обновляет время,
+// чтобы сброс, начатый до неё, не удалил запись
+func (p *PostgresDB) SaveChunkInvalidation(ctx context.Context, metricType, serialNumber string, ts time.Time) error {
+	query := `INSERT INTO metric_chunk_invalidations (metric_type, serial_number, chunk_start)
+			  VALUES ($1, $2, $3)
+			  ON CONFLICT (metric_type, serial_number, chunk_start) DO UPDATE SET created_at = NOW()`
+	_, err := p.db.ExecContext(ctx, query, metricType, serialNumber, MetricChunkStart(ts))
+	return err
+}
+
+// GetChunkInvalidations возвращает до limit несброшенных чанков (старые первыми)
+func (p *PostgresDB) GetChunkInvalidations(ctx context.Context, limit int) ([]MetricChunkRef, error) {
+	query := `SELECT metric_type, serial_number, chunk_start, created_at
+			  FROM metric_chunk_invalidations
+			  ORDER BY created_at
+			  LIMIT $1`
+	rows, err := p.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []MetricChunkRef
+	for rows.Next() {
+		var ref MetricChunkRef
+		if err := rows.Scan(&ref.MetricType, &ref.SerialNumber, &ref.Start, &ref.queuedAt); err != nil {
+			return nil, err
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// DeleteChunkInvalidation удаляет сброшенный чанк, если после его чтения новых неудач не было
+func (p *PostgresDB) DeleteChunkInvalidation(ctx context.Context, ref MetricChunkRef) error {
+	query := `DELETE FROM metric_chunk_invalidations
+			  WHERE metric_type = $1 AND serial_number = $2 AND chunk_start = $3 AND created_at = $4`
+	_, err := p.db.ExecContext(ctx, query, ref.MetricType, ref.SerialNumber, ref.Start, ref.queuedAt)
+	return err
+}
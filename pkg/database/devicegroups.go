@@ -0,0 +1,83 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// AddDeviceGroupMembers добавляет устройства в группу (повторное добавление игнорируется)
+func (p *PostgresDB) AddDeviceGroupMembers(ctx context.Context, group string, serialNumbers []string) error {
+	query := `INSERT INTO device_group_members (group_name, serial_number)
+			  SELECT $1, unnest($2::VARCHAR[])
+			  ON CONFLICT DO NOTHING`
+	_, err := p.db.ExecContext(ctx, query, group, pq.Array(serialNumbers))
+	return err
+}
+
+// RemoveDeviceGroupMember удаляет устройство из группы. false — устройства в группе не было
+func (p *PostgresDB) RemoveDeviceGroupMember(ctx context.Context, group, serialNumber string) (bool, error) {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM device_group_members WHERE group_name = $1 AND serial_number = $2`, group, serialNumber)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetDeviceGroupMembers возвращает устройства группы
+func (p *PostgresDB) GetDeviceGroupMembers(ctx context.Context, group string) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT serial_number FROM device_group_members WHERE group_name = $1 ORDER BY serial_number`, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	serials := []string{}
+	for rows.Next() {
+		var sn string
+		if err := rows.Scan(&sn); err != nil {
+			return nil, err
+		}
+		serials = append(serials, sn)
+	}
+	return serials, rows.Err()
+}
+
+// GetDeviceGroups возвращает группы, в которые входит устройство
+func (p *PostgresDB) GetDeviceGroups(ctx context.Context, serialNumber string) ([]string, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT group_name FROM device_group_members WHERE serial_number = $1`, serialNumber)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var groups []string
+	for rows.Next() {
+		var g string
+		if err := rows.Scan(&g); err != nil {
+			return nil, err
+		}
+		groups = append(groups, g)
+	}
+	return groups, rows.Err()
+}
+
+// AuditRecord — запись журнала аудита
+type AuditRecord struct {
+	PrincipalID   string
+	PrincipalName string
+	Action        string // например metric:read
+	Resource      string // например device:DEV-00000001
+	Reason        string
+	CreatedAt     time.Time
+}
+
+// SaveAuditRecord сохраняет запись журнала аудита
+func (p *PostgresDB) SaveAuditRecord(ctx context.Context, rec AuditRecord) error {
+	query := `INSERT INTO audit_log (principal_id, principal_name, action, resource, reason) VALUES ($1, $2, $3, $4, $5)`
+	_, err := p.db.ExecContext(ctx, query, rec.PrincipalID, rec.PrincipalName, rec.Action, rec.Resource, rec.Reason)
+	return err
+}
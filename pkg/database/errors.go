@@ -0,0 +1,53 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/lib/pq"
+	"github.com/redis/go-redis/v9"
+)
+
+// IsTimeout — запрос не уложился во время: истёк дедлайн контекста
+// или PostgreSQL отменил его по statement_timeout
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014" // query_canceled
+}
+
+// IsUnavailable — хранилище (PostgreSQL или Redis) недоступно: нет соединения,
+// сервер перезапускается или перегружен. Такой запрос имеет смысл повторить позже
+func IsUnavailable(err error) bool {
+	if err == nil || IsTimeout(err) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, redis.ErrClosed) || errors.Is(err, ErrCacheUnavailable) {
+		return true
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch {
+		case strings.HasPrefix(string(pqErr.Code), "08"): // connection_exception
+			return true
+		case strings.HasPrefix(string(pqErr.Code), "53"): // insufficient_resources (too_many_connections)
+			return true
+		case pqErr.Code == "57P01", pqErr.Code == "57P02", pqErr.Code == "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+			return true
+		}
+	}
+	return false
+}
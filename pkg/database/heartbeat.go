@@ -0,0 +1,109 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Отслеживание связи с устройствами (alert-processor): время последнего сообщения каждого устройства —
+// в sorted set, устройства, признанные недоступными, — в другом. Переход между ними делают скрипты,
+// поэтому при нескольких экземплярах alert-processor каждое отключение и возвращение фиксирует ровно один.
+
+const (
+	heartbeatKey = "alert:heartbeat" // устройство → время последнего сообщения (мс), только доступные
+	offlineKey   = "alert:offline"   // устройство → время последнего сообщения (мс) до отключения
+)
+
+// OfflineDevice — устройство, от которого давно не было сообщений
+type OfflineDevice struct {
+	SerialNumber string
+	LastSeen     time.Time
+}
+
+// touchDeviceScript отмечает сообщение устройства.
+// KEYS[1] — heartbeatKey, KEYS[2] — offlineKey; ARGV[1] — время (мс), ARGV[2] — серийный номер.
+// Если устройство было недоступно, убирает его из KEYS[2] и возвращает время последнего сообщения до отключения
+var touchDeviceScript = redis.NewScript(`
+local cur = tonumber(redis.call('ZSCORE', KEYS[1], ARGV[2]) or '0')
+if tonumber(ARGV[1]) > cur then
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+end
+local since = redis.call('ZSCORE', KEYS[2], ARGV[2])
+if since then
+	redis.call('ZREM', KEYS[2], ARGV[2])
+end
+return since
+`)
+
+// expireHeartbeatsScript переносит устройства без сообщений с ARGV[1] (мс) включительно из KEYS[1] в KEYS[2],
+// не больше ARGV[2] за вызов. Возвращает пары (серийный номер, время последнего сообщения мс)
+var expireHeartbeatsScript = redis.NewScript(`
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'WITHSCORES', 'LIMIT', 0, ARGV[2])
+for i = 1, #expired, 2 do
+	redis.call('ZREM', KEYS[1], expired[i])
+	redis.call('ZADD', KEYS[2], expired[i + 1], expired[i])
+end
+return expired
+`)
+
+// restoreHeartbeatScript возвращает устройство из KEYS[2] в KEYS[1], если оно всё ещё недоступно с тем же
+// временем последнего сообщения ARGV[1] (мс). ARGV[2] — серийный номер
+var restoreHeartbeatScript = redis.NewScript(`
+local since = redis.call('ZSCORE', KEYS[2], ARGV[2])
+if since and tonumber(since) == tonumber(ARGV[1]) then
+	redis.call('ZREM', KEYS[2], ARGV[2])
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+end
+return 0
+`)
+
+// TouchDevice отмечает сообщение устройства в момент now. Если устройство было признано недоступным,
+// возвращает время его последнего сообщения до отключения (иначе нулевое время)
+func (r *RedisCache) TouchDevice(ctx context.Context, serialNumber string, now time.Time) (time.Time, error) {
+	since, err := touchDeviceScript.Run(ctx, r.client, []string{heartbeatKey, offlineKey}, now.UnixMilli(), serialNumber).Text()
+	if err == redis.Nil {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	ms, err := strconv.ParseFloat(since, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(int64(ms)), nil
+}
+
+// ExpireHeartbeats признаёт недоступными устройства без сообщений с deadline включительно (не больше limit
+// за вызов) и возвращает их. Каждое устройство возвращается один раз — до следующего TouchDevice
+func (r *RedisCache) ExpireHeartbeats(ctx context.Context, deadline time.Time, limit int) ([]OfflineDevice, error) {
+	res, err := expireHeartbeatsScript.Run(ctx, r.client, []string{heartbeatKey, offlineKey}, deadline.UnixMilli(), limit).StringSlice()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+	devices := make([]OfflineDevice, 0, len(res)/2)
+	for i := 0; i+1 < len(res); i += 2 {
+		ms, err := strconv.ParseFloat(res[i+1], 64) // счёт sorted set
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, OfflineDevice{SerialNumber: res[i], LastSeen: time.UnixMilli(int64(ms))})
+	}
+	return devices, nil
+}
+
+// RestoreOffline снова признаёт устройство недоступным с последним сообщением lastSeen — если возвращение,
+// полученное TouchDevice, не удалось сохранить: повторная обработка сообщения зафиксирует его заново
+func (r *RedisCache) RestoreOffline(ctx context.Context, serialNumber string, lastSeen time.Time) error {
+	return r.client.ZAdd(ctx, offlineKey, redis.Z{Score: float64(lastSeen.UnixMilli()), Member: serialNumber}).Err()
+}
+
+// RestoreHeartbeat возвращает устройство, полученное ExpireHeartbeats, в доступные, если от него с тех пор
+// не было сообщений, — если отключение не удалось сохранить: следующая проверка найдёт устройство снова
+func (r *RedisCache) RestoreHeartbeat(ctx context.Context, d OfflineDevice) error {
+	return restoreHeartbeatScript.Run(ctx, r.client, []string{heartbeatKey, offlineKey}, d.LastSeen.UnixMilli(), d.SerialNumber).Err()
+}
@@ -0,0 +1,273 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Инциденты алертов: непрерывный период нарушения условия одного типа на устройстве — вместо строки
+// на каждый сэмпл. Когда инцидент открывается и закрывается, решает скрипт в Redis (один на все экземпляры
+// alert-processor), PostgreSQL хранит сами инциденты: начало, конец, пиковое и последнее значение, — а для
+// статистики алертов число и сумму значений нарушающих сэмплов по минутам (alert_stat_buckets).
+
+// incidentStateTTL — сколько хранится состояние инцидентов устройства без новых сэмплов
+const incidentStateTTL = 7 * 24 * time.Hour
+
+// Incident — инцидент алерта
+type Incident struct {
+	ID           int64      `json:"id"`
+	SerialNumber string     `json:"serial_number"`
+	AlertType    string     `json:"alert_type"`
+	Severity     string     `json:"severity"`         // уровень при пиковом значении
+	Metric       string     `json:"metric,omitempty"` // метрика при пиковом значении (для WiFi — диапазон)
+	StartedAt    time.Time  `json:"started_at"`
+	LastSeenAt   time.Time  `json:"last_seen_at"`       // последний нарушающий сэмпл
+	EndedAt      *time.Time `json:"ended_at,omitempty"` // nil — инцидент открыт
+	PeakValue    int        `json:"peak_value"`
+	LastValue    int        `json:"last_value"`
+	Samples      int        `json:"samples"`                 // число нарушающих сэмплов
+	SuppressedBy string     `json:"suppressed_by,omitempty"` // тишина или окно обслуживания при открытии
+}
+
+// IncidentSample — нарушающий сэмпл для UpsertIncident
+type IncidentSample struct {
+	SerialNumber string
+	AlertType    string
+	Severity     string
+	Metric       string
+	Value        int
+	Below        bool // худшее значение — минимальное
+	Timestamp    time.Time
+	SuppressedBy string // что подавило уведомление об открытии (пусто — не подавлено)
+}
+
+// IncidentFilter — параметры выборки инцидентов
+type IncidentFilter struct {
+	SerialNumber string
+	AlertType    string // пусто — все типы
+	Status       string // open, closed или пусто — все
+	From, To     time.Time
+	Limit        int
+}
+
+// IncidentEvent — открытие или закрытие инцидента (результат TrackIncidents)
+type IncidentEvent struct {
+	AlertType string
+	Opened    bool      // true — открыт, false — закрыт
+	Time      time.Time // время открытия или окончания (первый восстановившийся сэмпл)
+	Severity  string    // уровень закрытого инцидента (заполняет alert-processor при закрытии в PostgreSQL)
+}
+
+// UpsertIncident добавляет нарушающий сэмпл в открытый инцидент или открывает новый и учитывает его
+// в статистике алертов (alert_stat_buckets). Сэмпл старше уже закрытого инцидента (пришёл с опозданием)
+// новый инцидент не открывает; сэмпл не новее последнего в инциденте (повторная доставка или опоздание)
+// не учитывается, чтобы samples и статистика не росли дважды
+func (p *PostgresDB) UpsertIncident(ctx context.Context, s IncidentSample) error {
+	query := `WITH incident AS (
+				  INSERT INTO alert_incidents (serial_number, alert_type, severity, metric, started_at, last_seen_at,
+					  peak_value, last_value, suppressed_by)
+				  SELECT $1, $2, $3::VARCHAR, $4, $5::TIMESTAMPTZ, $5::TIMESTAMPTZ, $6::INTEGER, $6::INTEGER, $8
+				  WHERE NOT EXISTS (
+					  SELECT 1 FROM alert_incidents WHERE serial_number = $1 AND alert_type = $2 AND ended_at >= $5::TIMESTAMPTZ
+				  )
+				  ON CONFLICT (serial_number, alert_type) WHERE ended_at IS NULL DO UPDATE SET
+					  last_seen_at = EXCLUDED.last_seen_at,
+					  last_value = EXCLUDED.last_value,
+					  severity = CASE WHEN ($7 AND EXCLUDED.peak_value < alert_incidents.peak_value)
+					                    OR (NOT $7 AND EXCLUDED.peak_value > alert_incidents.peak_value)
+					                  THEN EXCLUDED.severity ELSE alert_incidents.severity END,
+					  metric = CASE WHEN ($7 AND EXCLUDED.peak_value < alert_incidents.peak_value)
+					                  OR (NOT $7 AND EXCLUDED.peak_value > alert_incidents.peak_value)
+					                THEN EXCLUDED.metric ELSE alert_incidents.metric END,
+					  peak_value = CASE WHEN $7 THEN LEAST(alert_incidents.peak_value, EXCLUDED.peak_value)
+					                    ELSE GREATEST(alert_incidents.peak_value, EXCLUDED.peak_value) END,
+					  samples = alert_incidents.samples + 1
+				  WHERE EXCLUDED.last_seen_at > alert_incidents.last_seen_at
+				  RETURNING 1
+			  )
+			  INSERT INTO alert_stat_buckets (serial_number, alert_type, severity, bucket, samples, total)
+			  SELECT $1, $2, $3::VARCHAR, date_trunc('minute', $5::TIMESTAMPTZ), 1, $6::INTEGER FROM incident
+			  ON CONFLICT (serial_number, alert_type, bucket, severity) DO UPDATE SET
+				  samples = alert_stat_buckets.samples + 1,
+				  total = alert_stat_buckets.total + EXCLUDED.total`
+	_, err := p.db.ExecContext(ctx, query, s.SerialNumber, s.AlertType, s.Severity, s.Metric, s.Timestamp, s.Value,
+		s.Below, s.SuppressedBy)
+	return err
+}
+
+// CloseIncident закрывает открытый инцидент (конец — не раньше последнего нарушающего сэмпла)
+// и возвращает его уровень. Если открытого нет, но инцидент уже закрыт этим же концом (повторная
+// обработка сообщения), возвращает его уровень; пусто — инцидента не было
+func (p *PostgresDB) CloseIncident(ctx context.Context, serialNumber, alertType string, endedAt time.Time) (string, error) {
+	query := `WITH closed AS (
+				  UPDATE alert_incidents SET ended_at = GREATEST($3, last_seen_at)
+				  WHERE serial_number = $1 AND alert_type = $2 AND ended_at IS NULL
+				  RETURNING severity
+			  )
+			  SELECT severity FROM closed
+			  UNION ALL
+			  (SELECT severity FROM alert_incidents
+			   WHERE serial_number = $1 AND alert_type = $2 AND ended_at = GREATEST($3, last_seen_at)
+			     AND NOT EXISTS (SELECT 1 FROM closed)
+			   ORDER BY started_at DESC LIMIT 1)`
+	var severity string
+	err := p.db.QueryRowContext(ctx, query, serialNumber, alertType, endedAt).Scan(&severity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return severity, err
+}
+
+// CloseIncidentWithEvent закрывает открытый инцидент alertType (как CloseIncident) и сохраняет алерт-событие
+// eventType в момент endedAt одной командой: либо записано и то и другое, либо ничего. Возвращает уровень
+// закрытого инцидента; пусто — открытого инцидента не было
+func (p *PostgresDB) CloseIncidentWithEvent(ctx context.Context, serialNumber, alertType string, endedAt time.Time, eventType, severity string, value int, suppressedBy string) (string, error) {
+	query := `WITH closed AS (
+				  UPDATE alert_incidents SET ended_at = GREATEST($3, last_seen_at)
+				  WHERE serial_number = $1 AND alert_type = $2 AND ended_at IS NULL
+				  RETURNING severity
+			  ), event AS (
+				  INSERT INTO alerts (serial_number, alert_type, severity, value, timestamp, suppressed, suppressed_by)
+				  VALUES ($1, $4, $5, $6, $3, $7, $8)
+			  )
+			  SELECT COALESCE((SELECT severity FROM closed), '')`
+	var closed string
+	err := p.db.QueryRowContext(ctx, query, serialNumber, alertType, endedAt, eventType, severity, value,
+		suppressedBy != "", suppressedBy).Scan(&closed)
+	return closed, err
+}
+
+// GetIncidents возвращает инциденты устройства, пересекающиеся с периодом [From, To], от новых к старым
+func (p *PostgresDB) GetIncidents(ctx context.Context, f IncidentFilter) ([]Incident, error) {
+	query := `SELECT id, serial_number, alert_type, severity, metric, started_at, last_seen_at, ended_at,
+				  peak_value, last_value, samples, suppressed_by
+			  FROM alert_incidents
+			  WHERE serial_number = $1 AND ($2 = '' OR alert_type = $2)
+			    AND started_at <= $4 AND (ended_at IS NULL OR ended_at >= $3)
+			    AND ($5 = '' OR ($5 = 'open') = (ended_at IS NULL))
+			  ORDER BY started_at DESC
+			  LIMIT $6`
+	rows, err := p.db.QueryContext(ctx, query, f.SerialNumber, f.AlertType, f.From, f.To, f.Status, f.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	incidents := []Incident{}
+	for rows.Next() {
+		var in Incident
+		if err := rows.Scan(&in.ID, &in.SerialNumber, &in.AlertType, &in.Severity, &in.Metric, &in.StartedAt, &in.LastSeenAt,
+			&in.EndedAt, &in.PeakValue, &in.LastValue, &in.Samples, &in.SuppressedBy); err != nil {
+			return nil, err
+		}
+		incidents = append(incidents, in)
+	}
+	return incidents, rows.Err()
+}
+
+// trackIncidentsScript обновляет состояние инцидентов устройства по одному сэмплу.
+// KEYS[1] — хэш состояния (поле — тип алерта, значение — "<открыт мс>:<восстановление с мс или 0>").
+// ARGV[1] — время сэмпла (мс), ARGV[2] — TTL (мс), ARGV[3] — число нарушенных типов n,
+// ARGV[4..3+n] — нарушенные типы, далее пары (восстановившийся тип, сколько держать восстановление, мс).
+// KEYS[2] — последний учтённый сэмпл (ts и ответ на него).
+// Открытый инцидент, тип которого не нарушен и не восстановился (между порогами), сбрасывает отсчёт восстановления.
+// Запоздавший сэмпл не учитывается; тот же сэмпл повторно (повторная доставка) получает тот же ответ.
+// Возвращает тройки (тип, "open" или "close", время мс)
+var trackIncidentsScript = redis.NewScript(`
+local ts = tonumber(ARGV[1])
+local last = redis.call('HMGET', KEYS[2], 'ts', 'out')
+if last[1] and ts <= tonumber(last[1]) then
+	if ts == tonumber(last[1]) and last[2] then
+		return cjson.decode(last[2])
+	end
+	return {}
+end
+
+local n = tonumber(ARGV[3])
+local firing, recovered, out = {}, {}, {}
+for i = 4, 3 + n do
+	firing[ARGV[i]] = true
+end
+for i = 4 + n, #ARGV, 2 do
+	recovered[ARGV[i]] = tonumber(ARGV[i + 1])
+end
+
+for t in pairs(firing) do
+	local v = redis.call('HGET', KEYS[1], t)
+	if v then
+		redis.call('HSET', KEYS[1], t, string.match(v, '^(%d+)') .. ':0')
+	else
+		redis.call('HSET', KEYS[1], t, ARGV[1] .. ':0')
+		table.insert(out, t)
+		table.insert(out, 'open')
+		table.insert(out, ARGV[1])
+	end
+end
+
+local state = redis.call('HGETALL', KEYS[1])
+for i = 1, #state, 2 do
+	local t = state[i]
+	if not firing[t] then
+		local opened, since = string.match(state[i + 1], '^(%d+):(%d+)$')
+		local clearFor = recovered[t]
+		if clearFor == nil then
+			if since ~= '0' then
+				redis.call('HSET', KEYS[1], t, opened .. ':0')
+			end
+		else
+			if since == '0' then
+				since = ARGV[1]
+			end
+			if ts - tonumber(since) >= clearFor then
+				redis.call('HDEL', KEYS[1], t)
+				table.insert(out, t)
+				table.insert(out, 'close')
+				table.insert(out, since)
+			elseif state[i + 1] ~= opened .. ':' .. since then
+				redis.call('HSET', KEYS[1], t, opened .. ':' .. since)
+			end
+		end
+	end
+end
+
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+redis.call('HSET', KEYS[2], 'ts', ARGV[1], 'out', cjson.encode(out))
+redis.call('PEXPIRE', KEYS[2], ARGV[2])
+return out
+`)
+
+// TrackIncidents обновляет состояние инцидентов устройства по сэмплу ts: firing — нарушенные типы алертов,
+// recovered — восстановившиеся типы и сколько восстановление должно держаться. Возвращает открытия и закрытия;
+// повторно для того же ts — те же (сообщение обрабатывается заново после ошибки сохранения)
+func (r *RedisCache) TrackIncidents(ctx context.Context, serialNumber string, ts time.Time, firing []string, recovered map[string]time.Duration) ([]IncidentEvent, error) {
+	args := []interface{}{ts.UnixMilli(), incidentStateTTL.Milliseconds(), len(firing)}
+	for _, t := range firing {
+		args = append(args, t)
+	}
+	for t, d := range recovered {
+		args = append(args, t, d.Milliseconds())
+	}
+	keys := []string{"alert:incident:" + serialNumber, "alert:incident:" + serialNumber + ":last"}
+	res, err := trackIncidentsScript.Run(ctx, r.client, keys, args...).StringSlice()
+	if err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	events := make([]IncidentEvent, 0, len(res)/3)
+	for i := 0; i+2 < len(res); i += 3 {
+		ms, err := strconv.ParseInt(res[i+2], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, IncidentEvent{AlertType: res[i], Opened: res[i+1] == "open", Time: time.UnixMilli(ms)})
+	}
+	return events, nil
+}
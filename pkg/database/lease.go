@@ -0,0 +1,132 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Аренда (lease) заполнения кэша и записи stale-while-revalidate.
+// Аренду держит одна реплика gateway, которая читает данные из PostgreSQL и кладёт их в кэш;
+// остальные ждут результат в Redis, а не идут в базу тем же запросом.
+
+// releaseLeaseScript удаляет аренду, только если она ещё наша (по токену).
+// KEYS[1] — ключ аренды, ARGV[1] — токен
+var releaseLeaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// leaseKey — ключ аренды заполнения name
+func leaseKey(name string) string {
+	return "lease:" + name
+}
+
+// AcquireLease пытается взять аренду name на ttl. Возвращает токен для ReleaseLease; ok=false — аренда занята
+func (r *RedisCache) AcquireLease(ctx context.Context, name string, ttl time.Duration) (string, bool, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", false, err
+	}
+	token := hex.EncodeToString(buf)
+	ok, err := r.client.SetNX(ctx, leaseKey(name), token, ttl).Result()
+	if err != nil {
+		return "", false, err
+	}
+	return token, ok, nil
+}
+
+// ReleaseLease освобождает аренду (чужую — истёкшую и взятую заново — не трогает)
+func (r *RedisCache) ReleaseLease(ctx context.Context, name, token string) error {
+	return releaseLeaseScript.Run(ctx, r.client, []string{leaseKey(name)}, token).Err()
+}
+
+// LeaseHeld — аренда name сейчас занята
+func (r *RedisCache) LeaseHeld(ctx context.Context, name string) (bool, error) {
+	n, err := r.client.Exists(ctx, leaseKey(name)).Result()
+	return n > 0, err
+}
+
+// swrEntry — запись stale-while-revalidate: данные и моменты, до которых они свежие и пригодные
+type swrEntry struct {
+	Data       json.RawMessage `json:"d"`
+	FreshUntil int64           `json:"f"` // Unix миллисекунды
+	StaleUntil int64           `json:"s"` // Unix миллисекунды (окончание TTL)
+}
+
+// GetSWR читает запись stale-while-revalidate: локальный кэш, затем Redis (там запись могла
+// обновить другая реплика). found=false — записи нет; fresh=false — запись устарела,
+// но ещё может отдаваться, пока её обновляют. Если Redis недоступен, отдаётся локальная запись
+func (r *RedisCache) GetSWR(ctx context.Context, key string) (data []byte, fresh, found bool, err error) {
+	now := time.Now().UnixMilli()
+	var local *swrEntry
+	if r.local != nil {
+		if v, ok := r.local.get(key); ok {
+			e := v.(swrEntry)
+			if now < e.FreshUntil {
+				r.counters.localHits.Add(1)
+				return e.Data, true, true, nil
+			}
+			local = &e
+		}
+		r.counters.localMisses.Add(1)
+	}
+
+	raw, err := r.client.Get(ctx, key).Bytes()
+	switch {
+	case err == redis.Nil:
+		r.counters.redisMisses.Add(1)
+	case err != nil:
+		r.counters.redisErrors.Add(1)
+		if local == nil {
+			return nil, false, false, err
+		}
+	default:
+		var e swrEntry
+		if err := json.Unmarshal(raw, &e); err != nil || len(e.Data) == 0 {
+			r.counters.redisMisses.Add(1) // запись старого формата — как промах
+			break
+		}
+		r.counters.redisHits.Add(1)
+		r.cacheSWRLocally(key, e)
+		return e.Data, now < e.FreshUntil, true, nil
+	}
+	if local != nil {
+		return local.Data, false, true, nil
+	}
+	return nil, false, false, nil
+}
+
+// SetSWR сохраняет запись: свежая fresh, затем ещё stale отдаётся устаревшей.
+// В локальный кэш запись попадает, даже если Redis недоступен
+func (r *RedisCache) SetSWR(ctx context.Context, key string, data []byte, fresh, stale time.Duration) error {
+	now := time.Now()
+	e := swrEntry{Data: data, FreshUntil: now.Add(fresh).UnixMilli(), StaleUntil: now.Add(fresh + stale).UnixMilli()}
+	r.cacheSWRLocally(key, e)
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, key, raw, fresh+stale).Err()
+}
+
+// cacheSWRLocally кладёт запись в локальный кэш до окончания её TTL
+func (r *RedisCache) cacheSWRLocally(key string, e swrEntry) {
+	if r.local == nil {
+		return
+	}
+	ttl := time.Until(time.UnixMilli(e.StaleUntil))
+	if e.StaleUntil == 0 {
+		ttl = time.Until(time.UnixMilli(e.FreshUntil))
+	}
+	if ttl > 0 {
+		r.local.set(key, e, int64(len(key)+len(e.Data)+64), ttl)
+	}
+}
@@ -0,0 +1,88 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// localCache — ограниченный по размеру LRU кэш в памяти процесса (первый уровень перед Redis).
+// Размер считается приблизительно: вызывающий передаёт «стоимость» записи в байтах
+type localCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	ll       *list.List               // от недавно использованных к давно
+	items    map[string]*list.Element // ключ → элемент ll
+}
+
+// localEntry — запись локального кэша
+type localEntry struct {
+	key     string
+	value   interface{}
+	size    int64
+	expires time.Time
+}
+
+// newLocalCache создаёт LRU кэш на maxBytes байт
+func newLocalCache(maxBytes int64) *localCache {
+	return &localCache{maxBytes: maxBytes, ll: list.New(), items: make(map[string]*list.Element)}
+}
+
+// get возвращает значение ключа; истёкшие записи удаляются
+func (c *localCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*localEntry)
+	if time.Now().After(e.expires) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return e.value, true
+}
+
+// set сохраняет значение на ttl и вытесняет давно использованные записи сверх лимита.
+// Запись больше всего кэша не сохраняется
+func (c *localCache) set(key string, value interface{}, size int64, ttl time.Duration) {
+	if size > c.maxBytes {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	c.items[key] = c.ll.PushFront(&localEntry{key: key, value: value, size: size, expires: time.Now().Add(ttl)})
+	c.bytes += size
+	for c.bytes > c.maxBytes {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// remove удаляет ключ
+func (c *localCache) remove(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// usage — число записей и занятый объём
+func (c *localCache) usage() (int, int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items), c.bytes
+}
+
+func (c *localCache) removeElement(el *list.Element) {
+	e := c.ll.Remove(el).(*localEntry)
+	delete(c.items, e.key)
+	c.bytes -= e.size
+}
@@ -0,0 +1,55 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+// Доставка уведомлений: notifier записывает по строке на событие алерта и канал. Повторно доставленное
+// из Pulsar событие уже доставленным каналам не отправляется.
+
+// Статусы доставки
+const (
+	DeliveryPending   = "pending"   // отправляется (или notifier остановился во время отправки)
+	DeliveryDelivered = "delivered" // доставлено
+	DeliveryFailed    = "failed"    // попытки исчерпаны или канал отверг уведомление
+)
+
+// NotificationDelivery — доставка события eventID в канал channel
+type NotificationDelivery struct {
+	EventID      string
+	Channel      string
+	SerialNumber string
+	AlertType    string
+}
+
+// BeginDelivery отмечает начало доставки (статус pending) и возвращает её id. ok=false — событие
+// в этот канал уже доставлено, отправлять не нужно
+func (p *PostgresDB) BeginDelivery(ctx context.Context, d NotificationDelivery) (id int64, ok bool, err error) {
+	query := `INSERT INTO notification_deliveries (event_id, channel, serial_number, alert_type, status)
+			  VALUES ($1, $2, $3, $4, $5)
+			  ON CONFLICT (event_id, channel) DO UPDATE SET status = EXCLUDED.status, updated_at = NOW()
+			  WHERE notification_deliveries.status <> $6
+			  RETURNING id`
+	err = p.db.QueryRowContext(ctx, query, d.EventID, d.Channel, d.SerialNumber, d.AlertType,
+		DeliveryPending, DeliveryDelivered).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return id, true, nil
+}
+
+// UpdateDelivery записывает результат доставки: статус, число попыток (добавляется к прежним)
+// и последнюю ошибку
+func (p *PostgresDB) UpdateDelivery(ctx context.Context, id int64, status string, attempts int, lastError string) error {
+	query := `UPDATE notification_deliveries
+			  SET status = $2, attempts = attempts + $3, last_error = $4, updated_at = NOW()
+			  WHERE id = $1`
+	_, err := p.db.ExecContext(ctx, query, id, status, attempts, lastError)
+	return err
+}
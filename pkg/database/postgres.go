@@ -5,9 +5,10 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 // PostgresDB — обертка над sql.DB для метрик и алертов (TimescaleDB)
@@ -67,6 +68,15 @@ func (p *PostgresDB) InitSchema(ctx context.Context) error {
 		`CREATE INDEX IF NOT EXISTS idx_metrics_serial_time ON metrics(serial_number, timestamp DESC);`,
 		`CREATE INDEX IF NOT EXISTS idx_metrics_type_time ON metrics(metric_type, timestamp DESC);`,
 
+		// Несброшенные чанки кэша метрик (Redis был недоступен): data-ingestion сбрасывает их повторно
+		`CREATE TABLE IF NOT EXISTS metric_chunk_invalidations (
+			metric_type VARCHAR(100) NOT NULL,
+			serial_number VARCHAR(255) NOT NULL,
+			chunk_start TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (metric_type, serial_number, chunk_start)
+		);`,
+
 		// Таблица для алертов
 		`CREATE TABLE IF NOT EXISTS alerts (
 			id BIGSERIAL PRIMARY KEY,
@@ -81,6 +91,142 @@ func (p *PostgresDB) InitSchema(ctx context.Context) error {
 		`CREATE INDEX IF NOT EXISTS idx_alerts_serial_time ON alerts(serial_number, timestamp DESC);`,
 		`CREATE INDEX IF NOT EXISTS idx_alerts_type_time ON alerts(alert_type, timestamp DESC);`,
 		`CREATE INDEX IF NOT EXISTS idx_alerts_processed ON alerts(processed) WHERE processed = FALSE;`,
+		// Уровень важности алерта (строки до его появления считаются warning)
+		`ALTER TABLE alerts ADD COLUMN IF NOT EXISTS severity VARCHAR(32) NOT NULL DEFAULT 'warning';`,
+		// Сработавшая метрика: из нескольких метрик правила (например диапазонов WiFi) — та, что дала значение
+		`ALTER TABLE alerts ADD COLUMN IF NOT EXISTS metric VARCHAR(64) NOT NULL DEFAULT '';`,
+		// Подавленный алерт (тишина или окно обслуживания): сохраняется, но уведомление не отправляется
+		`ALTER TABLE alerts ADD COLUMN IF NOT EXISTS suppressed BOOLEAN NOT NULL DEFAULT FALSE;`,
+		`ALTER TABLE alerts ADD COLUMN IF NOT EXISTS suppressed_by VARCHAR(64) NOT NULL DEFAULT '';`,
+
+		// API-ключи: храним только SHA-256 хэш ключа
+		`CREATE TABLE IF NOT EXISTS api_keys (
+			id BIGSERIAL PRIMARY KEY,
+			name VARCHAR(255) NOT NULL,
+			prefix VARCHAR(32) NOT NULL,
+			key_hash CHAR(64) NOT NULL UNIQUE,
+			role VARCHAR(32) NOT NULL DEFAULT 'viewer',
+			scopes TEXT[] NOT NULL DEFAULT '{}',
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			revoked_at TIMESTAMPTZ
+		);`,
+		// Миграция ключей, созданных до появления ролей
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS role VARCHAR(32) NOT NULL DEFAULT 'viewer';`,
+		`ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] NOT NULL DEFAULT '{}';`,
+
+		// Группы устройств (для ограничения доступа): группа существует, пока в ней есть устройства
+		`CREATE TABLE IF NOT EXISTS device_group_members (
+			group_name VARCHAR(255) NOT NULL,
+			serial_number VARCHAR(255) NOT NULL,
+			PRIMARY KEY (group_name, serial_number)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_device_group_members_serial ON device_group_members(serial_number);`,
+
+		// Журнал аудита (отказы в доступе)
+		`CREATE TABLE IF NOT EXISTS audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			principal_id VARCHAR(255) NOT NULL,
+			principal_name VARCHAR(255) NOT NULL,
+			action VARCHAR(100) NOT NULL,
+			resource VARCHAR(255) NOT NULL,
+			reason TEXT NOT NULL,
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log(created_at DESC);`,
+
+		// Правила алертов (alert-processor): дополняют встроенные и переопределяют их по имени
+		`CREATE TABLE IF NOT EXISTS alert_rules (
+			name VARCHAR(100) PRIMARY KEY,
+			alert_type VARCHAR(100) NOT NULL,
+			metrics TEXT[] NOT NULL,
+			condition VARCHAR(2) NOT NULL,
+			threshold INTEGER NOT NULL,
+			severity VARCHAR(32) NOT NULL DEFAULT 'warning',
+			severities JSONB NOT NULL DEFAULT '[]',
+			enabled BOOLEAN NOT NULL DEFAULT TRUE,
+			updated_at TIMESTAMPTZ DEFAULT NOW()
+		);`,
+		// Условия с длительностью: for (секунды) и count из последних samples сэмплов
+		`ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS for_seconds INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS count INTEGER NOT NULL DEFAULT 0;`,
+		`ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS samples INTEGER NOT NULL DEFAULT 0;`,
+		// Гистерезис: порог восстановления (NULL — threshold) и сколько восстановление должно держаться
+		`ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS clear_threshold INTEGER;`,
+		`ALTER TABLE alert_rules ADD COLUMN IF NOT EXISTS clear_for_seconds INTEGER NOT NULL DEFAULT 0;`,
+
+		// Инциденты алертов: открытым может быть один инцидент на устройство и тип алерта
+		`CREATE TABLE IF NOT EXISTS alert_incidents (
+			id BIGSERIAL PRIMARY KEY,
+			serial_number VARCHAR(255) NOT NULL,
+			alert_type VARCHAR(100) NOT NULL,
+			severity VARCHAR(32) NOT NULL,
+			started_at TIMESTAMPTZ NOT NULL,
+			last_seen_at TIMESTAMPTZ NOT NULL,
+			ended_at TIMESTAMPTZ,
+			peak_value INTEGER NOT NULL,
+			last_value INTEGER NOT NULL,
+			samples INTEGER NOT NULL DEFAULT 1
+		);`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS idx_alert_incidents_open ON alert_incidents(serial_number, alert_type) WHERE ended_at IS NULL;`,
+		`CREATE INDEX IF NOT EXISTS idx_alert_incidents_serial_time ON alert_incidents(serial_number, started_at DESC);`,
+		// Сработавшая метрика (при пиковом значении) и подавление при открытии
+		`ALTER TABLE alert_incidents ADD COLUMN IF NOT EXISTS metric VARCHAR(64) NOT NULL DEFAULT '';`,
+		`ALTER TABLE alert_incidents ADD COLUMN IF NOT EXISTS suppressed_by VARCHAR(64) NOT NULL DEFAULT '';`,
+
+		// Статистика алертов: нарушающие сэмплы инцидентов строками в alerts не сохраняются,
+		// вместо них — число и сумма значений за минуту по уровням
+		`CREATE TABLE IF NOT EXISTS alert_stat_buckets (
+			serial_number VARCHAR(255) NOT NULL,
+			alert_type VARCHAR(100) NOT NULL,
+			severity VARCHAR(32) NOT NULL,
+			bucket TIMESTAMPTZ NOT NULL,
+			samples INTEGER NOT NULL,
+			total BIGINT NOT NULL,
+			PRIMARY KEY (serial_number, alert_type, bucket, severity)
+		);`,
+
+		// Доставка уведомлений (notifier): одна строка на событие алерта и канал
+		`CREATE TABLE IF NOT EXISTS notification_deliveries (
+			id BIGSERIAL PRIMARY KEY,
+			event_id VARCHAR(512) NOT NULL,
+			channel VARCHAR(100) NOT NULL,
+			serial_number VARCHAR(255) NOT NULL,
+			alert_type VARCHAR(100) NOT NULL,
+			status VARCHAR(16) NOT NULL DEFAULT 'pending',
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ DEFAULT NOW(),
+			updated_at TIMESTAMPTZ DEFAULT NOW(),
+			UNIQUE (event_id, channel)
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_notification_deliveries_status ON notification_deliveries(status, updated_at DESC);`,
+
+		// Тишины: уведомления об алертах, совпавших со всеми матчерами, не отправляются с starts_at до ends_at
+		`CREATE TABLE IF NOT EXISTS alert_silences (
+			id BIGSERIAL PRIMARY KEY,
+			matchers TEXT[] NOT NULL,
+			starts_at TIMESTAMPTZ NOT NULL,
+			ends_at TIMESTAMPTZ NOT NULL,
+			comment TEXT NOT NULL DEFAULT '',
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_alert_silences_ends ON alert_silences(ends_at DESC);`,
+
+		// Окна обслуживания групп устройств: повторяются по расписанию (дни недели, начало, длительность)
+		`CREATE TABLE IF NOT EXISTS maintenance_windows (
+			id BIGSERIAL PRIMARY KEY,
+			name VARCHAR(100) NOT NULL,
+			group_name VARCHAR(100) NOT NULL,
+			days TEXT[] NOT NULL DEFAULT '{}',
+			start_time VARCHAR(5) NOT NULL,
+			duration_seconds INTEGER NOT NULL,
+			timezone VARCHAR(64) NOT NULL DEFAULT 'UTC',
+			matchers TEXT[] NOT NULL DEFAULT '{}',
+			comment TEXT NOT NULL DEFAULT '',
+			created_by VARCHAR(255) NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ DEFAULT NOW()
+		);`,
 	}
 
 	for _, query := range queries {
@@ -103,14 +249,23 @@ func (p *PostgresDB) SaveMetric(ctx context.Context, serialNumber, metricType st
 	return err
 }
 
-// GetMetrics получает метрики за период
-func (p *PostgresDB) GetMetrics(ctx context.Context, serialNumber, metricType string, from, to time.Time) ([]MetricValue, error) {
-	query := `SELECT value, EXTRACT(EPOCH FROM timestamp)::BIGINT as time 
-			  FROM metrics 
-			  WHERE serial_number = $1 AND metric_type = $2 AND timestamp >= $3 AND timestamp <= $4 
-			  ORDER BY timestamp ASC`
+// MetricCursor — позиция keyset-пагинации: время и id последней отданной точки
+type MetricCursor struct {
+	Time time.Time
+	ID   int64
+}
+
+// GetMetricsDownsampled получает средние значения метрики по интервалам bucket за период
+func (p *PostgresDB) GetMetricsDownsampled(ctx context.Context, serialNumber, metricType string, from, to time.Time, bucket time.Duration) ([]MetricValue, error) {
+	// Выравнивание по эпохе без зависимости от TimescaleDB (time_bucket)
+	query := `SELECT ROUND(AVG(value))::INTEGER as value,
+			         (FLOOR(EXTRACT(EPOCH FROM timestamp) / $5) * $5)::BIGINT as time
+			  FROM metrics
+			  WHERE serial_number = $1 AND metric_type = $2 AND timestamp >= $3 AND timestamp <= $4
+			  GROUP BY 2
+			  ORDER BY 2 ASC`
 
-	rows, err := p.db.QueryContext(ctx, query, serialNumber, metricType, from, to)
+	rows, err := p.db.QueryContext(ctx, query, serialNumber, metricType, from, to, int64(bucket.Seconds()))
 	if err != nil {
 		return nil, err
 	}
@@ -122,31 +277,245 @@ func (p *PostgresDB) GetMetrics(ctx context.Context, serialNumber, metricType st
 		if err := rows.Scan(&m.Value, &m.Time); err != nil {
 			return nil, err
 		}
-		metrics = append(metrics, m) // накапливаем результаты
+		metrics = append(metrics, m)
+	}
+	return metrics, rows.Err()
+}
+
+// GetMetricPoints получает точки серии за полуинтервал [from, to) по порядку (timestamp, id) — для чанков кэша
+func (p *PostgresDB) GetMetricPoints(ctx context.Context, serialNumber, metricType string, from, to time.Time) ([]MetricPoint, error) {
+	query := `SELECT id, value, timestamp
+			  FROM metrics
+			  WHERE serial_number = $1 AND metric_type = $2 AND timestamp >= $3 AND timestamp < $4
+			  ORDER BY timestamp ASC, id ASC`
+
+	rows, err := p.db.QueryContext(ctx, query, serialNumber, metricType, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var points []MetricPoint
+	for rows.Next() {
+		var pt MetricPoint
+		var ts time.Time
+		if err := rows.Scan(&pt.ID, &pt.Value, &ts); err != nil {
+			return nil, err
+		}
+		pt.TimeNano = ts.UnixNano()
+		points = append(points, pt)
+	}
+	return points, rows.Err()
+}
+
+// exportFetchSize — сколько строк за раз читается из серверного курсора при выгрузке
+const exportFetchSize = 10000
+
+// MetricRow — строка выгрузки метрик (устройство, тип, значение, время)
+type MetricRow struct {
+	SerialNumber string
+	MetricType   string
+	Value        int
+	Time         time.Time
+}
+
+// StreamMetrics выгружает метрики нескольких устройств и типов через серверный курсор
+// и вызывает fn для каждой строки. Результат не накапливается в памяти — подходит для больших выгрузок
+func (p *PostgresDB) StreamMetrics(ctx context.Context, serialNumbers, metricTypes []string, from, to time.Time, fn func(MetricRow) error) error {
+	// Курсор живёт только внутри транзакции
+	tx, err := p.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // только чтение — откат безопасен
+
+	declare := `DECLARE metrics_export NO SCROLL CURSOR FOR
+			  SELECT serial_number, metric_type, value, timestamp
+			  FROM metrics
+			  WHERE serial_number = ANY($1) AND metric_type = ANY($2) AND timestamp >= $3 AND timestamp <= $4
+			  ORDER BY serial_number, metric_type, timestamp`
+	if _, err := tx.ExecContext(ctx, declare, pq.Array(serialNumbers), pq.Array(metricTypes), from, to); err != nil {
+		return fmt.Errorf("declare cursor: %w", err)
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM metrics_export", exportFetchSize)
+	for {
+		n, err := fetchMetricRows(ctx, tx, fetch, fn)
+		if err != nil {
+			return err
+		}
+		if n < exportFetchSize {
+			return nil // курсор исчерпан
+		}
+	}
+}
+
+// fetchMetricRows читает одну порцию курсора и возвращает число прочитанных строк
+func fetchMetricRows(ctx context.Context, tx *sql.Tx, fetch string, fn func(MetricRow) error) (int, error) {
+	rows, err := tx.QueryContext(ctx, fetch)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		var r MetricRow
+		if err := rows.Scan(&r.SerialNumber, &r.MetricType, &r.Value, &r.Time); err != nil {
+			return n, err
+		}
+		if err := fn(r); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// LabelMatcher — условие на значение метки (как в PromQL): "=", "!=", "=~", "!~".
+// Регулярные выражения проверяются целиком (якоря ^...$ добавляются автоматически)
+type LabelMatcher struct {
+	Op    string
+	Value string
+}
+
+// SeriesKey — идентификатор временного ряда: устройство + тип метрики
+type SeriesKey struct {
+	SerialNumber string `json:"serial_number"`
+	MetricType   string `json:"metric_type"`
+}
+
+// serialMatcherSQL — SQL операторы для LabelMatcher (значения подставляются параметрами)
+var serialMatcherSQL = map[string]string{
+	"=":  "serial_number = $%d",
+	"!=": "serial_number <> $%d",
+	"=~": "serial_number ~ ('^(?:' || $%d || ')$')",
+	"!~": "serial_number !~ ('^(?:' || $%d || ')$')",
+}
+
+// seriesFilter строит условие WHERE по типам метрик, периоду и условиям на serial_number
+func seriesFilter(metricTypes []string, serialMatchers []LabelMatcher, from, to time.Time) (string, []interface{}, error) {
+	where := "metric_type = ANY($1) AND timestamp >= $2 AND timestamp <= $3"
+	args := []interface{}{pq.Array(metricTypes), from, to}
+	for _, m := range serialMatchers {
+		tmpl, ok := serialMatcherSQL[m.Op]
+		if !ok {
+			return "", nil, fmt.Errorf("unsupported matcher operator %q", m.Op)
+		}
+		args = append(args, m.Value)
+		where += " AND " + fmt.Sprintf(tmpl, len(args))
+	}
+	return where, args, nil
+}
+
+// FindSeries возвращает ряды (устройство, тип метрики), у которых есть точки за период
+// и serial_number удовлетворяет всем serialMatchers. Не больше limit рядов
+func (p *PostgresDB) FindSeries(ctx context.Context, metricTypes []string, serialMatchers []LabelMatcher, from, to time.Time, limit int) ([]SeriesKey, error) {
+	where, args, err := seriesFilter(metricTypes, serialMatchers, from, to)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, limit)
+	query := fmt.Sprintf(`SELECT DISTINCT serial_number, metric_type FROM metrics WHERE %s
+			  ORDER BY serial_number, metric_type LIMIT $%d`, where, len(args))
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var series []SeriesKey
+	for rows.Next() {
+		var k SeriesKey
+		if err := rows.Scan(&k.SerialNumber, &k.MetricType); err != nil {
+			return nil, err
+		}
+		series = append(series, k)
+	}
+	return series, rows.Err()
+}
+
+// FindSerialNumbers возвращает серийные номера устройств с точками за период (не больше limit)
+func (p *PostgresDB) FindSerialNumbers(ctx context.Context, metricTypes []string, serialMatchers []LabelMatcher, from, to time.Time, limit int) ([]string, error) {
+	where, args, err := seriesFilter(metricTypes, serialMatchers, from, to)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, limit)
+	query := fmt.Sprintf(`SELECT DISTINCT serial_number FROM metrics WHERE %s
+			  ORDER BY serial_number LIMIT $%d`, where, len(args))
+
+	rows, err := p.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	return metrics, rows.Err() // проверяем ошибку итерации
+	var serials []string
+	for rows.Next() {
+		var sn string
+		if err := rows.Scan(&sn); err != nil {
+			return nil, err
+		}
+		serials = append(serials, sn)
+	}
+	return serials, rows.Err()
 }
 
-// SaveAlert сохраняет алерт в DB
-func (p *PostgresDB) SaveAlert(ctx context.Context, serialNumber, alertType string, value int, timestamp time.Time) error {
-	query := `INSERT INTO alerts (serial_number, alert_type, value, timestamp) VALUES ($1, $2, $3, $4)`
-	_, err := p.db.ExecContext(ctx, query, serialNumber, alertType, value, timestamp)
+// SaveAlert сохраняет алерт в DB. metric — метрика, значение которой сработало (для WiFi — диапазон),
+// suppressedBy — что подавило уведомление (silence:<id>, maintenance:<id>; пусто — не подавлено)
+func (p *PostgresDB) SaveAlert(ctx context.Context, serialNumber, alertType, severity, metric string, value int, timestamp time.Time, suppressedBy string) error {
+	query := `INSERT INTO alerts (serial_number, alert_type, severity, metric, value, timestamp, suppressed, suppressed_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	_, err := p.db.ExecContext(ctx, query, serialNumber, alertType, severity, metric, value, timestamp, suppressedBy != "", suppressedBy)
 	return err
 }
 
-// GetAlertStats получает статистику по алертам
-func (p *PostgresDB) GetAlertStats(ctx context.Context, serialNumber, alertType string, from, to time.Time) (*AlertStats, error) {
-	query := `SELECT AVG(value)::INTEGER as avg_value, COUNT(*) as count 
-			  FROM alerts 
-			  WHERE serial_number = $1 AND alert_type = $2 AND timestamp >= $3 AND timestamp <= $4`
+// GetAlertStats получает статистику по алертам: среднее значение и количество, в том числе по уровням важности.
+// severities — учитывать только эти уровни (пусто — все). Нарушающие сэмплы инцидентов считаются по минутам
+// (alert_stat_buckets, минута from учитывается целиком), события и строки alerts до появления минутной
+// статистики — по времени алерта
+func (p *PostgresDB) GetAlertStats(ctx context.Context, serialNumber, alertType string, from, to time.Time, severities []string) (*AlertStats, error) {
+	query := `SELECT severity, SUM(n), SUM(total)
+			  FROM (
+				  SELECT severity, COUNT(*) AS n, SUM(value) AS total
+				  FROM alerts
+				  WHERE serial_number = $1 AND alert_type = $2 AND timestamp >= $3 AND timestamp <= $4
+				  GROUP BY severity
+				  UNION ALL
+				  SELECT severity, SUM(samples), SUM(total)
+				  FROM alert_stat_buckets
+				  WHERE serial_number = $1 AND alert_type = $2 AND bucket >= date_trunc('minute', $3::TIMESTAMPTZ)
+				    AND bucket <= $4
+				  GROUP BY severity
+			  ) t
+			  WHERE cardinality($5::TEXT[]) = 0 OR severity = ANY($5)
+			  GROUP BY severity`
+
+	rows, err := p.db.QueryContext(ctx, query, serialNumber, alertType, from, to, pq.Array(severities))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
 
-	var stats AlertStats
-	err := p.db.QueryRowContext(ctx, query, serialNumber, alertType, from, to).Scan(&stats.Value, &stats.Count)
-	if err == sql.ErrNoRows { // записей нет — возвращаем нули
-		return &AlertStats{Value: 0, Count: 0}, nil
+	stats := AlertStats{Severities: map[string]int{}}
+	var sum int64
+	for rows.Next() {
+		var severity string
+		var count int
+		var total int64
+		if err := rows.Scan(&severity, &count, &total); err != nil {
+			return nil, err
+		}
+		stats.Severities[severity] = count
+		stats.Count += count
+		sum += total
+	}
+	if stats.Count > 0 { // записей нет — нули
+		stats.Value = int(math.Round(float64(sum) / float64(stats.Count)))
 	}
-	return &stats, err
+	return &stats, rows.Err()
 }
 
 // MetricValue — значение метрики с временной меткой (используется в pkg/database)
@@ -155,8 +524,16 @@ type MetricValue struct {
 	Time  int64 `json:"time"`
 }
 
+// MetricPage — страница метрик: точки, токен следующей страницы и интервал усреднения
+type MetricPage struct {
+	Metrics       []MetricValue `json:"metrics"`
+	NextPageToken string        `json:"next_page_token,omitempty"` // пусто — последняя страница
+	BucketSeconds int64         `json:"bucket_seconds,omitempty"`  // > 0 — точки усреднены (downsample)
+}
+
 // AlertStats — агрегированная статистика алертов (среднее значение и количество)
 type AlertStats struct {
-	Value int `json:"value"`
-	Count int `json:"count"`
+	Value      int            `json:"value"`
+	Count      int            `json:"count"`
+	Severities map[string]int `json:"severities"` // количество по уровням важности
 }
@@ -0,0 +1,69 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// takeTokenScript — token bucket: пополняет корзину по прошедшему времени и забирает один токен.
+// Время берётся из Redis (TIME), чтобы часы реплик gateway не влияли на результат.
+// KEYS[1] — ключ корзины, ARGV[1] — токенов в секунду, ARGV[2] — ёмкость корзины.
+// Возвращает {1|0, остаток токенов, через сколько мс появится токен}
+var takeTokenScript = redis.NewScript(`
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+
+local b = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(b[1])
+local ts = tonumber(b[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, math.floor(tokens), wait}
+`)
+
+// RateLimitResult — результат попытки взять токен
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // сколько запросов ещё можно сделать сразу
+	RetryAfter time.Duration // когда появится следующий токен (если запрос отклонён)
+}
+
+// TakeToken атомарно забирает токен из корзины key (rate токенов в секунду, не больше burst).
+// Корзина общая для всех реплик, использующих этот Redis
+func (r *RedisCache) TakeToken(ctx context.Context, key string, rate float64, burst int) (*RateLimitResult, error) {
+	res, err := takeTokenScript.Run(ctx, r.client, []string{key},
+		strconv.FormatFloat(rate, 'f', -1, 64), burst).Int64Slice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) != 3 {
+		return nil, fmt.Errorf("rate limit: unexpected script result %v", res)
+	}
+	return &RateLimitResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,106 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// Перезагрузки устройств: alert-processor сравнивает uptime каждого сэмпла с предыдущим (Redis, общий для
+// экземпляров), перезагрузки сохраняются алертами device-rebooted в PostgreSQL — оттуда и история.
+// Зацикленность — перезагрузка внутри инцидента reboot-loop (до инцидентов — строка reboot-loop в alerts).
+
+// uptimeStateTTL — сколько хранится последний uptime устройства без новых сэмплов
+const uptimeStateTTL = 7 * 24 * time.Hour
+
+// UptimeObservation — результат ObserveUptime
+type UptimeObservation struct {
+	Rebooted     bool  // uptime уменьшился — устройство перезагрузилось
+	UptimeBefore int64 // uptime (сек) в последнем сэмпле до перезагрузки
+	Recent       int   // перезагрузок за окно, включая эту
+}
+
+// Reboot — перезагрузка устройства в истории
+type Reboot struct {
+	Time         time.Time `json:"time"`                  // сэмпл, в котором обнаружена перезагрузка
+	UptimeBefore int64     `json:"uptime_before_seconds"` // сколько устройство проработало до неё
+	Loop         bool      `json:"loop"`                  // перезагрузка дала алерт reboot-loop
+}
+
+// observeUptimeScript сравнивает uptime сэмпла с предыдущим.
+// KEYS[1] — хэш состояния (ts, uptime; reboot, prev, count — последняя перезагрузка), KEYS[2] — sorted set
+// перезагрузок за окно. ARGV[1] — время сэмпла (мс), ARGV[2] — uptime, ARGV[3] — окно (мс), ARGV[4] — TTL (мс).
+// Запоздавший сэмпл не учитывается; тот же сэмпл повторно (повторная доставка) получает тот же ответ.
+// Возвращает {1 — перезагрузка, uptime до неё, перезагрузок за окно}
+var observeUptimeScript = redis.NewScript(`
+local ts = tonumber(ARGV[1])
+local up = tonumber(ARGV[2])
+local s = redis.call('HMGET', KEYS[1], 'ts', 'uptime', 'reboot', 'prev', 'count')
+local last = tonumber(s[1])
+if last and ts <= last then
+	if ts == last and s[3] == ARGV[1] then
+		return {1, tonumber(s[4]), tonumber(s[5])}
+	end
+	return {0, 0, 0}
+end
+
+local rebooted, prev, count = 0, tonumber(s[2]) or 0, 0
+if last and up < prev then
+	rebooted = 1
+	redis.call('ZADD', KEYS[2], ARGV[1], ARGV[1])
+	redis.call('ZREMRANGEBYSCORE', KEYS[2], '-inf', ts - tonumber(ARGV[3]))
+	count = redis.call('ZCARD', KEYS[2])
+	redis.call('PEXPIRE', KEYS[2], ARGV[3])
+	redis.call('HSET', KEYS[1], 'reboot', ARGV[1], 'prev', tostring(prev), 'count', count)
+end
+redis.call('HSET', KEYS[1], 'ts', ARGV[1], 'uptime', ARGV[2])
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {rebooted, prev, count}
+`)
+
+// ObserveUptime записывает uptime (сек) сэмпла устройства и сообщает, перезагрузилось ли оно с прошлого сэмпла
+// и сколько перезагрузок было за последние window
+func (r *RedisCache) ObserveUptime(ctx context.Context, serialNumber string, ts time.Time, uptime int64, window time.Duration) (UptimeObservation, error) {
+	keys := []string{"alert:uptime:" + serialNumber, "alert:reboots:" + serialNumber}
+	res, err := observeUptimeScript.Run(ctx, r.client, keys, ts.UnixMilli(), uptime, window.Milliseconds(), uptimeStateTTL.Milliseconds()).Int64Slice()
+	if err != nil {
+		return UptimeObservation{}, err
+	}
+	return UptimeObservation{Rebooted: res[0] == 1, UptimeBefore: res[1], Recent: int(res[2])}, nil
+}
+
+// GetReboots возвращает перезагрузки устройства за период [from, to], от новых к старым
+func (p *PostgresDB) GetReboots(ctx context.Context, serialNumber string, from, to time.Time, limit int) ([]Reboot, error) {
+	query := `SELECT a.timestamp, a.value, EXISTS (
+				  SELECT 1 FROM alert_incidents l
+				  WHERE l.serial_number = a.serial_number AND l.alert_type = $5
+				    AND a.timestamp BETWEEN l.started_at AND l.last_seen_at
+			  ) OR EXISTS (
+				  SELECT 1 FROM alerts l
+				  WHERE l.serial_number = a.serial_number AND l.alert_type = $5 AND l.timestamp = a.timestamp
+			  )
+			  FROM alerts a
+			  WHERE a.serial_number = $1 AND a.alert_type = $4 AND a.timestamp >= $2 AND a.timestamp <= $3
+			  ORDER BY a.timestamp DESC
+			  LIMIT $6`
+	rows, err := p.db.QueryContext(ctx, query, serialNumber, from, to,
+		string(tr181.AlertDeviceRebooted), string(tr181.AlertRebootLoop), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reboots := []Reboot{}
+	for rows.Next() {
+		var r Reboot
+		if err := rows.Scan(&r.Time, &r.UptimeBefore, &r.Loop); err != nil {
+			return nil, err
+		}
+		reboots = append(reboots, r)
+	}
+	return reboots, rows.Err()
+}
@@ -0,0 +1,209 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Тишины и окна обслуживания создаются через API (api-gateway проверяет матчеры и расписание),
+// alert-processor перечитывает их по сообщению от api-gateway (и периодически, если сообщение потеряно)
+// и не отправляет уведомления о совпавших алертах.
+
+// policyChangedChannel — канал Redis: изменились тишины, окна обслуживания или группы устройств
+const policyChangedChannel = "alert:policy:changed"
+
+// Состояния тишины в выборке
+const (
+	SilenceActive  = "active"  // действует
+	SilencePending = "pending" // ещё не началась
+	SilenceExpired = "expired" // закончилась (или снята)
+)
+
+// Silence — тишина: уведомления об алертах, совпавших со всеми матчерами, не отправляются
+type Silence struct {
+	ID        int64     `json:"id"`
+	Matchers  []string  `json:"matchers"` // alert_type=high-cpu-usage, group=~"rollout-.*"
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Comment   string    `json:"comment"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// MaintenanceWindow — повторяющееся окно обслуживания группы устройств
+type MaintenanceWindow struct {
+	ID              int64     `json:"id"`
+	Name            string    `json:"name"`
+	Group           string    `json:"group"`
+	Days            []string  `json:"days"`       // mon..sun, пусто — каждый день
+	StartTime       string    `json:"start_time"` // ЧЧ:ММ по timezone
+	DurationSeconds int       `json:"duration_seconds"`
+	Timezone        string    `json:"timezone"`
+	Matchers        []string  `json:"matchers"` // дополнительно (пусто — все алерты устройств группы)
+	Comment         string    `json:"comment"`
+	CreatedBy       string    `json:"created_by"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// CreateSilence сохраняет тишину и заполняет ID и CreatedAt
+func (p *PostgresDB) CreateSilence(ctx context.Context, s *Silence) error {
+	query := `INSERT INTO alert_silences (matchers, starts_at, ends_at, comment, created_by)
+			  VALUES ($1, $2, $3, $4, $5)
+			  RETURNING id, created_at`
+	return p.db.QueryRowContext(ctx, query, pq.Array(s.Matchers), s.StartsAt, s.EndsAt, s.Comment, s.CreatedBy).
+		Scan(&s.ID, &s.CreatedAt)
+}
+
+// GetSilence возвращает тишину по id (nil — нет такой)
+func (p *PostgresDB) GetSilence(ctx context.Context, id int64) (*Silence, error) {
+	query := `SELECT id, matchers, starts_at, ends_at, comment, created_by, created_at
+			  FROM alert_silences WHERE id = $1`
+	var s Silence
+	err := p.db.QueryRowContext(ctx, query, id).
+		Scan(&s.ID, pq.Array(&s.Matchers), &s.StartsAt, &s.EndsAt, &s.Comment, &s.CreatedBy, &s.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSilences возвращает тишины в состоянии state на момент now (пусто — все), от новых к старым
+func (p *PostgresDB) GetSilences(ctx context.Context, state string, now time.Time, limit int) ([]Silence, error) {
+	query := `SELECT id, matchers, starts_at, ends_at, comment, created_by, created_at
+			  FROM alert_silences
+			  WHERE $1 = ''
+			     OR ($1 = 'active' AND starts_at <= $2 AND ends_at > $2)
+			     OR ($1 = 'pending' AND starts_at > $2)
+			     OR ($1 = 'expired' AND ends_at <= $2)
+			  ORDER BY created_at DESC, id DESC
+			  LIMIT $3`
+	rows, err := p.db.QueryContext(ctx, query, state, now, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	silences := []Silence{}
+	for rows.Next() {
+		var s Silence
+		if err := rows.Scan(&s.ID, pq.Array(&s.Matchers), &s.StartsAt, &s.EndsAt, &s.Comment, &s.CreatedBy, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		silences = append(silences, s)
+	}
+	return silences, rows.Err()
+}
+
+// ExpireSilence снимает тишину: конец (и начало ещё не начавшейся) переносится на now.
+// false — тишины нет или она уже закончилась
+func (p *PostgresDB) ExpireSilence(ctx context.Context, id int64, now time.Time) (bool, error) {
+	query := `UPDATE alert_silences SET ends_at = $2, starts_at = LEAST(starts_at, $2) WHERE id = $1 AND ends_at > $2`
+	res, err := p.db.ExecContext(ctx, query, id, now)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CreateMaintenanceWindow сохраняет окно обслуживания и заполняет ID и CreatedAt
+func (p *PostgresDB) CreateMaintenanceWindow(ctx context.Context, w *MaintenanceWindow) error {
+	query := `INSERT INTO maintenance_windows (name, group_name, days, start_time, duration_seconds, timezone, matchers, comment, created_by)
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			  RETURNING id, created_at`
+	return p.db.QueryRowContext(ctx, query, w.Name, w.Group, pq.Array(w.Days), w.StartTime, w.DurationSeconds,
+		w.Timezone, pq.Array(w.Matchers), w.Comment, w.CreatedBy).Scan(&w.ID, &w.CreatedAt)
+}
+
+// GetMaintenanceWindows возвращает окна обслуживания (group — только этой группы, пусто — все)
+func (p *PostgresDB) GetMaintenanceWindows(ctx context.Context, group string) ([]MaintenanceWindow, error) {
+	query := `SELECT id, name, group_name, days, start_time, duration_seconds, timezone, matchers, comment, created_by, created_at
+			  FROM maintenance_windows
+			  WHERE $1 = '' OR group_name = $1
+			  ORDER BY id`
+	rows, err := p.db.QueryContext(ctx, query, group)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []MaintenanceWindow{}
+	for rows.Next() {
+		var w MaintenanceWindow
+		if err := rows.Scan(&w.ID, &w.Name, &w.Group, pq.Array(&w.Days), &w.StartTime, &w.DurationSeconds,
+			&w.Timezone, pq.Array(&w.Matchers), &w.Comment, &w.CreatedBy, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		windows = append(windows, w)
+	}
+	return windows, rows.Err()
+}
+
+// DeleteMaintenanceWindow удаляет окно обслуживания. false — окна нет
+func (p *PostgresDB) DeleteMaintenanceWindow(ctx context.Context, id int64) (bool, error) {
+	res, err := p.db.ExecContext(ctx, `DELETE FROM maintenance_windows WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetAllDeviceGroups возвращает группы всех устройств, входящих хотя бы в одну группу
+// (alert-processor держит их в памяти для матчеров group и окон обслуживания)
+func (p *PostgresDB) GetAllDeviceGroups(ctx context.Context) (map[string][]string, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT serial_number, group_name FROM device_group_members`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := make(map[string][]string)
+	for rows.Next() {
+		var sn, g string
+		if err := rows.Scan(&sn, &g); err != nil {
+			return nil, err
+		}
+		groups[sn] = append(groups[sn], g)
+	}
+	return groups, rows.Err()
+}
+
+// NotifyPolicyChanged сообщает alert-processor, что тишины, окна обслуживания или группы устройств изменились
+func (r *RedisCache) NotifyPolicyChanged(ctx context.Context) error {
+	return r.client.Publish(ctx, policyChangedChannel, "").Err()
+}
+
+// PolicyChanges — сообщения об изменении политики уведомлений (несколько подряд объединяются в одно).
+// Подписка переподключается сама (go-redis) и закрывается вместе с кэшем
+func (r *RedisCache) PolicyChanges() <-chan struct{} {
+	changed := make(chan struct{}, 1)
+	ps := r.client.Subscribe(context.Background(), policyChangedChannel)
+	go func() {
+		defer ps.Close()
+		ch := ps.Channel()
+		for {
+			select {
+			case <-r.stop:
+				return
+			case _, ok := <-ch:
+				if !ok {
+					return
+				}
+				select {
+				case changed <- struct{}{}:
+				default: // перечитывание уже запрошено
+				}
+			}
+		}
+	}()
+	return changed
+}
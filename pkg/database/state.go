@@ -0,0 +1,126 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// deviceStateTTL — сколько хранится последнее состояние устройства без обновлений
+const deviceStateTTL = 7 * 24 * time.Hour
+
+// deviceStateTimestampField — поле хэша с временем снимка (Unix миллисекунды)
+const deviceStateTimestampField = "timestamp"
+
+// saveDeviceStateScript атомарно заменяет снимок, если он не старше сохранённого.
+// KEYS[1] — ключ хэша, ARGV[1] — timestamp (мс), ARGV[2] — TTL (сек), далее пары поле/значение
+var saveDeviceStateScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], 'timestamp')
+if cur and tonumber(cur) > tonumber(ARGV[1]) then
+	return 0
+end
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[1], 'timestamp', ARGV[1], unpack(ARGV, 3))
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// DeviceState — последний известный снимок параметров устройства
+type DeviceState struct {
+	SerialNumber string           `json:"serial_number"`
+	Timestamp    time.Time        `json:"timestamp"`
+	Data         tr181.DeviceData `json:"data"`
+}
+
+// deviceStateKey — ключ хэша с последним состоянием устройства
+func deviceStateKey(serialNumber string) string {
+	return "device:state:" + serialNumber
+}
+
+// SaveDeviceState обновляет последнее состояние устройства (хэш: параметр TR181 → значение).
+// Запоздавшие снимки (старше сохранённого) игнорируются
+func (r *RedisCache) SaveDeviceState(ctx context.Context, device *tr181.TR181Device) error {
+	raw, err := json.Marshal(device.Data)
+	if err != nil {
+		return err
+	}
+	// Разбираем в map с json.Number, чтобы не терять точность int64
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var params map[string]json.Number
+	if err := dec.Decode(&params); err != nil {
+		return err
+	}
+
+	args := make([]interface{}, 0, 2+len(params)*2)
+	args = append(args, device.Timestamp.UnixMilli(), int(deviceStateTTL.Seconds()))
+	for name, value := range params {
+		args = append(args, name, value.String())
+	}
+	return saveDeviceStateScript.Run(ctx, r.client, []string{deviceStateKey(device.SerialNumber)}, args...).Err()
+}
+
+// GetDeviceStates возвращает последние состояния устройств одним pipeline.
+// Устройства без сохранённого состояния в результат не попадают
+func (r *RedisCache) GetDeviceStates(ctx context.Context, serialNumbers []string) ([]DeviceState, error) {
+	pipe := r.client.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(serialNumbers))
+	for i, sn := range serialNumbers {
+		cmds[i] = pipe.HGetAll(ctx, deviceStateKey(sn))
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	states := make([]DeviceState, 0, len(serialNumbers))
+	for i, cmd := range cmds {
+		fields, err := cmd.Result()
+		if err != nil {
+			return nil, err
+		}
+		if len(fields) == 0 {
+			continue // состояние неизвестно
+		}
+		state, err := decodeDeviceState(serialNumbers[i], fields)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, *state)
+	}
+	return states, nil
+}
+
+// decodeDeviceState собирает DeviceState из полей хэша
+func decodeDeviceState(serialNumber string, fields map[string]string) (*DeviceState, error) {
+	ms, err := strconv.ParseInt(fields[deviceStateTimestampField], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("device state %s: invalid timestamp: %w", serialNumber, err)
+	}
+
+	// Значения в хэше — числа в виде строк, их можно вставить в JSON как есть
+	params := make(map[string]json.RawMessage, len(fields))
+	for name, value := range fields {
+		if name == deviceStateTimestampField {
+			continue
+		}
+		params[name] = json.RawMessage(value)
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, err
+	}
+
+	state := &DeviceState{SerialNumber: serialNumber, Timestamp: time.UnixMilli(ms).UTC()}
+	if err := json.Unmarshal(raw, &state.Data); err != nil {
+		return nil, fmt.Errorf("device state %s: %w", serialNumber, err)
+	}
+	return state, nil
+}
@@ -0,0 +1,79 @@
+// Package database — см. package doc в redis.go
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// MetricSummary — сводная статистика метрики за период или интервал
+type MetricSummary struct {
+	Time   int64   `json:"time,omitempty"` // начало интервала (Unix), 0 — весь период
+	Count  int64   `json:"count"`          // число точек; 0 — остальные поля не заданы
+	Min    int     `json:"min"`
+	Max    int     `json:"max"`
+	Mean   float64 `json:"mean"`
+	Median float64 `json:"median"`
+	P95    float64 `json:"p95"`
+	StdDev float64 `json:"stddev"` // выборочное стандартное отклонение (0 для одной точки)
+}
+
+// summarySelect — агрегаты сводной статистики (порядок — как в scanSummary)
+const summarySelect = `COUNT(*),
+			         COALESCE(MIN(value), 0),
+			         COALESCE(MAX(value), 0),
+			         COALESCE(AVG(value), 0)::DOUBLE PRECISION,
+			         COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY value), 0),
+			         COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY value), 0),
+			         COALESCE(STDDEV_SAMP(value), 0)::DOUBLE PRECISION`
+
+// GetMetricSummary считает сводную статистику метрики устройства за период [from, to]
+func (p *PostgresDB) GetMetricSummary(ctx context.Context, serialNumber, metricType string, from, to time.Time) (*MetricSummary, error) {
+	query := `SELECT ` + summarySelect + `
+			  FROM metrics
+			  WHERE serial_number = $1 AND metric_type = $2 AND timestamp >= $3 AND timestamp <= $4`
+
+	var s MetricSummary
+	row := p.db.QueryRowContext(ctx, query, serialNumber, metricType, from, to)
+	if err := scanSummary(row, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetMetricSummaryBuckets считает сводную статистику по интервалам bucket, выровненным по эпохе
+// (как GetMetricsDownsampled). Интервалы без точек не возвращаются
+func (p *PostgresDB) GetMetricSummaryBuckets(ctx context.Context, serialNumber, metricType string, from, to time.Time, bucket time.Duration) ([]MetricSummary, error) {
+	query := `SELECT (FLOOR(EXTRACT(EPOCH FROM timestamp) / $5) * $5)::BIGINT as time, ` + summarySelect + `
+			  FROM metrics
+			  WHERE serial_number = $1 AND metric_type = $2 AND timestamp >= $3 AND timestamp <= $4
+			  GROUP BY 1
+			  ORDER BY 1 ASC`
+
+	rows, err := p.db.QueryContext(ctx, query, serialNumber, metricType, from, to, int64(bucket.Seconds()))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	buckets := []MetricSummary{}
+	for rows.Next() {
+		var s MetricSummary
+		if err := scanSummary(rows, &s, &s.Time); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, s)
+	}
+	return buckets, rows.Err()
+}
+
+// scanSummary читает строку с summarySelect; prefix — столбцы перед агрегатами
+func scanSummary(row interface{ Scan(...interface{}) error }, s *MetricSummary, prefix ...interface{}) error {
+	dest := append(prefix, &s.Count, &s.Min, &s.Max, &s.Mean, &s.Median, &s.P95, &s.StdDev)
+	err := row.Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
@@ -11,7 +11,7 @@ import (
 const (
 	// TopicTR181Data — топик для данных устройств TR181 (метрики, телеметрия)
 	TopicTR181Data = "persistent://public/default/tr181-device-data"
-	// TopicAlerts — топик для алертов (устаревший, алерты пишутся в БД)
+	// TopicAlerts — изменения алертов для уведомлений (alert-processor → notifier); сами алерты — в БД
 	TopicAlerts = "persistent://public/default/alerts"
 	// TopicLogs — топик для логов (опциональный log-viewer подписывается)
 	TopicLogs = "persistent://public/default/tr181-logs"
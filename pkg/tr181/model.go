@@ -61,10 +61,68 @@ type AlertType string
 
 // Константы типов алертов
 const (
-	AlertHighCPUUsage AlertType = "high-cpu-usage"
-	AlertLowWiFi      AlertType = "low-wifi"
+	AlertHighCPUUsage         AlertType = "high-cpu-usage"
+	AlertHighMemoryUsage      AlertType = "high-memory-usage"
+	AlertHighCPUTemperature   AlertType = "high-cpu-temperature"
+	AlertHighBoardTemperature AlertType = "high-board-temperature"
+	AlertHighRadioTemperature AlertType = "high-radio-temperature"
+	AlertLowWiFi              AlertType = "low-wifi" // любой диапазон (сработавший — в метрике алерта)
+	AlertLowWiFi2GHz          AlertType = "low-wifi-2ghz"
+	AlertLowWiFi5GHz          AlertType = "low-wifi-5ghz"
+	AlertLowWiFi6GHz          AlertType = "low-wifi-6ghz"
+	AlertAnomaly              AlertType = "anomaly"         // отклонение от модели устройства (любая метрика)
+	AlertDeviceOffline        AlertType = "device-offline"  // нет сообщений дольше допустимого
+	AlertDeviceOnline         AlertType = "device-online"   // сообщения снова приходят (событие, без инцидента)
+	AlertDeviceRebooted       AlertType = "device-rebooted" // uptime уменьшился (событие, без инцидента)
+	AlertRebootLoop           AlertType = "reboot-loop"     // частые перезагрузки
 )
 
+// AlertSeverity представляет уровень важности алерта
+type AlertSeverity string
+
+// Уровни важности алертов (совпадают с уровнями log-viewer)
+const (
+	SeverityInfo    AlertSeverity = "info"    // событие (например восстановление связи)
+	SeverityWarning AlertSeverity = "warning" // превышение нормы
+	SeverityError   AlertSeverity = "error"   // критично
+)
+
+// AlertSeverities — все уровни по возрастанию важности
+var AlertSeverities = []AlertSeverity{SeverityInfo, SeverityWarning, SeverityError}
+
+// Rank возвращает порядок уровня (чем выше, тем важнее); 0 — неизвестный уровень
+func (s AlertSeverity) Rank() int {
+	for i, v := range AlertSeverities {
+		if s == v {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// AlertStatus — состояние алерта в событии для уведомлений
+type AlertStatus string
+
+// Состояния алерта
+const (
+	AlertFiring   AlertStatus = "firing"   // инцидент открыт или событие (device-rebooted)
+	AlertResolved AlertStatus = "resolved" // инцидент закрыт
+)
+
+// AlertEvent — изменение алерта для уведомлений (топик alerts): открытие и закрытие инцидента или событие.
+// Отдельные сэмплы открытого инцидента не публикуются
+type AlertEvent struct {
+	ID           string        `json:"id"` // одинаковый при повторной публикации (идемпотентность доставки)
+	Status       AlertStatus   `json:"status"`
+	SerialNumber string        `json:"serial_number"`
+	Type         AlertType     `json:"alert_type"`
+	Severity     AlertSeverity `json:"severity"`
+	Value        int           `json:"value"`
+	Metric       MetricType    `json:"metric,omitempty"`
+	Time         time.Time     `json:"time"`
+	Receivers    []string      `json:"receivers,omitempty"` // каналы по маршрутам alert-processor (пусто — все)
+}
+
 // MetricValue представляет значение метрики с временной меткой
 type MetricValue struct {
 	Value int   `json:"value"`
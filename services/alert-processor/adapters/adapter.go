@@ -2,13 +2,20 @@
 package adapters
 
 import (
+	"context"
+	"time"
+
 	"golang-test-dev/pkg/tr181"
 )
 
 // AlertResult — результат оценки адаптера: нужен ли алерт
 type AlertResult struct {
-	Type  tr181.AlertType
-	Value int
+	Type     tr181.AlertType
+	Value    int
+	Metric   tr181.MetricType    // метрика, давшая значение (для WiFi — диапазон)
+	Severity tr181.AlertSeverity // уровень важности (сохраняется вместе с алертом)
+	Below    bool                // нарушение — значение ниже порога (худшее значение — минимальное)
+	Event    bool                // событие (например device-rebooted): сохраняется без инцидента
 }
 
 // Adapter оценивает данные устройства и возвращает алерты при необходимости
@@ -16,3 +23,28 @@ type AlertResult struct {
 type Adapter interface {
 	Evaluate(device *tr181.TR181Device) []AlertResult
 }
+
+// StateStore — хранилище состояния адаптеров по устройствам (реализуется database.RedisCache)
+type StateStore interface {
+	ConditionStore
+	AnomalyStore
+	RebootStore
+}
+
+// StatefulAdapter — адаптер, которому нужно состояние устройства между сообщениями.
+// Обработчик вызывает EvaluateState вместо Evaluate; ошибка хранилища — сообщение обрабатывается повторно
+type StatefulAdapter interface {
+	Adapter
+	EvaluateState(ctx context.Context, device *tr181.TR181Device, store StateStore) ([]AlertResult, error)
+}
+
+// Recovery — условие алертов типа Type в сэмпле снято; инцидент закрывается, если так держится не меньше For
+type Recovery struct {
+	Type tr181.AlertType
+	For  time.Duration
+}
+
+// Recoverer — адаптер, который сообщает восстановление (для закрытия инцидентов)
+type Recoverer interface {
+	Recovered(device *tr181.TR181Device) []Recovery
+}
@@ -0,0 +1,101 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+)
+
+// AnomalyConfig — настройки адаптера аномалий (пустой Metrics — адаптер выключен)
+type AnomalyConfig struct {
+	Metrics   []tr181.MetricType // метрики, для которых строится модель
+	Sigma     float64            // аномалия — отклонение от среднего не меньше Sigma стандартных отклонений
+	Alpha     float64            // вес нового сэмпла в EWMA (0 < Alpha < 1): чем меньше, тем дольше память модели
+	Warmup    int                // сколько сэмплов модель учится, прежде чем сравнивать
+	MinStdDev float64            // нижняя граница стандартного отклонения (для почти постоянных метрик)
+	ClearFor  time.Duration      // инцидент anomaly закрывается после ClearFor без аномалий
+}
+
+// Validate проверяет настройки
+func (c *AnomalyConfig) Validate() error {
+	var d tr181.DeviceData
+	for _, m := range c.Metrics {
+		if _, ok := d.GetMetricValue(m); !ok {
+			return fmt.Errorf("anomaly: unknown metric %q", m)
+		}
+	}
+	switch {
+	case c.Sigma <= 0:
+		return fmt.Errorf("anomaly: sigma must be positive")
+	case c.Alpha <= 0 || c.Alpha >= 1:
+		return fmt.Errorf("anomaly: alpha must be between 0 and 1")
+	case c.Warmup < 1:
+		return fmt.Errorf("anomaly: warmup must be at least 1 sample")
+	case c.MinStdDev < 0:
+		return fmt.Errorf("anomaly: min stddev must not be negative")
+	case c.ClearFor < 0 || c.ClearFor > maxRuleFor:
+		return fmt.Errorf("anomaly: clear_for must be between 0 and %s", maxRuleFor)
+	}
+	return nil
+}
+
+// AnomalyStore — модели аномалий по устройствам, общие для экземпляров alert-processor
+// (реализуется database.RedisCache)
+type AnomalyStore interface {
+	// UpdateAnomalyModel добавляет сэмпл в модель и возвращает модель до него
+	UpdateAnomalyModel(ctx context.Context, metricType, serialNumber string, ts time.Time, value, alpha float64) (database.AnomalyModel, error)
+}
+
+// AnomalyAdapter — статистические аномалии: сэмпл сравнивается с моделью устройства (EWMA среднего
+// и дисперсии этой метрики), а не с общим порогом. Устройство с CPU около 5% получит алерт на 50%,
+// хотя порог high-cpu-usage не пройден
+type AnomalyAdapter struct {
+	cfg AnomalyConfig
+}
+
+// NewAnomalyAdapter создаёт адаптер аномалий (настройки проверяются заранее, см. AnomalyConfig.Validate)
+func NewAnomalyAdapter(cfg AnomalyConfig) *AnomalyAdapter {
+	return &AnomalyAdapter{cfg: cfg}
+}
+
+// Evaluate — без модели аномалию не определить (см. EvaluateState)
+func (a *AnomalyAdapter) Evaluate(*tr181.TR181Device) []AlertResult {
+	return nil
+}
+
+// EvaluateState обновляет модели метрик устройства и возвращает алерты anomaly для сэмплов,
+// отклонившихся от модели (до окончания обучения — ничего)
+func (a *AnomalyAdapter) EvaluateState(ctx context.Context, device *tr181.TR181Device, store StateStore) ([]AlertResult, error) {
+	var results []AlertResult
+	for _, metric := range a.cfg.Metrics {
+		value, _ := device.Data.GetMetricValue(metric)
+		model, err := store.UpdateAnomalyModel(ctx, string(metric), device.SerialNumber, device.Timestamp, float64(value), a.cfg.Alpha)
+		if err != nil {
+			return nil, fmt.Errorf("anomaly %s: %w", metric, err)
+		}
+		if model.Samples < a.cfg.Warmup {
+			continue
+		}
+		diff := float64(value) - model.Mean
+		if math.Abs(diff) >= a.cfg.Sigma*math.Max(model.StdDev, a.cfg.MinStdDev) {
+			results = append(results, AlertResult{
+				Type:     tr181.AlertAnomaly,
+				Value:    value,
+				Metric:   metric,
+				Severity: tr181.SeverityWarning,
+				Below:    diff < 0,
+			})
+		}
+	}
+	return results, nil
+}
+
+// Recovered — сэмпл без аномалий снимает условие: если хоть одна метрика аномальна, тип anomaly
+// сработал в этом сэмпле и восстановлением не считается (см. trackIncidents)
+func (a *AnomalyAdapter) Recovered(*tr181.TR181Device) []Recovery {
+	return []Recovery{{Type: tr181.AlertAnomaly, For: a.cfg.ClearFor}}
+}
@@ -0,0 +1,89 @@
+package adapters
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+)
+
+// RebootConfig — настройки адаптера перезагрузок
+type RebootConfig struct {
+	LoopCount  int           // столько перезагрузок за LoopWindow — reboot-loop (0 — не искать)
+	LoopWindow time.Duration // окно поиска reboot-loop
+}
+
+// Validate проверяет настройки
+func (c *RebootConfig) Validate() error {
+	if c.LoopCount < 0 {
+		return fmt.Errorf("reboot: loop count must not be negative")
+	}
+	if c.LoopCount > 0 && (c.LoopWindow <= 0 || c.LoopWindow > maxRuleFor) {
+		return fmt.Errorf("reboot: loop window must be between 0 and %s", maxRuleFor)
+	}
+	return nil
+}
+
+// RebootStore — последний uptime устройств, общий для экземпляров alert-processor (реализуется database.RedisCache)
+type RebootStore interface {
+	ObserveUptime(ctx context.Context, serialNumber string, ts time.Time, uptime int64, window time.Duration) (database.UptimeObservation, error)
+}
+
+// RebootAdapter — перезагрузки по uptime: uptime меньше, чем в прошлом сэмпле, — событие device-rebooted
+// (без инцидента), LoopCount перезагрузок за LoopWindow — алерт reboot-loop уровня error
+type RebootAdapter struct {
+	cfg RebootConfig
+}
+
+// NewRebootAdapter создаёт адаптер перезагрузок (настройки проверяются заранее, см. RebootConfig.Validate)
+func NewRebootAdapter(cfg RebootConfig) *RebootAdapter {
+	return &RebootAdapter{cfg: cfg}
+}
+
+// Evaluate — без прошлого сэмпла перезагрузку не определить (см. EvaluateState)
+func (a *RebootAdapter) Evaluate(*tr181.TR181Device) []AlertResult {
+	return nil
+}
+
+// EvaluateState сравнивает uptime сэмпла с предыдущим и возвращает device-rebooted и, при частых
+// перезагрузках, reboot-loop. Сэмпл без uptime (0) не учитывается
+func (a *RebootAdapter) EvaluateState(ctx context.Context, device *tr181.TR181Device, store StateStore) ([]AlertResult, error) {
+	if device.Data.Uptime <= 0 {
+		return nil, nil
+	}
+	obs, err := store.ObserveUptime(ctx, device.SerialNumber, device.Timestamp, device.Data.Uptime, a.cfg.LoopWindow)
+	if err != nil {
+		return nil, fmt.Errorf("uptime: %w", err)
+	}
+	if !obs.Rebooted {
+		return nil, nil
+	}
+
+	results := []AlertResult{{
+		Type:     tr181.AlertDeviceRebooted,
+		Value:    int(obs.UptimeBefore), // сколько проработало до перезагрузки
+		Metric:   tr181.MetricUptime,
+		Severity: tr181.SeverityInfo,
+		Event:    true,
+	}}
+	if a.cfg.LoopCount > 0 && obs.Recent >= a.cfg.LoopCount {
+		results = append(results, AlertResult{
+			Type:     tr181.AlertRebootLoop,
+			Value:    obs.Recent, // перезагрузок за окно
+			Metric:   tr181.MetricUptime,
+			Severity: tr181.SeverityError,
+		})
+	}
+	return results, nil
+}
+
+// Recovered — сэмпл без reboot-loop снимает условие; инцидент закрывается, если за окно поиска
+// новых зацикленных перезагрузок не было
+func (a *RebootAdapter) Recovered(*tr181.TR181Device) []Recovery {
+	if a.cfg.LoopCount == 0 {
+		return nil
+	}
+	return []Recovery{{Type: tr181.AlertRebootLoop, For: a.cfg.LoopWindow}}
+}
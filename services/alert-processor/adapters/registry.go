@@ -1,10 +1,21 @@
 package adapters
 
-// Registry возвращает все зарегистрированные адаптеры для оценки TR181 данных.
-// Новый адаптер — создать файл (например temperature_adapter.go) и добавить сюда.
-func Registry() []Adapter {
-	return []Adapter{
-		NewCPUAdapter(),   // алерт при высокой загрузке CPU
-		NewWiFiAdapter(),  // алерт при слабом сигнале WiFi
+// Config — настройки адаптеров, которые не выражаются правилами
+type Config struct {
+	Anomaly AnomalyConfig
+	Reboot  RebootConfig
+}
+
+// Registry возвращает адаптеры для оценки TR181 данных: декларативные правила (см. rules_default.yaml)
+// и адаптеры, которые нельзя выразить правилом. Новый порог — правило в YAML или в таблице alert_rules,
+// новый адаптер на Go — создать файл и добавить сюда.
+func Registry(rules []Rule, cfg Config) []Adapter {
+	registry := []Adapter{
+		NewRuleAdapter(rules),        // пороговые правила
+		NewRebootAdapter(cfg.Reboot), // перезагрузки по uptime
+	}
+	if len(cfg.Anomaly.Metrics) > 0 {
+		registry = append(registry, NewAnomalyAdapter(cfg.Anomaly)) // отклонения от модели устройства
 	}
+	return registry
 }
@@ -0,0 +1,307 @@
+package adapters
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang-test-dev/pkg/tr181"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	maxRuleFor     = 24 * time.Hour  // максимальная длительность условия for
+	maxRuleSamples = 32              // максимальное окно в сэмплах
+	conditionGap   = 2 * time.Minute // перерыв в данных, после которого отсчёт for и окно сэмплов начинаются заново
+)
+
+// alertTypePattern — допустимое имя типа алерта (как у встроенных: high-cpu-usage)
+var alertTypePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+//go:embed rules_default.yaml
+var defaultRulesYAML []byte
+
+// Rule — декларативное правило алерта: значение метрики сравнивается с порогом.
+// Если метрик несколько, берётся худшее значение: максимальное для > и >=, минимальное для < и <=.
+// Правило с For или Samples срабатывает не на одиночный сэмпл, а на устойчивое нарушение
+// (состояние по устройствам хранится в ConditionStore)
+type Rule struct {
+	Name       string              `yaml:"name" json:"name"`             // уникальное имя правила
+	AlertType  tr181.AlertType     `yaml:"alert_type" json:"alert_type"` // тип создаваемого алерта
+	Metrics    []tr181.MetricType  `yaml:"metrics" json:"metrics"`       // проверяемые метрики
+	Condition  string              `yaml:"condition" json:"condition"`   // >, >=, < или <=
+	Threshold  int                 `yaml:"threshold" json:"threshold"`   // порог срабатывания
+	Severity   tr181.AlertSeverity `yaml:"severity" json:"severity"`     // уровень при срабатывании (по умолчанию warning)
+	Severities []SeverityLevel     `yaml:"severities" json:"severities"` // более высокие уровни при более строгих порогах
+	For        time.Duration       `yaml:"for" json:"for"`               // условие нарушено непрерывно не меньше For
+	Count      int                 `yaml:"count" json:"count"`           // условие нарушено в Count из последних Samples сэмплов
+	Samples    int                 `yaml:"samples" json:"samples"`
+	Clear      *int                `yaml:"clear_threshold" json:"clear_threshold"` // порог восстановления (гистерезис), по умолчанию Threshold
+	ClearFor   time.Duration       `yaml:"clear_for" json:"clear_for"`             // восстановление должно держаться не меньше ClearFor
+	Disabled   bool                `yaml:"disabled" json:"disabled"`               // правило выключено
+}
+
+// SeverityLevel — уровень важности, если значение проходит и этот порог
+type SeverityLevel struct {
+	Severity  tr181.AlertSeverity `yaml:"severity" json:"severity"`
+	Threshold int                 `yaml:"threshold" json:"threshold"`
+}
+
+// ruleFile — формат YAML файла правил
+type ruleFile struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// ParseRules разбирает правила из YAML (без проверки — см. ValidateRules)
+func ParseRules(data []byte) ([]Rule, error) {
+	var f ruleFile
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse rules: %w", err)
+	}
+	return f.Rules, nil
+}
+
+// DefaultRules возвращает встроенные правила (rules_default.yaml)
+func DefaultRules() []Rule {
+	rules, err := ParseRules(defaultRulesYAML)
+	if err != nil {
+		panic(err) // встроенный файл проверяется при сборке и запуске
+	}
+	return rules
+}
+
+// ValidateRules проверяет все правила и уникальность имён
+func ValidateRules(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return err
+		}
+		if names[rules[i].Name] {
+			return fmt.Errorf("rule %q: duplicate name", rules[i].Name)
+		}
+		names[rules[i].Name] = true
+	}
+	return nil
+}
+
+// Validate проверяет определение правила
+func (r *Rule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("rule without name")
+	}
+	if !alertTypePattern.MatchString(string(r.AlertType)) {
+		return fmt.Errorf("rule %q: invalid alert_type %q", r.Name, r.AlertType)
+	}
+	if len(r.Metrics) == 0 {
+		return fmt.Errorf("rule %q: no metrics", r.Name)
+	}
+	var d tr181.DeviceData
+	for _, m := range r.Metrics {
+		if _, ok := d.GetMetricValue(m); !ok {
+			return fmt.Errorf("rule %q: unknown metric %q", r.Name, m)
+		}
+	}
+	if !validCondition(r.Condition) {
+		return fmt.Errorf("rule %q: invalid condition %q: use >, >=, < or <=", r.Name, r.Condition)
+	}
+	if r.Severity != "" && r.Severity.Rank() == 0 {
+		return fmt.Errorf("rule %q: unknown severity %q", r.Name, r.Severity)
+	}
+	if r.For < 0 || r.For > maxRuleFor {
+		return fmt.Errorf("rule %q: for must be between 0 and %s", r.Name, maxRuleFor)
+	}
+	if r.Count != 0 || r.Samples != 0 {
+		if r.Count < 1 || r.Count > r.Samples || r.Samples > maxRuleSamples {
+			return fmt.Errorf("rule %q: need 1 <= count <= samples <= %d", r.Name, maxRuleSamples)
+		}
+		if r.For > 0 {
+			return fmt.Errorf("rule %q: for and count/samples are mutually exclusive", r.Name)
+		}
+	}
+	// Порог восстановления не строже порога срабатывания: иначе значение между ними и нарушает, и восстанавливает
+	if r.Clear != nil && *r.Clear != r.Threshold && compare(r.Condition, *r.Clear, r.Threshold) {
+		return fmt.Errorf("rule %q: clear_threshold %d is stricter than threshold %d", r.Name, *r.Clear, r.Threshold)
+	}
+	if r.ClearFor < 0 || r.ClearFor > maxRuleFor {
+		return fmt.Errorf("rule %q: clear_for must be between 0 and %s", r.Name, maxRuleFor)
+	}
+	for _, l := range r.Severities {
+		if l.Severity.Rank() == 0 {
+			return fmt.Errorf("rule %q: unknown severity %q", r.Name, l.Severity)
+		}
+		// Порог уровня не мягче основного: иначе уровень недостижим в части диапазона
+		if l.Threshold != r.Threshold && !compare(r.Condition, l.Threshold, r.Threshold) {
+			return fmt.Errorf("rule %q: %s threshold %d is less strict than rule threshold %d", r.Name, l.Severity, l.Threshold, r.Threshold)
+		}
+	}
+	return nil
+}
+
+// Stateful — правило зависит от предыдущих сэмплов устройства
+func (r *Rule) Stateful() bool {
+	return r.For > 0 || r.Samples > 0
+}
+
+// ConditionStore — состояние условий правил по устройствам, общее для экземпляров alert-processor
+// (реализуется database.RedisCache)
+type ConditionStore interface {
+	// ObserveCondition записывает сэмпл и возвращает начало непрерывного нарушения (нулевое — не нарушено)
+	// и число нарушений среди последних samples сэмплов
+	ObserveCondition(ctx context.Context, rule, serialNumber string, ts time.Time, breach bool, samples int, ttl time.Duration) (time.Time, int, error)
+}
+
+// RuleAdapter — адаптер, вычисляющий декларативные правила
+type RuleAdapter struct {
+	rules []Rule
+}
+
+// NewRuleAdapter создаёт адаптер для правил (выключенные пропускаются)
+func NewRuleAdapter(rules []Rule) *RuleAdapter {
+	a := &RuleAdapter{}
+	for _, r := range rules {
+		if !r.Disabled {
+			a.rules = append(a.rules, r)
+		}
+	}
+	return a
+}
+
+// Evaluate возвращает алерты сработавших правил без состояния (правила с For/Samples — см. EvaluateState)
+func (a *RuleAdapter) Evaluate(device *tr181.TR181Device) []AlertResult {
+	var results []AlertResult
+	for i := range a.rules {
+		r := &a.rules[i]
+		if r.Stateful() {
+			continue
+		}
+		if value, metric := r.value(&device.Data); compare(r.Condition, value, r.Threshold) {
+			results = append(results, r.result(value, metric))
+		}
+	}
+	return results
+}
+
+// EvaluateState возвращает алерты всех сработавших правил, обновляя состояние правил с For/Samples
+func (a *RuleAdapter) EvaluateState(ctx context.Context, device *tr181.TR181Device, store StateStore) ([]AlertResult, error) {
+	var results []AlertResult
+	for i := range a.rules {
+		r := &a.rules[i]
+		value, metric := r.value(&device.Data)
+		breach := compare(r.Condition, value, r.Threshold)
+		if r.Stateful() {
+			since, hits, err := store.ObserveCondition(ctx, r.Name, device.SerialNumber, device.Timestamp, breach, r.Samples, conditionGap)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+			}
+			// Алерт — только на нарушающий сэмпл, когда нарушение устойчивое
+			breach = breach && (r.For == 0 || !since.IsZero() && device.Timestamp.Sub(since) >= r.For) &&
+				(r.Samples == 0 || hits >= r.Count)
+		}
+		if breach {
+			results = append(results, r.result(value, metric))
+		}
+	}
+	return results, nil
+}
+
+// Recovered возвращает типы алертов, условие которых в этом сэмпле снято: значение по ту сторону порога
+// восстановления у всех правил этого типа. For — наибольший clear_for этих правил
+func (a *RuleAdapter) Recovered(device *tr181.TR181Device) []Recovery {
+	breached := make(map[tr181.AlertType]bool)
+	for i := range a.rules {
+		r := &a.rules[i]
+		if value, _ := r.value(&device.Data); !r.recovered(value) {
+			breached[r.AlertType] = true
+		}
+	}
+
+	var recovered []Recovery
+	index := make(map[tr181.AlertType]int)
+	for i := range a.rules {
+		r := &a.rules[i]
+		if breached[r.AlertType] {
+			continue
+		}
+		if j, ok := index[r.AlertType]; ok {
+			recovered[j].For = max(recovered[j].For, r.ClearFor)
+			continue
+		}
+		index[r.AlertType] = len(recovered)
+		recovered = append(recovered, Recovery{Type: r.AlertType, For: r.ClearFor})
+	}
+	return recovered
+}
+
+// result — алерт правила для значения value метрики metric
+func (r *Rule) result(value int, metric tr181.MetricType) AlertResult {
+	return AlertResult{
+		Type:     r.AlertType,
+		Value:    value,
+		Metric:   metric,
+		Severity: r.severity(value),
+		Below:    r.Condition == "<" || r.Condition == "<=",
+	}
+}
+
+// recovered — значение не нарушает порог восстановления
+func (r *Rule) recovered(value int) bool {
+	clear := r.Threshold
+	if r.Clear != nil {
+		clear = *r.Clear
+	}
+	return !compare(r.Condition, value, clear)
+}
+
+// value — худшее значение метрик правила и метрика, которой оно принадлежит
+func (r *Rule) value(d *tr181.DeviceData) (int, tr181.MetricType) {
+	metric := r.Metrics[0]
+	worst, _ := d.GetMetricValue(metric)
+	for _, m := range r.Metrics[1:] {
+		v, _ := d.GetMetricValue(m)
+		if compare(r.Condition, v, worst) {
+			worst, metric = v, m
+		}
+	}
+	return worst, metric
+}
+
+// severity — наивысший уровень, порог которого пройден
+func (r *Rule) severity(value int) tr181.AlertSeverity {
+	severity := r.Severity
+	if severity == "" {
+		severity = tr181.SeverityWarning
+	}
+	for _, l := range r.Severities {
+		if l.Severity.Rank() > severity.Rank() && (value == l.Threshold || compare(r.Condition, value, l.Threshold)) {
+			severity = l.Severity
+		}
+	}
+	return severity
+}
+
+// validCondition — известен ли оператор сравнения
+func validCondition(cond string) bool {
+	switch cond {
+	case ">", ">=", "<", "<=":
+		return true
+	}
+	return false
+}
+
+// compare применяет оператор сравнения: value <cond> threshold
+func compare(cond string, value, threshold int) bool {
+	switch cond {
+	case ">":
+		return value > threshold
+	case ">=":
+		return value >= threshold
+	case "<":
+		return value < threshold
+	case "<=":
+		return value <= threshold
+	}
+	return false
+}
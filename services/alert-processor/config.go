@@ -0,0 +1,109 @@
+// Настройки адаптеров, которые не выражаются правилами: аномалии (ANOMALY_*) и перезагрузки (REBOOT_LOOP_*).
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
+)
+
+// Значения по умолчанию: модель с памятью около 50 сэмплов (25 минут при отправке раз в 30 секунд),
+// обучение — час, аномалия — отклонение от 4σ
+const (
+	defaultAnomalyMetrics   = "cpu-usage,memory-usage"
+	defaultAnomalySigma     = 4.0
+	defaultAnomalyAlpha     = 0.02
+	defaultAnomalyWarmup    = 120
+	defaultAnomalyMinStdDev = 2.0
+	defaultAnomalyClearFor  = 5 * time.Minute
+)
+
+// По умолчанию reboot-loop — 3 перезагрузки за час
+const (
+	defaultRebootLoopCount  = 3
+	defaultRebootLoopWindow = time.Hour
+)
+
+// adapterConfig читает настройки адаптеров аномалий и перезагрузок
+func adapterConfig() (adapters.Config, error) {
+	anomaly, err := anomalyConfig()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	reboot, err := rebootConfig()
+	if err != nil {
+		return adapters.Config{}, err
+	}
+	return adapters.Config{Anomaly: anomaly, Reboot: reboot}, nil
+}
+
+// anomalyConfig читает настройки аномалий: ANOMALY_METRICS (через запятую; задана пустой — адаптер выключен),
+// ANOMALY_SIGMA, ANOMALY_ALPHA, ANOMALY_WARMUP (сэмплов), ANOMALY_MIN_STDDEV, ANOMALY_CLEAR_FOR
+func anomalyConfig() (adapters.AnomalyConfig, error) {
+	cfg := adapters.AnomalyConfig{
+		Sigma:     defaultAnomalySigma,
+		Alpha:     defaultAnomalyAlpha,
+		Warmup:    defaultAnomalyWarmup,
+		MinStdDev: defaultAnomalyMinStdDev,
+		ClearFor:  defaultAnomalyClearFor,
+	}
+	metrics, ok := os.LookupEnv("ANOMALY_METRICS")
+	if !ok {
+		metrics = defaultAnomalyMetrics
+	}
+	for _, m := range strings.Split(metrics, ",") {
+		if m = strings.TrimSpace(m); m != "" {
+			cfg.Metrics = append(cfg.Metrics, tr181.MetricType(m))
+		}
+	}
+
+	var err error
+	if v := os.Getenv("ANOMALY_SIGMA"); v != "" {
+		if cfg.Sigma, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_SIGMA %q", v)
+		}
+	}
+	if v := os.Getenv("ANOMALY_ALPHA"); v != "" {
+		if cfg.Alpha, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_ALPHA %q", v)
+		}
+	}
+	if v := os.Getenv("ANOMALY_WARMUP"); v != "" {
+		if cfg.Warmup, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_WARMUP %q", v)
+		}
+	}
+	if v := os.Getenv("ANOMALY_MIN_STDDEV"); v != "" {
+		if cfg.MinStdDev, err = strconv.ParseFloat(v, 64); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_MIN_STDDEV %q", v)
+		}
+	}
+	if v := os.Getenv("ANOMALY_CLEAR_FOR"); v != "" {
+		if cfg.ClearFor, err = time.ParseDuration(v); err != nil {
+			return cfg, fmt.Errorf("invalid ANOMALY_CLEAR_FOR %q", v)
+		}
+	}
+	return cfg, cfg.Validate()
+}
+
+// rebootConfig читает настройки перезагрузок: REBOOT_LOOP_COUNT (0 — не искать reboot-loop), REBOOT_LOOP_WINDOW
+func rebootConfig() (adapters.RebootConfig, error) {
+	cfg := adapters.RebootConfig{LoopCount: defaultRebootLoopCount, LoopWindow: defaultRebootLoopWindow}
+	var err error
+	if v := os.Getenv("REBOOT_LOOP_COUNT"); v != "" {
+		if cfg.LoopCount, err = strconv.Atoi(v); err != nil {
+			return cfg, fmt.Errorf("invalid REBOOT_LOOP_COUNT %q", v)
+		}
+	}
+	if v := os.Getenv("REBOOT_LOOP_WINDOW"); v != "" {
+		if cfg.LoopWindow, err = time.ParseDuration(v); err != nil {
+			return cfg, fmt.Errorf("invalid REBOOT_LOOP_WINDOW %q", v)
+		}
+	}
+	return cfg, cfg.Validate()
+}
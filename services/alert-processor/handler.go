@@ -6,11 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"reflect"
+	"sync/atomic"
 	"time"
 
 	pulsarclient "github.com/apache/pulsar-client-go/pulsar"
-	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/pkg/database"
 	"golang-test-dev/pkg/logcollector"
+	"golang-test-dev/pkg/tr181"
 	"golang-test-dev/services/alert-processor/adapters"
 )
 
@@ -19,17 +22,41 @@ type AlertHandler struct {
 	storage  *AlertStorage
 	consumer pulsarclient.Consumer
 	logColl  *logcollector.Collector
-	adapters []adapters.Adapter
+	state    StateStore                         // состояние правил с длительностью и инцидентов (Redis)
+	hb       *heartbeatMonitor                  // связь с устройствами (nil — не отслеживается)
+	pub      *AlertPublisher                    // события для уведомлений (nil — не публикуются)
+	policy   *AlertPolicy                       // тишины и окна обслуживания
+	adapters atomic.Pointer[[]adapters.Adapter] // заменяются целиком при перезагрузке правил
+	rules    []adapters.Rule                    // текущие правила (только для SetRules)
+	cfg      adapters.Config                    // настройки адаптеров аномалий и перезагрузок
 }
 
-// NewAlertHandler создаёт обработчик с storage и списком адаптеров.
-func NewAlertHandler(storage *AlertStorage, consumer pulsarclient.Consumer, logColl *logcollector.Collector) *AlertHandler {
-	return &AlertHandler{
+// NewAlertHandler создаёт обработчик с storage, хранилищем состояния условий, монитором связи hb,
+// публикацией событий pub, политикой уведомлений policy и адаптерами для правил rules и настроек cfg.
+func NewAlertHandler(storage *AlertStorage, consumer pulsarclient.Consumer, logColl *logcollector.Collector, state StateStore, hb *heartbeatMonitor, pub *AlertPublisher, policy *AlertPolicy, rules []adapters.Rule, cfg adapters.Config) *AlertHandler {
+	h := &AlertHandler{
 		storage:  storage,
 		consumer: consumer,
 		logColl:  logColl,
-		adapters: adapters.Registry(),
+		state:    state,
+		hb:       hb,
+		pub:      pub,
+		policy:   policy,
+		cfg:      cfg,
+	}
+	h.SetRules(rules)
+	return h
+}
+
+// SetRules заменяет правила (сообщения, которые уже оцениваются, досчитываются по старым).
+func (h *AlertHandler) SetRules(rules []adapters.Rule) {
+	if h.rules != nil && reflect.DeepEqual(h.rules, rules) {
+		return
 	}
+	registry := adapters.Registry(rules, h.cfg)
+	h.adapters.Store(&registry)
+	h.rules = rules
+	log.Printf("alert rules loaded: %d", len(rules))
 }
 
 // Handle парсит сообщение, прогоняет через адаптеры и сохраняет алерты.
@@ -52,42 +79,113 @@ func (h *AlertHandler) Handle(ctx context.Context, msg pulsarclient.Message) {
 		device.Timestamp = time.Now()
 	}
 
-	// Прогоняем через все адаптеры (CPU, WiFi и т.д.)
-	for _, a := range h.adapters {
-		results := a.Evaluate(&device)
-		for _, r := range results {
-			// Сохраняем каждый алерт в PostgreSQL
-			if err := h.storage.Save(ctx, device.SerialNumber, string(r.Type), r.Value, device.Timestamp); err != nil {
-				log.Printf("save alert: %v", err)
-				h.consumer.Nack(msg) // откатываем для повтора
-				return
-			}
-			// Отправляем в log-viewer (если подключён)
-			if h.logColl != nil {
-				alertMsg := fmt.Sprintf("%s %s value=%d", device.SerialNumber, r.Type, r.Value)
-				level := alertLevel(string(r.Type), r.Value) // warning или error по порогу
-				h.logColl.Send("alert-processor", level, alertMsg)
+	// Устройство на связи (и, возможно, вернулось после отключения)
+	if h.hb != nil {
+		if err := h.hb.Seen(ctx, device.SerialNumber, time.Now()); err != nil {
+			log.Printf("%s: %v", device.SerialNumber, err)
+			h.consumer.Nack(msg)
+			return
+		}
+	}
+
+	// Прогоняем через все адаптеры (правила и т.д.). Сначала оцениваем все: при ошибке хранилища
+	// состояния сообщение повторится, и алерты не должны сохраниться дважды
+	var results []adapters.AlertResult
+	registry := *h.adapters.Load()
+	for _, a := range registry {
+		sa, ok := a.(adapters.StatefulAdapter)
+		if !ok {
+			results = append(results, a.Evaluate(&device)...)
+			continue
+		}
+		res, err := sa.EvaluateState(ctx, &device, h.state)
+		if err != nil {
+			log.Printf("evaluate %s: %v", device.SerialNumber, err)
+			h.consumer.Nack(msg) // откатываем для повтора
+			return
+		}
+		results = append(results, res...)
+	}
+
+	// Открываем и закрываем инциденты
+	events, err := h.trackIncidents(ctx, &device, registry, results)
+	if err != nil {
+		log.Printf("%s: %v", device.SerialNumber, err)
+		h.consumer.Nack(msg)
+		return
+	}
+
+	// Тишины и окна обслуживания: подавленный алерт сохраняется с отметкой, уведомление о нём не отправляется
+	suppressed := make([]string, len(results))
+	for i, r := range results {
+		suppressed[i] = h.policy.Suppressed(device.SerialNumber, r.Type, r.Severity, r.Metric, device.Timestamp)
+	}
+
+	// Сохраняем события в PostgreSQL и добавляем сэмпл в инцидент — один на тип, самый важный алерт
+	// этого типа. Сэмпл, уже добавленный в инцидент (повторная обработка), не учитывается второй раз
+	top := topResults(results)
+	for i, r := range results {
+		var err error
+		if r.Event {
+			err = h.storage.SaveEvent(ctx, device.SerialNumber, device.Timestamp, r, suppressed[i])
+		} else if top[r.Type] == i {
+			err = h.storage.Save(ctx, &device, r, suppressed[i])
+		}
+		if err != nil {
+			log.Printf("save alert: %v", err)
+			h.consumer.Nack(msg) // откатываем для повтора
+			return
+		}
+		// Отправляем в log-viewer (если подключён)
+		if h.logColl != nil {
+			alertMsg := fmt.Sprintf("%s %s value=%d metric=%s", device.SerialNumber, r.Type, r.Value, r.Metric)
+			if suppressed[i] != "" {
+				alertMsg += " suppressed=" + suppressed[i]
 			}
+			h.logColl.Send("alert-processor", string(r.Severity), alertMsg) // warning или error по уровню правила
 		}
 	}
 
+	// Уведомления — после сохранения: открытые и закрытые инциденты и события
+	h.publish(ctx, &device, results, suppressed, events)
+
 	h.consumer.Ack(msg)
 }
 
-// alertLevel определяет уровень алерта: error = критичное, warning = превышение нормы.
-func alertLevel(alertType string, value int) string {
-	switch alertType {
-	case "high-cpu-usage":
-		if value >= 80 {  // CPU 80%+ — критично
-			return "error"
+// publish публикует открытия (с самым важным алертом этого типа в сэмпле) и закрытия инцидентов и события,
+// кроме подавленных. Закрытие сопоставляется с тишинами по уровню инцидента (без метрики)
+func (h *AlertHandler) publish(ctx context.Context, device *tr181.TR181Device, results []adapters.AlertResult, suppressed []string, events []database.IncidentEvent) {
+	top := topResults(results)
+	for _, e := range events {
+		if !e.Opened {
+			alertType, severity := tr181.AlertType(e.AlertType), tr181.AlertSeverity(e.Severity)
+			if h.policy.Suppressed(device.SerialNumber, alertType, severity, "", e.Time) == "" {
+				h.pub.Resolved(ctx, device.SerialNumber, alertType, severity, e.Time)
+			}
+			continue
+		}
+		if i, ok := top[tr181.AlertType(e.AlertType)]; ok && suppressed[i] == "" {
+			h.pub.Firing(ctx, device.SerialNumber, e.Time, results[i])
+		}
+	}
+	for i, r := range results {
+		if r.Event && suppressed[i] == "" {
+			h.pub.Firing(ctx, device.SerialNumber, device.Timestamp, r)
+		}
+	}
+}
+
+// topResults возвращает для каждого типа алерта с инцидентом индекс самого важного алерта в results
+// (при равном уровне — первого)
+func topResults(results []adapters.AlertResult) map[tr181.AlertType]int {
+	top := make(map[tr181.AlertType]int)
+	for i, r := range results {
+		if r.Event {
+			continue
 		}
-		return "warning"  // 60-79% — предупреждение
-	case "low-wifi":
-		if value <= -110 {  // сигнал хуже -110 dBm — критично
-			return "error"
+		if j, ok := top[r.Type]; !ok || r.Severity.Rank() > results[j].Severity.Rank() {
+			top[r.Type] = i
 		}
-		return "warning"  // -100..-110 dBm — предупреждение
-	default:
-		return "warning"
 	}
+	return top
 }
@@ -0,0 +1,182 @@
+// Отключение устройств: алерт device-offline, если сообщений нет дольше OFFLINE_AFTER интервалов отправки,
+// и событие device-online, когда они снова приходят. Проверка идёт по таймеру, а не по сообщениям.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/logcollector"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
+)
+
+const (
+	defaultReportInterval = 30 * time.Second // интервал отправки симулятора
+	defaultOfflineAfter   = 3                // пропущенных интервалов до device-offline
+	heartbeatBatch        = 1000             // устройств за один вызов ExpireHeartbeats
+)
+
+// HeartbeatStore — время последнего сообщения устройств, общее для экземпляров alert-processor
+// (реализуется database.RedisCache)
+type HeartbeatStore interface {
+	TouchDevice(ctx context.Context, serialNumber string, now time.Time) (time.Time, error)
+	ExpireHeartbeats(ctx context.Context, deadline time.Time, limit int) ([]database.OfflineDevice, error)
+	RestoreOffline(ctx context.Context, serialNumber string, lastSeen time.Time) error
+	RestoreHeartbeat(ctx context.Context, d database.OfflineDevice) error
+}
+
+// heartbeatMonitor отмечает сообщения устройств и по таймеру находит замолчавшие
+type heartbeatMonitor struct {
+	store    HeartbeatStore
+	storage  *AlertStorage
+	pub      *AlertPublisher
+	policy   *AlertPolicy
+	logColl  *logcollector.Collector
+	interval time.Duration // интервал отправки (и проверки)
+	timeout  time.Duration // без сообщений дольше — устройство недоступно
+	started  time.Time
+}
+
+// newHeartbeatMonitor создаёт монитор: DEVICE_REPORT_INTERVAL (по умолчанию 30s) и OFFLINE_AFTER —
+// сколько интервалов без сообщений (по умолчанию 3, 0 — не отслеживать). nil — отслеживание выключено
+func newHeartbeatMonitor(store HeartbeatStore, storage *AlertStorage, pub *AlertPublisher, policy *AlertPolicy, logColl *logcollector.Collector) (*heartbeatMonitor, error) {
+	interval := defaultReportInterval
+	if v := os.Getenv("DEVICE_REPORT_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid DEVICE_REPORT_INTERVAL %q", v)
+		}
+		interval = d
+	}
+	after := defaultOfflineAfter
+	if v := os.Getenv("OFFLINE_AFTER"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OFFLINE_AFTER %q", v)
+		}
+		after = n
+	}
+	if after == 0 {
+		return nil, nil
+	}
+	return &heartbeatMonitor{
+		store:    store,
+		storage:  storage,
+		pub:      pub,
+		policy:   policy,
+		logColl:  logColl,
+		interval: interval,
+		timeout:  interval * time.Duration(after),
+		started:  time.Now(),
+	}, nil
+}
+
+// Seen отмечает сообщение устройства; если устройство было недоступно — закрывает инцидент device-offline
+// и сохраняет событие device-online (уведомление — закрытие device-offline). Время — время получения:
+// часы устройства могут расходиться. Возвращение фиксирует один экземпляр; если его не удалось сохранить,
+// устройство снова считается недоступным, и повторная обработка сообщения зафиксирует возвращение заново
+func (m *heartbeatMonitor) Seen(ctx context.Context, serialNumber string, now time.Time) error {
+	lastSeen, err := m.store.TouchDevice(ctx, serialNumber, now)
+	if err != nil {
+		return fmt.Errorf("heartbeat: %w", err)
+	}
+	if lastSeen.IsZero() {
+		return nil
+	}
+
+	offline := now.Sub(lastSeen)
+	event := adapters.AlertResult{Type: tr181.AlertDeviceOnline, Value: int(offline.Seconds()), Severity: tr181.SeverityInfo}
+	suppressedBy := m.policy.Suppressed(serialNumber, event.Type, event.Severity, "", now)
+	severity, err := m.storage.CloseIncidentWithEvent(ctx, serialNumber, string(tr181.AlertDeviceOffline), now, event, suppressedBy)
+	if err != nil {
+		if rerr := m.store.RestoreOffline(ctx, serialNumber, lastSeen); rerr != nil {
+			log.Printf("heartbeat %s: %v", serialNumber, rerr)
+		}
+		return fmt.Errorf("save alert: %w", err)
+	}
+	m.notify(serialNumber, event, suppressedBy)
+	if m.policy.Suppressed(serialNumber, tr181.AlertDeviceOffline, tr181.AlertSeverity(severity), "", now) == "" {
+		m.pub.Resolved(ctx, serialNumber, tr181.AlertDeviceOffline, tr181.AlertSeverity(severity), now)
+	}
+	return nil
+}
+
+// Run проверяет устройства раз в интервал отправки до отмены ctx
+func (m *heartbeatMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.check(ctx, now)
+		}
+	}
+}
+
+// check признаёт недоступными устройства без сообщений дольше timeout и сохраняет device-offline.
+// Первые timeout после старта не проверяет: сообщения, накопившиеся, пока alert-processor не работал,
+// ещё не обработаны. Устройство переносится в недоступные до сохранения алерта, поэтому ни один экземпляр
+// не сохранит его дважды; если алерт сохранить не удалось, проверка прерывается, а устройство и остальные
+// из пачки возвращаются в доступные — следующая проверка найдёт их снова
+func (m *heartbeatMonitor) check(ctx context.Context, now time.Time) {
+	if now.Sub(m.started) < m.timeout {
+		return
+	}
+	for {
+		devices, err := m.store.ExpireHeartbeats(ctx, now.Add(-m.timeout), heartbeatBatch)
+		if err != nil {
+			log.Printf("heartbeat: %v", err)
+			return
+		}
+		for i, d := range devices {
+			alert := adapters.AlertResult{
+				Type:     tr181.AlertDeviceOffline,
+				Value:    int(now.Sub(d.LastSeen).Seconds()), // секунд без сообщений
+				Severity: tr181.SeverityWarning,
+			}
+			device := &tr181.TR181Device{SerialNumber: d.SerialNumber, Timestamp: now}
+			suppressedBy := m.policy.Suppressed(d.SerialNumber, alert.Type, alert.Severity, "", now)
+			if err := m.storage.Save(ctx, device, alert, suppressedBy); err != nil {
+				log.Printf("save alert %s %s: %v", d.SerialNumber, alert.Type, err)
+				m.restore(ctx, devices[i:]) // остальные — до следующей проверки
+				return
+			}
+			m.notify(d.SerialNumber, alert, suppressedBy)
+			if suppressedBy == "" {
+				m.pub.Firing(ctx, d.SerialNumber, now, alert)
+			}
+		}
+		if len(devices) < heartbeatBatch {
+			return
+		}
+	}
+}
+
+// restore возвращает в доступные устройства, отключение которых не сохранено
+func (m *heartbeatMonitor) restore(ctx context.Context, devices []database.OfflineDevice) {
+	for _, d := range devices {
+		if err := m.store.RestoreHeartbeat(ctx, d); err != nil {
+			log.Printf("heartbeat %s: %v", d.SerialNumber, err)
+		}
+	}
+}
+
+// notify пишет алерт в лог и log-viewer (если подключён)
+func (m *heartbeatMonitor) notify(serialNumber string, r adapters.AlertResult, suppressedBy string) {
+	msg := fmt.Sprintf("%s %s value=%d", serialNumber, r.Type, r.Value)
+	if suppressedBy != "" {
+		msg += " suppressed=" + suppressedBy
+	}
+	log.Print(msg)
+	if m.logColl != nil {
+		m.logColl.Send("alert-processor", string(r.Severity), msg)
+	}
+}
@@ -0,0 +1,74 @@
+// Инциденты: открытие при первом нарушении, закрытие после устойчивого восстановления.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
+)
+
+// StateStore — состояние, общее для экземпляров alert-processor (реализуется database.RedisCache).
+type StateStore interface {
+	adapters.StateStore
+	TrackIncidents(ctx context.Context, serialNumber string, ts time.Time, firing []string, recovered map[string]time.Duration) ([]database.IncidentEvent, error)
+}
+
+// trackIncidents обновляет состояние инцидентов устройства по результатам оценки сэмпла, закрывает
+// восстановившиеся и возвращает открытия и закрытия. Нарушающие сэмплы добавляются в инциденты
+// при сохранении алертов (см. Handle); у событий (AlertResult.Event) инцидентов нет.
+// Повторная обработка того же сэмпла возвращает те же открытия и закрытия.
+func (h *AlertHandler) trackIncidents(ctx context.Context, device *tr181.TR181Device, registry []adapters.Adapter, results []adapters.AlertResult) ([]database.IncidentEvent, error) {
+	firing := make([]string, 0, len(results))
+	seen := make(map[string]bool, len(results))
+	for _, r := range results {
+		if !r.Event && !seen[string(r.Type)] {
+			seen[string(r.Type)] = true
+			firing = append(firing, string(r.Type))
+		}
+	}
+	recovered := make(map[string]time.Duration)
+	for _, a := range registry {
+		rec, ok := a.(adapters.Recoverer)
+		if !ok {
+			continue
+		}
+		for _, r := range rec.Recovered(device) {
+			if !seen[string(r.Type)] {
+				recovered[string(r.Type)] = max(recovered[string(r.Type)], r.For)
+			}
+		}
+	}
+	if len(firing) == 0 && len(recovered) == 0 {
+		return nil, nil
+	}
+
+	events, err := h.state.TrackIncidents(ctx, device.SerialNumber, device.Timestamp, firing, recovered)
+	if err != nil {
+		return nil, fmt.Errorf("track incidents: %w", err)
+	}
+	for i, e := range events {
+		if !e.Opened {
+			// При повторной обработке сообщения закрытие повторяется (TrackIncidents вернёт то же),
+			// уровень уже закрытого инцидента вернёт CloseIncident
+			severity, err := h.storage.CloseIncident(ctx, device.SerialNumber, e.AlertType, e.Time)
+			if err != nil {
+				return nil, fmt.Errorf("close incident: %w", err)
+			}
+			events[i].Severity = severity
+		}
+		state := "closed"
+		if e.Opened {
+			state = "opened"
+		}
+		log.Printf("incident %s %s %s", state, device.SerialNumber, e.AlertType)
+		if h.logColl != nil {
+			h.logColl.Send("alert-processor", "info", fmt.Sprintf("%s %s incident %s", device.SerialNumber, e.AlertType, state))
+		}
+	}
+	return events, nil
+}
@@ -39,6 +39,16 @@ func main() {
 		log.Printf("schema: %v", err)
 	}
 
+	// Redis — состояние правил с длительностью (for, count/samples), инцидентов и связи с устройствами,
+	// общее для всех экземпляров.
+	// Пока Redis недоступен, сообщения с такими правилами обрабатываются повторно
+	redisAddr := os.Getenv("REDIS_ADDR")
+	if redisAddr == "" {
+		redisAddr = "localhost:6379"
+	}
+	state := database.OpenRedisCache(redisAddr, 0)
+	defer state.Close()
+
 	// Подключаемся к Pulsar
 	client, err := pulsar.NewClient(pulsarURL)
 	if err != nil {
@@ -60,8 +70,56 @@ func main() {
 	}
 	defer consumer.Close()
 
+	// Правила алертов: встроенные или из ALERT_RULES_FILE плюс таблица alert_rules.
+	// Ошибка в определении правила при старте — фатальна, при перезагрузке — остаются прежние правила
+	rulesFile := os.Getenv("ALERT_RULES_FILE")
+	rules, err := loadRules(context.Background(), db, rulesFile)
+	if err != nil {
+		log.Fatalf("rules: %v", err)
+	}
+
+	// Аномалии относительно модели каждого устройства (ANOMALY_*) и частые перезагрузки (REBOOT_LOOP_*)
+	adapterCfg, err := adapterConfig()
+	if err != nil {
+		log.Fatalf("adapters: %v", err)
+	}
+
+	// Политика уведомлений: маршруты (ALERT_ROUTES_FILE), тишины и окна обслуживания из БД. Перечитывается
+	// по сообщению от api-gateway через Redis и раз в POLICY_RELOAD (если сообщение потеряно).
+	// Ошибка при старте — фатальна, при перечитывании — остаётся прежняя
+	routing, err := loadRouting(os.Getenv("ALERT_ROUTES_FILE"))
+	if err != nil {
+		log.Fatalf("routes: %v", err)
+	}
+	policy := NewAlertPolicy(db, routing)
+	policyChanges := state.PolicyChanges() // подписка до первого чтения, чтобы не пропустить изменения
+	if err := policy.Reload(context.Background()); err != nil {
+		log.Fatalf("policy: %v", err)
+	}
+	go policy.Run(context.Background(), policyReloadInterval(), policyChanges)
+
+	// Изменения алертов для уведомлений (notifier) — в топик alerts
+	pub, err := NewAlertPublisher(client, policy)
+	if err != nil {
+		log.Fatalf("alerts producer: %v", err)
+	}
+	defer pub.Close()
+
 	storage := NewAlertStorage(db)
-	handler := NewAlertHandler(storage, consumer, logColl)
+
+	// Отключение устройств (DEVICE_REPORT_INTERVAL, OFFLINE_AFTER): проверка по таймеру, Redis общий
+	hb, err := newHeartbeatMonitor(state, storage, pub, policy, logColl)
+	if err != nil {
+		log.Fatalf("heartbeat: %v", err)
+	}
+	if hb != nil {
+		go hb.Run(context.Background())
+	}
+
+	handler := NewAlertHandler(storage, consumer, logColl, state, hb, pub, policy, rules, adapterCfg)
+	if interval := rulesReloadInterval(); interval > 0 {
+		go reloadRules(context.Background(), handler, db, rulesFile, interval)
+	}
 
 	// Горутина: бесконечный цикл приёма сообщений
 	go func() {
@@ -0,0 +1,198 @@
+// Политика уведомлений: тишины и окна обслуживания (из БД, перечитываются по сообщению от api-gateway
+// и периодически) подавляют
+// уведомления, маршруты выбирают каналы. Подавленные алерты сохраняются с отметкой suppressed_by.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang-test-dev/pkg/alerting"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+)
+
+const (
+	defaultPolicyReload = 30 * time.Second // как часто перечитывать тишины, окна и группы устройств
+	maxLoadedSilences   = 10000            // тишин одного состояния за одно чтение
+)
+
+// PolicyStore — тишины, окна обслуживания и группы устройств (реализуется database.PostgresDB)
+type PolicyStore interface {
+	GetSilences(ctx context.Context, state string, now time.Time, limit int) ([]database.Silence, error)
+	GetMaintenanceWindows(ctx context.Context, group string) ([]database.MaintenanceWindow, error)
+	GetAllDeviceGroups(ctx context.Context) (map[string][]string, error)
+}
+
+// silence — тишина с разобранными матчерами
+type silence struct {
+	id       int64
+	matchers []alerting.Matcher
+	startsAt time.Time
+	endsAt   time.Time
+}
+
+// window — окно обслуживания с разобранным расписанием
+type window struct {
+	id       int64
+	group    string
+	schedule *alerting.Schedule
+	matchers []alerting.Matcher
+}
+
+// policySnapshot — прочитанные из БД тишины, окна и группы (заменяется целиком)
+type policySnapshot struct {
+	silences []silence
+	windows  []window
+	groups   map[string][]string // серийный номер → группы
+}
+
+// AlertPolicy решает, подавлено ли уведомление об алерте и в какие каналы оно отправляется
+type AlertPolicy struct {
+	store   PolicyStore
+	routing *Routing // nil — маршруты не настроены: notifier отправляет во все каналы
+	snap    atomic.Pointer[policySnapshot]
+}
+
+// NewAlertPolicy создаёт политику; до первого Reload ничего не подавляется
+func NewAlertPolicy(store PolicyStore, routing *Routing) *AlertPolicy {
+	p := &AlertPolicy{store: store, routing: routing}
+	p.snap.Store(&policySnapshot{})
+	return p
+}
+
+// Reload перечитывает тишины (действующие и будущие), окна обслуживания и группы устройств.
+// Записи с ошибкой (созданные в обход API) пропускаются с сообщением в лог
+func (p *AlertPolicy) Reload(ctx context.Context) error {
+	now := time.Now()
+	snap := &policySnapshot{}
+	for _, state := range []string{database.SilenceActive, database.SilencePending} {
+		records, err := p.store.GetSilences(ctx, state, now, maxLoadedSilences)
+		if err != nil {
+			return fmt.Errorf("silences: %w", err)
+		}
+		for _, rec := range records {
+			matchers, err := alerting.ParseMatchers(rec.Matchers)
+			if err != nil {
+				log.Printf("silence %d: %v (ignored)", rec.ID, err)
+				continue
+			}
+			snap.silences = append(snap.silences, silence{id: rec.ID, matchers: matchers, startsAt: rec.StartsAt, endsAt: rec.EndsAt})
+		}
+	}
+
+	records, err := p.store.GetMaintenanceWindows(ctx, "")
+	if err != nil {
+		return fmt.Errorf("maintenance windows: %w", err)
+	}
+	for _, rec := range records {
+		schedule, err := alerting.NewSchedule(rec.Days, rec.StartTime, time.Duration(rec.DurationSeconds)*time.Second, rec.Timezone)
+		if err != nil {
+			log.Printf("maintenance window %d: %v (ignored)", rec.ID, err)
+			continue
+		}
+		matchers, err := alerting.ParseMatchers(rec.Matchers)
+		if err != nil {
+			log.Printf("maintenance window %d: %v (ignored)", rec.ID, err)
+			continue
+		}
+		snap.windows = append(snap.windows, window{id: rec.ID, group: rec.Group, schedule: schedule, matchers: matchers})
+	}
+
+	if snap.groups, err = p.store.GetAllDeviceGroups(ctx); err != nil {
+		return fmt.Errorf("device groups: %w", err)
+	}
+	p.snap.Store(snap)
+	return nil
+}
+
+// Run перечитывает политику при каждом сообщении из changed и раз в interval (0 — только по сообщениям)
+// до отмены ctx; при ошибке остаётся прежняя
+func (p *AlertPolicy) Run(ctx context.Context, interval time.Duration, changed <-chan struct{}) {
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tick:
+		case <-changed:
+		}
+		if err := p.Reload(ctx); err != nil {
+			log.Printf("reload policy: %v (keeping previous)", err)
+		}
+	}
+}
+
+// Suppressed возвращает, что подавляет уведомление об алерте в момент ts: silence:<id>, maintenance:<id>
+// или пусто — не подавлено
+func (p *AlertPolicy) Suppressed(serialNumber string, alertType tr181.AlertType, severity tr181.AlertSeverity, metric tr181.MetricType, ts time.Time) string {
+	snap := p.snap.Load()
+	if len(snap.silences) == 0 && len(snap.windows) == 0 {
+		return ""
+	}
+	a := snap.alert(serialNumber, alertType, severity, metric)
+	for _, s := range snap.silences {
+		if !ts.Before(s.startsAt) && ts.Before(s.endsAt) && alerting.MatchAll(s.matchers, a) {
+			return fmt.Sprintf("silence:%d", s.id)
+		}
+	}
+	for _, w := range snap.windows {
+		if inGroup(a.Groups, w.group) && w.schedule.Active(ts) && alerting.MatchAll(w.matchers, a) {
+			return fmt.Sprintf("maintenance:%d", w.id)
+		}
+	}
+	return ""
+}
+
+// Receivers возвращает каналы для уведомления; ok=false — маршруты не настроены (все каналы)
+func (p *AlertPolicy) Receivers(serialNumber string, alertType tr181.AlertType, severity tr181.AlertSeverity, metric tr181.MetricType) (receivers []string, ok bool) {
+	if p.routing == nil {
+		return nil, false
+	}
+	return p.routing.Receivers(p.snap.Load().alert(serialNumber, alertType, severity, metric)), true
+}
+
+// alert собирает алерт для матчеров (с группами устройства)
+func (s *policySnapshot) alert(serialNumber string, alertType tr181.AlertType, severity tr181.AlertSeverity, metric tr181.MetricType) *alerting.Alert {
+	return &alerting.Alert{
+		SerialNumber: serialNumber,
+		Type:         string(alertType),
+		Severity:     string(severity),
+		Metric:       string(metric),
+		Groups:       s.groups[serialNumber],
+	}
+}
+
+// inGroup — есть ли group среди groups
+func inGroup(groups []string, group string) bool {
+	for _, g := range groups {
+		if g == group {
+			return true
+		}
+	}
+	return false
+}
+
+// policyReloadInterval читает POLICY_RELOAD (по умолчанию 30s; 0 — только при старте и по сообщениям)
+func policyReloadInterval() time.Duration {
+	v := strings.TrimSpace(os.Getenv("POLICY_RELOAD"))
+	if v == "" {
+		return defaultPolicyReload
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Printf("invalid POLICY_RELOAD %q, using %s", v, defaultPolicyReload)
+		return defaultPolicyReload
+	}
+	return d
+}
@@ -0,0 +1,94 @@
+// Публикация изменений алертов в топик alerts (для notifier).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	pulsarclient "github.com/apache/pulsar-client-go/pulsar"
+	"golang-test-dev/pkg/pulsar"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
+)
+
+// AlertPublisher публикует события алертов с каналами по маршрутам policy. Ошибка публикации пишется
+// в лог и сообщение не повторяет: алерт уже сохранён, повтор сохранил бы его дважды
+type AlertPublisher struct {
+	producer pulsarclient.Producer
+	policy   *AlertPolicy
+}
+
+// NewAlertPublisher создаёт producer для топика alerts
+func NewAlertPublisher(client pulsarclient.Client, policy *AlertPolicy) (*AlertPublisher, error) {
+	prod, err := client.CreateProducer(pulsarclient.ProducerOptions{
+		Topic: pulsar.TopicAlerts,
+		Name:  "alert-processor-alerts",
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &AlertPublisher{producer: prod, policy: policy}, nil
+}
+
+// Close закрывает producer
+func (p *AlertPublisher) Close() {
+	if p != nil {
+		p.producer.Close()
+	}
+}
+
+// Firing публикует открытие инцидента или событие r устройства в момент ts
+func (p *AlertPublisher) Firing(ctx context.Context, serialNumber string, ts time.Time, r adapters.AlertResult) {
+	p.publish(ctx, tr181.AlertEvent{
+		Status:       tr181.AlertFiring,
+		SerialNumber: serialNumber,
+		Type:         r.Type,
+		Severity:     r.Severity,
+		Value:        r.Value,
+		Metric:       r.Metric,
+		Time:         ts,
+	})
+}
+
+// Resolved публикует закрытие инцидента типа alertType уровня severity (пусто — неизвестен, info)
+func (p *AlertPublisher) Resolved(ctx context.Context, serialNumber string, alertType tr181.AlertType, severity tr181.AlertSeverity, ts time.Time) {
+	if severity == "" {
+		severity = tr181.SeverityInfo
+	}
+	p.publish(ctx, tr181.AlertEvent{
+		Status:       tr181.AlertResolved,
+		SerialNumber: serialNumber,
+		Type:         alertType,
+		Severity:     severity,
+		Time:         ts,
+	})
+}
+
+// publish отправляет событие асинхронно. nil — публикация выключена. Если маршруты настроены,
+// а ни один не совпал (и каналов по умолчанию нет), событие не публикуется
+func (p *AlertPublisher) publish(ctx context.Context, e tr181.AlertEvent) {
+	if p == nil {
+		return
+	}
+	if receivers, ok := p.policy.Receivers(e.SerialNumber, e.Type, e.Severity, e.Metric); ok {
+		if len(receivers) == 0 {
+			return
+		}
+		e.Receivers = receivers
+	}
+	e.ID = fmt.Sprintf("%s:%s:%s:%d", e.SerialNumber, e.Type, e.Status, e.Time.UnixMilli())
+	data, err := json.Marshal(e)
+	if err != nil {
+		log.Printf("publish %s: %v", e.ID, err)
+		return
+	}
+	msg := &pulsarclient.ProducerMessage{Payload: data, Key: e.SerialNumber}
+	p.producer.SendAsync(ctx, msg, func(_ pulsarclient.MessageID, _ *pulsarclient.ProducerMessage, err error) {
+		if err != nil {
+			log.Printf("publish %s: %v", e.ID, err)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+// Маршруты уведомлений (ALERT_ROUTES_FILE): по типу, уровню, метрике и группам устройства алерт
+// отправляется в каналы notifier (receivers).
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"golang-test-dev/pkg/alerting"
+	"gopkg.in/yaml.v3"
+)
+
+// Route — маршрут: алерт, совпавший со всеми матчерами, отправляется в Receivers
+type Route struct {
+	Name      string   `yaml:"name"`
+	Matchers  []string `yaml:"matchers"`  // severity=error, group=~"core-.*" (пусто — любой алерт)
+	Receivers []string `yaml:"receivers"` // имена каналов notifier
+	Continue  bool     `yaml:"continue"`  // после совпадения проверять следующие маршруты
+	matchers  []alerting.Matcher
+}
+
+// Routing — маршруты по порядку; первый совпавший (без continue) завершает поиск
+type Routing struct {
+	Routes           []Route  `yaml:"routes"`
+	DefaultReceivers []string `yaml:"default_receivers"` // если не совпал ни один маршрут (пусто — не отправлять)
+}
+
+// loadRouting читает маршруты из файла; пустое имя — маршрутизация не настроена (nil: все каналы)
+func loadRouting(file string) (*Routing, error) {
+	if file == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var r Routing
+	if err := yaml.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for i := range r.Routes {
+		route := &r.Routes[i]
+		if route.Name == "" {
+			route.Name = fmt.Sprintf("#%d", i+1)
+		}
+		if len(route.Receivers) == 0 {
+			return nil, fmt.Errorf("%s: route %s: receivers are required", file, route.Name)
+		}
+		if route.matchers, err = alerting.ParseMatchers(route.Matchers); err != nil {
+			return nil, fmt.Errorf("%s: route %s: %w", file, route.Name, err)
+		}
+	}
+	return &r, nil
+}
+
+// Receivers возвращает каналы для алерта (без повторов, в порядке маршрутов)
+func (r *Routing) Receivers(a *alerting.Alert) []string {
+	var receivers []string
+	seen := make(map[string]bool)
+	add := func(names []string) {
+		for _, n := range names {
+			if !seen[n] {
+				seen[n] = true
+				receivers = append(receivers, n)
+			}
+		}
+	}
+	for _, route := range r.Routes {
+		if !alerting.MatchAll(route.matchers, a) {
+			continue
+		}
+		add(route.Receivers)
+		if !route.Continue {
+			return receivers
+		}
+	}
+	if len(receivers) == 0 {
+		add(r.DefaultReceivers)
+	}
+	return receivers
+}
@@ -0,0 +1,124 @@
+// Загрузка правил алертов: встроенные (или из ALERT_RULES_FILE) плюс таблица alert_rules.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
+)
+
+// defaultRulesReload — как часто перечитывать правила из таблицы alert_rules
+const defaultRulesReload = time.Minute
+
+// RuleStore — источник правил из БД (реализуется database.PostgresDB).
+type RuleStore interface {
+	GetAlertRules(ctx context.Context) ([]database.AlertRuleRecord, error)
+}
+
+// loadRules собирает правила: файл (или встроенные), затем таблица — правило с тем же именем заменяется.
+// Все правила проверяются; при ошибке не возвращается ничего.
+func loadRules(ctx context.Context, store RuleStore, file string) ([]adapters.Rule, error) {
+	rules := adapters.DefaultRules()
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if rules, err = adapters.ParseRules(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+	}
+
+	records, err := store.GetAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("alert_rules: %w", err)
+	}
+	index := make(map[string]int, len(rules))
+	for i, r := range rules {
+		index[r.Name] = i
+	}
+	for _, rec := range records {
+		r, err := ruleFromRecord(rec)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[r.Name]; ok {
+			rules[i] = r
+			continue
+		}
+		index[r.Name] = len(rules)
+		rules = append(rules, r)
+	}
+
+	if err := adapters.ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ruleFromRecord конвертирует строку alert_rules в правило.
+func ruleFromRecord(rec database.AlertRuleRecord) (adapters.Rule, error) {
+	r := adapters.Rule{
+		Name:      rec.Name,
+		AlertType: tr181.AlertType(rec.AlertType),
+		Condition: rec.Condition,
+		Threshold: rec.Threshold,
+		Severity:  tr181.AlertSeverity(rec.Severity),
+		For:       time.Duration(rec.ForSeconds) * time.Second,
+		Count:     rec.Count,
+		Samples:   rec.Samples,
+		Clear:     rec.Clear,
+		ClearFor:  time.Duration(rec.ClearFor) * time.Second,
+		Disabled:  !rec.Enabled,
+	}
+	for _, m := range rec.Metrics {
+		r.Metrics = append(r.Metrics, tr181.MetricType(m))
+	}
+	if len(rec.Severities) > 0 {
+		if err := json.Unmarshal(rec.Severities, &r.Severities); err != nil {
+			return r, fmt.Errorf("rule %q: invalid severities: %w", rec.Name, err)
+		}
+	}
+	return r, nil
+}
+
+// rulesReloadInterval читает ALERT_RULES_RELOAD (0 — не перечитывать).
+func rulesReloadInterval() time.Duration {
+	v := os.Getenv("ALERT_RULES_RELOAD")
+	if v == "" {
+		return defaultRulesReload
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d < 0 {
+		log.Printf("invalid ALERT_RULES_RELOAD %q, using %s", v, defaultRulesReload)
+		return defaultRulesReload
+	}
+	return d
+}
+
+// reloadRules периодически перечитывает правила; ошибочные определения не применяются
+// (остаются предыдущие правила).
+func reloadRules(ctx context.Context, handler *AlertHandler, store RuleStore, file string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			rules, err := loadRules(ctx, store, file)
+			if err != nil {
+				log.Printf("reload rules: %v (keeping previous rules)", err)
+				continue
+			}
+			handler.SetRules(rules)
+		}
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/alert-processor/adapters"
 )
 
 // AlertStorage обёртка над PostgresDB для алертов.
@@ -18,7 +20,33 @@ func NewAlertStorage(db *database.PostgresDB) *AlertStorage {
 	return &AlertStorage{db: db}
 }
 
-// Save сохраняет один алерт в БД.
-func (s *AlertStorage) Save(ctx context.Context, serialNumber, alertType string, value int, ts time.Time) error {
-	return s.db.SaveAlert(ctx, serialNumber, alertType, value, ts)
+// Save добавляет нарушающий сэмпл в открытый инцидент (или открывает его); отдельной строкой в alerts
+// он не сохраняется. suppressedBy — тишина или окно обслуживания, подавившие уведомление (пусто — не подавлено).
+func (s *AlertStorage) Save(ctx context.Context, device *tr181.TR181Device, r adapters.AlertResult, suppressedBy string) error {
+	return s.db.UpsertIncident(ctx, database.IncidentSample{
+		SerialNumber: device.SerialNumber,
+		AlertType:    string(r.Type),
+		Severity:     string(r.Severity),
+		Metric:       string(r.Metric),
+		Value:        r.Value,
+		Below:        r.Below,
+		Timestamp:    device.Timestamp,
+		SuppressedBy: suppressedBy,
+	})
+}
+
+// SaveEvent сохраняет алерт-событие (например device-rebooted) без инцидента.
+func (s *AlertStorage) SaveEvent(ctx context.Context, serialNumber string, ts time.Time, r adapters.AlertResult, suppressedBy string) error {
+	return s.db.SaveAlert(ctx, serialNumber, string(r.Type), string(r.Severity), string(r.Metric), r.Value, ts, suppressedBy)
+}
+
+// CloseIncidentWithEvent закрывает открытый инцидент alertType и сохраняет событие r одной командой;
+// возвращает уровень закрытого инцидента (пусто — не было открытого).
+func (s *AlertStorage) CloseIncidentWithEvent(ctx context.Context, serialNumber, alertType string, ts time.Time, r adapters.AlertResult, suppressedBy string) (string, error) {
+	return s.db.CloseIncidentWithEvent(ctx, serialNumber, alertType, ts, string(r.Type), string(r.Severity), r.Value, suppressedBy)
+}
+
+// CloseIncident закрывает открытый инцидент устройства и возвращает его уровень (пусто — не было открытого).
+func (s *AlertStorage) CloseIncident(ctx context.Context, serialNumber, alertType string, endedAt time.Time) (string, error) {
+	return s.db.CloseIncident(ctx, serialNumber, alertType, endedAt)
 }
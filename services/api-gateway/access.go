@@ -0,0 +1,95 @@
+// Авторизация запросов к устройствам и управление группами устройств (только для администраторов).
+package main
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+// maxGroupMembers — максимум устройств в одном запросе добавления в группу
+const maxGroupMembers = 1000
+
+// Действия для журнала аудита
+const (
+	actionMetricRead = "metric:read"
+	actionAlertRead  = "alert:read"
+	actionStateRead  = "state:read"
+	actionExport     = "metric:export"
+	actionManageKeys = "keys:manage"
+	actionManageGrps = "device-groups:manage"
+	actionSilences   = "silences:manage"
+	actionMaint      = "maintenance:manage"
+)
+
+// addGroupMembersRequest — тело POST /api/v1/device-groups/:group/devices
+type addGroupMembersRequest struct {
+	SerialNumbers []string `json:"serial_numbers"`
+}
+
+// addGroupMembersHandler - добавляет устройства в группу (группа создаётся с первым устройством)
+func addGroupMembersHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req addGroupMembersRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "body", "invalid request body"), "")
+			return
+		}
+		serials := make([]string, 0, len(req.SerialNumbers))
+		for _, sn := range req.SerialNumbers {
+			if sn = strings.TrimSpace(sn); sn != "" {
+				serials = append(serials, sn)
+			}
+		}
+		if len(serials) == 0 {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_MISSING_PARAMETER, "serial_numbers", "serial_numbers is required"), "")
+			return
+		}
+		if len(serials) > maxGroupMembers {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "serial_numbers", "too many serial numbers: max %d", maxGroupMembers), "")
+			return
+		}
+
+		if err := postgresDB.AddDeviceGroupMembers(c.Request.Context(), c.Param("group"), serials); err != nil {
+			writeError(c, err, "failed to update device group")
+			return
+		}
+		authz.InvalidateGroups()
+		notifyPolicyChanged(c.Request.Context(), redisCache) // группы используются окнами обслуживания
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// listGroupMembersHandler - устройства группы
+func listGroupMembersHandler(postgresDB *database.PostgresDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		serials, err := postgresDB.GetDeviceGroupMembers(c.Request.Context(), c.Param("group"))
+		if err != nil {
+			writeError(c, err, "failed to get device group")
+			return
+		}
+		c.JSON(http.StatusOK, serials)
+	}
+}
+
+// removeGroupMemberHandler - удаляет устройство из группы
+func removeGroupMemberHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		removed, err := postgresDB.RemoveDeviceGroupMember(c.Request.Context(), c.Param("group"), c.Param("serialNumber"))
+		if err != nil {
+			writeError(c, err, "failed to update device group")
+			return
+		}
+		if !removed {
+			writeError(c, notFound("device is not in group"), "")
+			return
+		}
+		authz.InvalidateGroups()
+		notifyPolicyChanged(c.Request.Context(), redisCache) // группы используются окнами обслуживания
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,66 @@
+// Модель аномалий устройства (EWMA среднего и дисперсии, обновляет alert-processor): HTTP и gRPC
+// обработчики поверх Redis.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+// AnomalyModel возвращает текущую модель метрики устройства; модели нет — ошибка NOT_FOUND
+func (s *queryService) AnomalyModel(ctx context.Context, serialNumber, metricType string) (*database.AnomalyModel, error) {
+	if serialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if !isValidMetricType(tr181.MetricType(metricType)) {
+		return nil, invalidMetricType(metricType)
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionMetricRead, serialNumber); err != nil {
+		return nil, err
+	}
+
+	model, err := s.redisCache.GetAnomalyModel(ctx, metricType, serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get anomaly model: %w", err)
+	}
+	if model == nil {
+		return nil, notFound("no anomaly model for %s on %s", metricType, serialNumber)
+	}
+	return model, nil
+}
+
+// GetAnomalyModel - gRPC метод получения модели аномалий
+func (s *apiServer) GetAnomalyModel(ctx context.Context, req *tr181pb.AnomalyModelRequest) (*tr181pb.AnomalyModel, error) {
+	model, err := s.svc.AnomalyModel(ctx, req.SerialNumber, req.MetricType)
+	if err != nil {
+		return nil, grpcError(err, "failed to get anomaly model")
+	}
+	return &tr181pb.AnomalyModel{
+		SerialNumber: model.SerialNumber,
+		MetricType:   model.MetricType,
+		Mean:         model.Mean,
+		Stddev:       model.StdDev,
+		Samples:      int64(model.Samples),
+		UpdatedAt:    model.UpdatedAt.Unix(),
+	}, nil
+}
+
+// getAnomalyModelHandler - HTTP обработчик модели аномалий
+// (GET /api/v1/metric/:metricType/anomaly-model?serial-number=)
+func getAnomalyModelHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		model, err := svc.AnomalyModel(c.Request.Context(), c.Query("serial-number"), c.Param("metricType"))
+		if err != nil {
+			writeError(c, err, "failed to get anomaly model")
+			return
+		}
+		c.JSON(http.StatusOK, model)
+	}
+}
@@ -0,0 +1,221 @@
+// Package auth — аутентификация клиентов api-gateway: API-ключи (хэш в PostgreSQL)
+// и JWT bearer токены (ключи подписи из JWKS файла). Общий код для HTTP (Gin) и gRPC.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-test-dev/pkg/database"
+)
+
+const (
+	// APIKeyPrefix — префикс всех выдаваемых API-ключей (отличает их от JWT в Authorization: Bearer)
+	APIKeyPrefix = "tr181_"
+	// keyCacheTTL — сколько проверенный ключ живёт в памяти (задержка применения отзыва ключа)
+	keyCacheTTL = 30 * time.Second
+)
+
+// ErrUnauthenticated — нет учётных данных или они недействительны
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Principal — аутентифицированный клиент
+type Principal struct {
+	ID     string   // apikey:<id> или subject JWT
+	Name   string   // имя ключа или subject
+	Method string   // apikey или jwt
+	Role   string   // viewer, operator или admin (см. authz.go)
+	Scopes []string // доступные устройства: group:<имя>, pattern:<glob> или *
+}
+
+// KeyStore — хранилище API-ключей (реализуется database.PostgresDB)
+type KeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*database.APIKey, error)
+}
+
+// Reflection — режимы доступа к gRPC reflection
+const (
+	ReflectionOff           = "off"           // reflection не регистрируется
+	ReflectionAuthenticated = "authenticated" // только для аутентифицированных клиентов
+	ReflectionOn            = "on"            // для всех
+)
+
+// Config — настройки аутентификации из переменных окружения
+type Config struct {
+	APIKeys    bool   // принимать API-ключи (AUTH_MODE содержит apikey)
+	JWT        bool   // принимать JWT (AUTH_MODE содержит jwt)
+	JWKSFile   string // AUTH_JWKS_FILE — JSON Web Key Set с публичными ключами
+	Issuer     string // AUTH_JWT_ISSUER — ожидаемый iss (пусто — не проверяется)
+	Audience   string // AUTH_JWT_AUDIENCE — ожидаемый aud (пусто — не проверяется)
+	AdminKey   string // AUTH_ADMIN_KEY — начальный ключ администратора для выпуска остальных ключей
+	Reflection string // GRPC_REFLECTION — off | authenticated | on
+}
+
+// LoadConfig читает настройки: AUTH_MODE=none|apikey|jwt|apikey,jwt
+func LoadConfig() Config {
+	cfg := Config{
+		JWKSFile:   os.Getenv("AUTH_JWKS_FILE"),
+		Issuer:     os.Getenv("AUTH_JWT_ISSUER"),
+		Audience:   os.Getenv("AUTH_JWT_AUDIENCE"),
+		AdminKey:   os.Getenv("AUTH_ADMIN_KEY"),
+		Reflection: strings.ToLower(os.Getenv("GRPC_REFLECTION")),
+	}
+	for _, mode := range strings.Split(strings.ToLower(os.Getenv("AUTH_MODE")), ",") {
+		switch strings.TrimSpace(mode) {
+		case "apikey":
+			cfg.APIKeys = true
+		case "jwt":
+			cfg.JWT = true
+		}
+	}
+	if cfg.Reflection == "" {
+		cfg.Reflection = ReflectionAuthenticated
+	}
+	return cfg
+}
+
+// Enabled — включена ли аутентификация хотя бы одним способом
+func (c Config) Enabled() bool {
+	return c.APIKeys || c.JWT
+}
+
+// Authenticator проверяет учётные данные. nil — аутентификация выключена (всё разрешено)
+type Authenticator struct {
+	cfg          Config
+	store        KeyStore
+	jwks         *jwksSource
+	adminKeyHash string
+
+	mu       sync.Mutex
+	keyCache map[string]keyCacheEntry // хэш ключа → проверенный principal
+}
+
+// keyCacheEntry — закэшированный результат проверки API-ключа
+type keyCacheEntry struct {
+	principal *Principal
+	expires   time.Time
+}
+
+// New создаёт Authenticator. При выключенной аутентификации возвращает nil, nil
+func New(cfg Config, store KeyStore) (*Authenticator, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+	switch cfg.Reflection {
+	case ReflectionOff, ReflectionAuthenticated, ReflectionOn:
+	default:
+		return nil, fmt.Errorf("invalid GRPC_REFLECTION %q: use off, authenticated or on", cfg.Reflection)
+	}
+
+	a := &Authenticator{cfg: cfg, store: store, keyCache: make(map[string]keyCacheEntry)}
+	if cfg.JWT {
+		if cfg.JWKSFile == "" {
+			return nil, fmt.Errorf("AUTH_JWKS_FILE is required for jwt authentication")
+		}
+		src, err := newJWKSSource(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = src
+	}
+	if cfg.AdminKey != "" {
+		a.adminKeyHash = HashAPIKey(cfg.AdminKey)
+	}
+	return a, nil
+}
+
+// ReflectionMode — режим gRPC reflection (без аутентификации reflection доступна всем)
+func (a *Authenticator) ReflectionMode() string {
+	if a == nil {
+		return ReflectionOn
+	}
+	return a.cfg.Reflection
+}
+
+// Authenticate проверяет API-ключ (apiKey) или bearer токен (bearer: API-ключ или JWT)
+func (a *Authenticator) Authenticate(ctx context.Context, apiKey, bearer string) (*Principal, error) {
+	switch {
+	case apiKey != "":
+		return a.authenticateAPIKey(ctx, apiKey)
+	case strings.HasPrefix(bearer, APIKeyPrefix):
+		return a.authenticateAPIKey(ctx, bearer)
+	case bearer != "":
+		if !a.cfg.JWT {
+			return nil, fmt.Errorf("%w: jwt authentication is disabled", ErrUnauthenticated)
+		}
+		return a.jwks.authenticate(bearer, a.cfg.Issuer, a.cfg.Audience)
+	}
+	return nil, fmt.Errorf("%w: missing credentials", ErrUnauthenticated)
+}
+
+// authenticateAPIKey проверяет API-ключ: начальный ключ администратора, кэш, затем БД
+func (a *Authenticator) authenticateAPIKey(ctx context.Context, key string) (*Principal, error) {
+	if !a.cfg.APIKeys {
+		return nil, fmt.Errorf("%w: api key authentication is disabled", ErrUnauthenticated)
+	}
+	hash := HashAPIKey(key)
+	if a.adminKeyHash != "" && subtle.ConstantTimeCompare([]byte(hash), []byte(a.adminKeyHash)) == 1 {
+		return &Principal{ID: "apikey:admin", Name: "admin", Method: "apikey", Role: RoleAdmin}, nil
+	}
+
+	a.mu.Lock()
+	entry, ok := a.keyCache[hash]
+	a.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.principal, nil
+	}
+
+	stored, err := a.store.GetAPIKeyByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("lookup api key: %w", err)
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("%w: invalid api key", ErrUnauthenticated)
+	}
+	p := &Principal{ID: fmt.Sprintf("apikey:%d", stored.ID), Name: stored.Name, Method: "apikey",
+		Role: stored.Role, Scopes: stored.Scopes}
+
+	a.mu.Lock()
+	a.keyCache[hash] = keyCacheEntry{principal: p, expires: time.Now().Add(keyCacheTTL)}
+	a.mu.Unlock()
+	return p, nil
+}
+
+// GenerateAPIKey создаёт новый ключ: возвращает сам ключ (показывается один раз), префикс и хэш для БД
+func GenerateAPIKey() (key, prefix, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:len(APIKeyPrefix)+6], HashAPIKey(key), nil
+}
+
+// HashAPIKey — SHA-256 ключа в hex (ключи случайные 256 бит, медленный хэш не нужен)
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+type principalKey struct{}
+
+// WithPrincipal добавляет principal в контекст
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext возвращает principal из контекста (nil — аутентификация выключена)
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
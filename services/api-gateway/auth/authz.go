@@ -0,0 +1,237 @@
+// Авторизация: роли (viewer < operator < admin) и области доступа к устройствам
+// (группы устройств и шаблоны серийных номеров). Отказы пишутся в журнал аудита.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-test-dev/pkg/database"
+)
+
+// Роли. Каждая следующая включает права предыдущей
+const (
+	RoleViewer   = "viewer"   // чтение метрик, алертов и состояния своих устройств
+	RoleOperator = "operator" // viewer + управление алертами своих устройств
+	RoleAdmin    = "admin"    // все устройства, управление ключами и группами
+)
+
+// roleRank — порядок ролей; неизвестная роль имеет ранг 0 (нет прав)
+var roleRank = map[string]int{RoleViewer: 1, RoleOperator: 2, RoleAdmin: 3}
+
+const (
+	// ScopeAll — доступ ко всем устройствам
+	ScopeAll = "*"
+	// groupCacheTTL — сколько членство устройства в группах живёт в памяти
+	groupCacheTTL = time.Minute
+	// groupCacheMax — при превышении кэш групп сбрасывается целиком
+	groupCacheMax = 100000
+)
+
+// ErrPermissionDenied — principal не имеет доступа к действию или устройству
+var ErrPermissionDenied = errors.New("permission denied")
+
+// ValidRole — известна ли роль
+func ValidRole(role string) bool {
+	return roleRank[role] > 0
+}
+
+// ValidateScope проверяет формат области доступа: *, group:<имя> или pattern:<glob>
+func ValidateScope(scope string) error {
+	switch {
+	case scope == ScopeAll:
+		return nil
+	case strings.HasPrefix(scope, "group:") && len(scope) > len("group:"):
+		return nil
+	case strings.HasPrefix(scope, "pattern:"):
+		if _, err := path.Match(strings.TrimPrefix(scope, "pattern:"), ""); err != nil {
+			return fmt.Errorf("invalid scope %q: %v", scope, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("invalid scope %q: use *, group:<name> or pattern:<glob>", scope)
+}
+
+// HasRole — есть ли у principal роль не ниже role
+func (p *Principal) HasRole(role string) bool {
+	return p != nil && roleRank[p.Role] >= roleRank[role]
+}
+
+// GroupStore — членство устройств в группах (реализуется database.PostgresDB)
+type GroupStore interface {
+	GetDeviceGroups(ctx context.Context, serialNumber string) ([]string, error)
+}
+
+// AuditStore — журнал аудита (реализуется database.PostgresDB)
+type AuditStore interface {
+	SaveAuditRecord(ctx context.Context, rec database.AuditRecord) error
+}
+
+// Authorizer проверяет права principal из контекста. nil — аутентификация выключена (всё разрешено)
+type Authorizer struct {
+	groups GroupStore
+	audit  AuditStore
+
+	mu         sync.Mutex
+	groupCache map[string]groupCacheEntry // серийный номер → группы
+}
+
+// groupCacheEntry — закэшированные группы устройства
+type groupCacheEntry struct {
+	groups  []string
+	expires time.Time
+}
+
+// NewAuthorizer создаёт Authorizer для включённой аутентификации (authn != nil), иначе nil
+func NewAuthorizer(authn *Authenticator, groups GroupStore, audit AuditStore) *Authorizer {
+	if authn == nil {
+		return nil
+	}
+	return &Authorizer{groups: groups, audit: audit, groupCache: make(map[string]groupCacheEntry)}
+}
+
+// AuthorizeRole проверяет, что у principal есть роль не ниже role
+func (z *Authorizer) AuthorizeRole(ctx context.Context, role, action string) error {
+	if z == nil {
+		return nil
+	}
+	p := PrincipalFromContext(ctx)
+	if p.HasRole(role) {
+		return nil
+	}
+	return z.deny(ctx, p, action, "api", fmt.Sprintf("role %s required", role))
+}
+
+// AuthorizeDevices проверяет роль и доступ principal ко всем устройствам serialNumbers.
+// Отказ возвращает ошибку с ErrPermissionDenied и пишет запись в журнал аудита
+func (z *Authorizer) AuthorizeDevices(ctx context.Context, role, action string, serialNumbers ...string) error {
+	if z == nil {
+		return nil
+	}
+	p := PrincipalFromContext(ctx)
+	if !p.HasRole(role) {
+		return z.deny(ctx, p, action, "api", fmt.Sprintf("role %s required", role))
+	}
+	for _, sn := range serialNumbers {
+		ok, err := z.deviceAllowed(ctx, p, sn)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return z.deny(ctx, p, action, "device:"+sn, "device is out of scope")
+		}
+	}
+	return nil
+}
+
+// FilterDevices оставляет только доступные principal устройства (без записи в аудит):
+// для запросов по шаблонам, где чужие устройства просто не должны попадать в ответ
+func (z *Authorizer) FilterDevices(ctx context.Context, serialNumbers []string) ([]string, error) {
+	if z == nil {
+		return serialNumbers, nil
+	}
+	p := PrincipalFromContext(ctx)
+	if !p.HasRole(RoleViewer) {
+		return nil, nil
+	}
+	allowed := make([]string, 0, len(serialNumbers))
+	for _, sn := range serialNumbers {
+		ok, err := z.deviceAllowed(ctx, p, sn)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			allowed = append(allowed, sn)
+		}
+	}
+	return allowed, nil
+}
+
+// deviceAllowed — входит ли устройство в области доступа principal (admin — все устройства)
+func (z *Authorizer) deviceAllowed(ctx context.Context, p *Principal, serialNumber string) (bool, error) {
+	if p.HasRole(RoleAdmin) {
+		return true, nil
+	}
+	var groups []string
+	groupsLoaded := false
+	for _, scope := range p.Scopes {
+		switch {
+		case scope == ScopeAll:
+			return true, nil
+		case strings.HasPrefix(scope, "pattern:"):
+			if ok, _ := path.Match(strings.TrimPrefix(scope, "pattern:"), serialNumber); ok {
+				return true, nil
+			}
+		case strings.HasPrefix(scope, "group:"):
+			if !groupsLoaded {
+				var err error
+				if groups, err = z.deviceGroups(ctx, serialNumber); err != nil {
+					return false, err
+				}
+				groupsLoaded = true
+			}
+			for _, g := range groups {
+				if g == strings.TrimPrefix(scope, "group:") {
+					return true, nil
+				}
+			}
+		}
+	}
+	return false, nil
+}
+
+// deviceGroups возвращает группы устройства (с кэшем в памяти)
+func (z *Authorizer) deviceGroups(ctx context.Context, serialNumber string) ([]string, error) {
+	z.mu.Lock()
+	entry, ok := z.groupCache[serialNumber]
+	z.mu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.groups, nil
+	}
+
+	groups, err := z.groups.GetDeviceGroups(ctx, serialNumber)
+	if err != nil {
+		return nil, fmt.Errorf("lookup device groups: %w", err)
+	}
+
+	z.mu.Lock()
+	if len(z.groupCache) >= groupCacheMax {
+		z.groupCache = make(map[string]groupCacheEntry)
+	}
+	z.groupCache[serialNumber] = groupCacheEntry{groups: groups, expires: time.Now().Add(groupCacheTTL)}
+	z.mu.Unlock()
+	return groups, nil
+}
+
+// InvalidateGroups сбрасывает кэш групп после изменения состава групп
+func (z *Authorizer) InvalidateGroups() {
+	if z == nil {
+		return
+	}
+	z.mu.Lock()
+	z.groupCache = make(map[string]groupCacheEntry)
+	z.mu.Unlock()
+}
+
+// deny пишет отказ в журнал аудита и возвращает ошибку ErrPermissionDenied
+func (z *Authorizer) deny(ctx context.Context, p *Principal, action, resource, reason string) error {
+	rec := database.AuditRecord{PrincipalID: "anonymous", PrincipalName: "anonymous", Action: action, Resource: resource, Reason: reason}
+	if p != nil {
+		rec.PrincipalID, rec.PrincipalName = p.ID, p.Name
+	}
+	log.Printf("authz: denied %s %s to %s: %s", action, resource, rec.PrincipalID, reason)
+
+	// Аудит не должен зависеть от отмены клиентского запроса
+	auditCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 2*time.Second)
+	defer cancel()
+	if err := z.audit.SaveAuditRecord(auditCtx, rec); err != nil {
+		log.Printf("authz: save audit record: %v", err)
+	}
+	return fmt.Errorf("%w: %s", ErrPermissionDenied, reason)
+}
@@ -0,0 +1,79 @@
+// gRPC interceptors: учётные данные из metadata authorization: Bearer или x-api-key.
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// reflectionMethodPrefix — методы сервиса gRPC reflection (v1 и v1alpha)
+const reflectionMethodPrefix = "/grpc.reflection."
+
+// UnaryInterceptor проверяет учётные данные unary вызовов
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticateGRPC(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamInterceptor проверяет учётные данные потоковых вызовов (в том числе reflection)
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateGRPC(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &principalStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// authenticateGRPC возвращает контекст с principal или ошибку Unauthenticated
+func (a *Authenticator) authenticateGRPC(ctx context.Context, method string) (context.Context, error) {
+	if a == nil {
+		return ctx, nil
+	}
+	if strings.HasPrefix(method, reflectionMethodPrefix) && a.cfg.Reflection == ReflectionOn {
+		return ctx, nil // reflection открыта для всех
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	bearer := ""
+	if v := md.Get("authorization"); len(v) > 0 && strings.HasPrefix(v[0], "Bearer ") {
+		bearer = strings.TrimSpace(strings.TrimPrefix(v[0], "Bearer "))
+	}
+	apiKey := ""
+	if v := md.Get("x-api-key"); len(v) > 0 {
+		apiKey = v[0]
+	}
+
+	p, err := a.Authenticate(ctx, apiKey, bearer)
+	if err != nil {
+		if errors.Is(err, ErrUnauthenticated) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		log.Printf("auth: %v", err)
+		return nil, status.Error(codes.Unavailable, "authentication unavailable")
+	}
+	return WithPrincipal(ctx, p), nil
+}
+
+// principalStream подменяет контекст потока контекстом с principal
+type principalStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *principalStream) Context() context.Context {
+	return s.ctx
+}
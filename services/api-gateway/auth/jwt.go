@@ -0,0 +1,228 @@
+// JWT: проверка подписи ключами из JWKS файла (RSA, EC, Ed25519) с перечиткой файла при изменении.
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwksCheckInterval — как часто проверять, не изменился ли JWKS файл (ротация ключей)
+const jwksCheckInterval = time.Minute
+
+// jwtMethods — допустимые алгоритмы подписи (none и HMAC не принимаются)
+var jwtMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+
+// jwk — ключ из JSON Web Key Set (только поля публичных ключей)
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// jwksSource — ключи подписи из файла; перечитывается при изменении mtime
+type jwksSource struct {
+	path string
+
+	mu        sync.RWMutex
+	keys      map[string]crypto.PublicKey // kid → ключ
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+// newJWKSSource загружает JWKS файл; ошибка — если файл не читается или в нём нет ключей
+func newJWKSSource(path string) (*jwksSource, error) {
+	s := &jwksSource{path: path}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load читает и разбирает файл
+func (s *jwksSource) load() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return fmt.Errorf("jwks: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue // ключи шифрования не нужны
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			return fmt.Errorf("jwks: key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = pub
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("jwks: no signing keys in %s", s.path)
+	}
+
+	s.mu.Lock()
+	s.keys, s.modTime, s.checkedAt = keys, info.ModTime(), time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// refresh перечитывает файл, если он изменился (не чаще jwksCheckInterval)
+func (s *jwksSource) refresh() {
+	s.mu.RLock()
+	due := time.Since(s.checkedAt) > jwksCheckInterval
+	modTime := s.modTime
+	s.mu.RUnlock()
+	if !due {
+		return
+	}
+
+	info, err := os.Stat(s.path)
+	if err == nil && !info.ModTime().Equal(modTime) {
+		if err := s.load(); err != nil {
+			log.Printf("auth: reload jwks: %v (keeping previous keys)", err)
+		}
+		return
+	}
+	s.mu.Lock()
+	s.checkedAt = time.Now()
+	s.mu.Unlock()
+}
+
+// key возвращает ключ по kid; без kid — единственный ключ набора
+func (s *jwksSource) key(kid string) (crypto.PublicKey, error) {
+	s.refresh()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if kid == "" && len(s.keys) == 1 {
+		for _, k := range s.keys {
+			return k, nil
+		}
+	}
+	k, ok := s.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return k, nil
+}
+
+// jwtClaims — стандартные claims плюс роли и области доступа
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"device_scopes"`
+}
+
+// authenticate проверяет подпись, срок действия, iss и aud токена
+func (s *jwksSource) authenticate(token, issuer, audience string) (*Principal, error) {
+	opts := []jwt.ParserOption{jwt.WithValidMethods(jwtMethods), jwt.WithExpirationRequired()}
+	if issuer != "" {
+		opts = append(opts, jwt.WithIssuer(issuer))
+	}
+	if audience != "" {
+		opts = append(opts, jwt.WithAudience(audience))
+	}
+
+	var claims jwtClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return s.key(kid)
+	}, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid token: %v", ErrUnauthenticated, err)
+	}
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+
+	p := &Principal{ID: claims.Subject, Name: claims.Subject, Method: "jwt", Scopes: claims.Scopes}
+	for _, r := range claims.Roles {
+		if roleRank[r] > roleRank[p.Role] {
+			p.Role = r // из нескольких ролей берётся старшая
+		}
+	}
+	return p, nil
+}
+
+// publicKey собирает публичный ключ из параметров JWK
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("invalid Ed25519 key")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeBigInt декодирует base64url число из JWK
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
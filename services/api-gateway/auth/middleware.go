@@ -0,0 +1,53 @@
+// Gin middleware: учётные данные из Authorization: Bearer или X-API-Key.
+// Коды ошибок в ответах — ErrorCode из api/proto/tr181_api.proto.
+package auth
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Middleware проверяет учётные данные запроса и кладёт principal в контекст.
+// На nil Authenticator (аутентификация выключена) пропускает все запросы
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if a == nil {
+			c.Next()
+			return
+		}
+		bearer := ""
+		if h := c.GetHeader("Authorization"); strings.HasPrefix(h, "Bearer ") {
+			bearer = strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+		}
+
+		p, err := a.Authenticate(c.Request.Context(), c.GetHeader("X-API-Key"), bearer)
+		if err != nil {
+			if errors.Is(err, ErrUnauthenticated) {
+				c.Header("WWW-Authenticate", `Bearer realm="tr181"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error(), "code": "UNAUTHENTICATED"})
+				return
+			}
+			log.Printf("auth: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "authentication unavailable", "code": "STORAGE_UNAVAILABLE"})
+			return
+		}
+
+		c.Request = c.Request.WithContext(WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// RequireRole пропускает только principal с ролью не ниже role (отказ — 403 и запись в аудит)
+func (z *Authorizer) RequireRole(role, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := z.AuthorizeRole(c.Request.Context(), role, action); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error(), "code": "PERMISSION_DENIED"})
+			return
+		}
+		c.Next()
+	}
+}
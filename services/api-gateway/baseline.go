@@ -0,0 +1,248 @@
+// Сравнение устройства с парком: ряд устройства по интервалам рядом с перцентилями средних значений
+// всех устройств (или группы) в тех же интервалах и оценка отклонения — для разбора жалоб в поддержке.
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	baselineBuckets   = 96          // число интервалов по умолчанию (24 часа — по 15 минут)
+	minBaselineBucket = time.Minute // минимальный интервал по умолчанию (устройства шлют данные раз в 30 сек)
+	// minBaselineSpread — нижняя граница робастного σ: при почти одинаковых значениях парка
+	// отклонение на единицу метрики не должно давать огромный score
+	minBaselineSpread = 1.0
+)
+
+// baselineQuery — параметры сравнения с парком (нулевое время — значение по умолчанию)
+type baselineQuery struct {
+	SerialNumber string
+	MetricType   string
+	From         time.Time
+	To           time.Time
+	Bucket       time.Duration // 0 — подбирается по периоду
+	Group        string        // группа устройств (пусто — весь парк)
+}
+
+// baselinePoint — интервал: значение устройства, распределение по парку и отклонение
+type baselinePoint struct {
+	Time  int64                  `json:"time"`
+	Value *int                   `json:"value,omitempty"` // нет — у устройства нет данных в интервале
+	Fleet *database.BaselineBand `json:"fleet,omitempty"` // нет — меньше database.MinBaselineDevices устройств
+	Score *float64               `json:"score,omitempty"`
+}
+
+// metricBaselineResult — ответ сравнения с парком
+type metricBaselineResult struct {
+	SerialNumber     string          `json:"serial_number"`
+	MetricType       string          `json:"metric_type"`
+	Group            string          `json:"group,omitempty"`
+	BucketSeconds    int64           `json:"bucket_seconds"`
+	DeviationScore   float64         `json:"deviation_score"`
+	OutsideBandRatio float64         `json:"outside_band_ratio"`
+	ComparedBuckets  int64           `json:"compared_buckets"`
+	Points           []baselinePoint `json:"points"`
+}
+
+// Baseline сравнивает метрику устройства с парком (или группой). Ряд устройства берётся так же, как downsample
+// метрик, базовая линия парка — из PostgreSQL по целым интервалам и кэшируется общей для всех устройств
+func (s *queryService) Baseline(ctx context.Context, q baselineQuery) (*metricBaselineResult, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if !isValidMetricType(tr181.MetricType(q.MetricType)) {
+		return nil, invalidMetricType(q.MetricType)
+	}
+	var err error
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionMetricRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	// Запрос по всему парку тяжелее запроса одного устройства: период ограничен, как для сырых точек
+	rangeLen := q.To.Sub(q.From)
+	if rangeLen > s.limits.MaxRange {
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "from", "time range exceeds maximum of %s", s.limits.MaxRange)
+	}
+	bucket := q.Bucket
+	switch {
+	case bucket == 0:
+		bucket = baselineBucket(rangeLen)
+	case bucket < time.Second:
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "bucket", "bucket must be at least 1s")
+	case int64(rangeLen/bucket) >= int64(s.limits.MaxPoints):
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "bucket", "too many buckets: max %d", s.limits.MaxPoints)
+	}
+	bucket = bucket.Truncate(time.Second)
+
+	series, err := s.downsampledMetrics(ctx, metricQuery{SerialNumber: q.SerialNumber, MetricType: q.MetricType, From: q.From, To: q.To}, bucket)
+	if err != nil {
+		return nil, err
+	}
+	fleet, err := s.fleetBaseline(ctx, q.MetricType, q.Group, q.From, q.To, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	result := compareBaseline(series.Metrics, fleet)
+	result.SerialNumber = q.SerialNumber
+	result.MetricType = q.MetricType
+	result.Group = q.Group
+	result.BucketSeconds = series.BucketSeconds
+	return result, nil
+}
+
+// fleetBaseline — базовая линия парка за период, расширенный до границ интервалов: крайние интервалы
+// считаются целиком, а ключ кэша не меняется, пока конец периода («сейчас») внутри одного интервала
+func (s *queryService) fleetBaseline(ctx context.Context, metricType, group string, from, to time.Time, bucket time.Duration) ([]database.FleetBaselineBucket, error) {
+	size := int64(bucket.Seconds())
+	start := time.Unix(floorDiv(from.Unix(), size)*size, 0)
+	end := time.Unix((floorDiv(to.Unix(), size)+1)*size, 0)
+
+	cacheKey := fmt.Sprintf("baseline:%s:%s:%d:%d:%d", metricType, group, start.Unix(), end.Unix(), size)
+	return cachedSWR(ctx, s.fill, cacheKey, responseFresh, responseStale, func(ctx context.Context) ([]database.FleetBaselineBucket, error) {
+		buckets, err := s.postgresDB.GetFleetBaseline(ctx, metricType, group, start, end, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fleet baseline: %w", err)
+		}
+		return buckets, nil
+	})
+}
+
+// baselineBucket — интервал по умолчанию: около baselineBuckets интервалов, кратный минуте
+func baselineBucket(rangeLen time.Duration) time.Duration {
+	bucket := (rangeLen / baselineBuckets).Truncate(time.Minute)
+	if bucket < rangeLen/baselineBuckets {
+		bucket += time.Minute
+	}
+	return max(bucket, minBaselineBucket)
+}
+
+// compareBaseline объединяет ряд устройства и базовую линию по интервалам и считает отклонение.
+// score — расстояние от медианы парка в робастных σ (IQR / 1.349), вне полосы — значение ниже p5 или выше p95
+func compareBaseline(series []database.MetricValue, fleet []database.FleetBaselineBucket) *metricBaselineResult {
+	result := &metricBaselineResult{Points: make([]baselinePoint, 0, max(len(series), len(fleet)))}
+	var sum float64
+	var outside int64
+	i, j := 0, 0
+	for i < len(series) || j < len(fleet) {
+		var p baselinePoint
+		switch {
+		case j == len(fleet) || (i < len(series) && series[i].Time < fleet[j].Time):
+			p.Time, p.Value = series[i].Time, &series[i].Value
+			i++
+		case i == len(series) || fleet[j].Time < series[i].Time:
+			p.Time, p.Fleet = fleet[j].Time, &fleet[j].BaselineBand
+			j++
+		default:
+			p.Time, p.Value, p.Fleet = series[i].Time, &series[i].Value, &fleet[j].BaselineBand
+			i++
+			j++
+		}
+
+		if p.Value != nil && p.Fleet != nil {
+			v := float64(*p.Value)
+			sigma := math.Max((p.Fleet.P75-p.Fleet.P25)/1.349, minBaselineSpread)
+			score := (v - p.Fleet.P50) / sigma
+			p.Score = &score
+			sum += score
+			result.ComparedBuckets++
+			if v < p.Fleet.P5 || v > p.Fleet.P95 {
+				outside++
+			}
+		}
+		result.Points = append(result.Points, p)
+	}
+	if result.ComparedBuckets > 0 {
+		result.DeviationScore = sum / float64(result.ComparedBuckets)
+		result.OutsideBandRatio = float64(outside) / float64(result.ComparedBuckets)
+	}
+	return result
+}
+
+// GetMetricBaseline - gRPC метод сравнения метрики устройства с парком
+func (s *apiServer) GetMetricBaseline(ctx context.Context, req *tr181pb.MetricBaselineRequest) (*tr181pb.MetricBaselineResponse, error) {
+	from, to, err := grpcTimeRange(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+
+	result, err := s.svc.Baseline(ctx, baselineQuery{
+		SerialNumber: req.SerialNumber,
+		MetricType:   req.MetricType,
+		From:         from,
+		To:           to,
+		Bucket:       time.Duration(req.BucketSeconds) * time.Second,
+		Group:        req.Group,
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to get metric baseline")
+	}
+
+	resp := &tr181pb.MetricBaselineResponse{
+		Points:           make([]*tr181pb.BaselinePoint, len(result.Points)),
+		BucketSeconds:    result.BucketSeconds,
+		Group:            result.Group,
+		DeviationScore:   result.DeviationScore,
+		OutsideBandRatio: result.OutsideBandRatio,
+		ComparedBuckets:  result.ComparedBuckets,
+	}
+	for i, p := range result.Points {
+		pb := &tr181pb.BaselinePoint{Time: p.Time, Score: p.Score}
+		if p.Value != nil {
+			v := int32(*p.Value)
+			pb.Value = &v
+		}
+		if f := p.Fleet; f != nil {
+			pb.Fleet = &tr181pb.BaselineBand{Devices: f.Devices, P5: f.P5, P25: f.P25, P50: f.P50, P75: f.P75, P95: f.P95}
+		}
+		resp.Points[i] = pb
+	}
+	return resp, nil
+}
+
+// getMetricBaselineHandler - HTTP обработчик сравнения с парком
+// (GET /api/v1/metric/:metricType/baseline?serial-number=&from=&to=&bucket=15m&group=)
+func getMetricBaselineHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, ok := parseTimeRange(c)
+		if !ok {
+			return
+		}
+
+		var bucket time.Duration
+		if v := c.Query("bucket"); v != "" {
+			var err error
+			if bucket, err = time.ParseDuration(v); err != nil {
+				writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "bucket", "invalid bucket parameter"), "")
+				return
+			}
+		}
+
+		result, err := svc.Baseline(c.Request.Context(), baselineQuery{
+			SerialNumber: c.Query("serial-number"),
+			MetricType:   c.Param("metricType"),
+			From:         from,
+			To:           to,
+			Bucket:       bucket,
+			Group:        c.Query("group"),
+		})
+		if err != nil {
+			writeError(c, err, "failed to get metric baseline")
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
@@ -0,0 +1,201 @@
+// Чтение серии метрик через кэш чанков: закрытые часы — из Redis (промахи догружаются из PostgreSQL
+// одним запросом), незакрытый хвост — всегда из PostgreSQL. Ответ собирается из чанков,
+// поэтому запросы с разными from/to (дашборды, «последние 24 часа») используют одни и те же ключи.
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"golang-test-dev/pkg/database"
+)
+
+const (
+	chunkBatch              = 24                 // чанков в одном MGET
+	chunkDownsampleMaxRange = 7 * 24 * time.Hour // до этого периода downsample считается из чанков, дальше — в PostgreSQL
+	tailFresh               = 5 * time.Second    // хвост серии свежий
+	tailStale               = 30 * time.Second   // и ещё столько отдаётся устаревшим, пока обновляется
+)
+
+// scanPoints перебирает точки серии в [from, to] по порядку (timestamp, id), начиная после курсора after (nil — с from).
+// fn возвращает false, чтобы остановить перебор
+func (s *queryService) scanPoints(ctx context.Context, serialNumber, metricType string, from, to time.Time,
+	after *database.MetricCursor, fn func(database.MetricPoint) bool) error {
+	start := from
+	if after != nil && after.Time.After(start) {
+		start = after.Time
+	}
+	// visit — фильтр по периоду и курсору; false — перебор остановлен
+	visit := func(points []database.MetricPoint) bool {
+		for _, p := range points {
+			t := p.Time()
+			if t.Before(from) || t.After(to) {
+				continue
+			}
+			if after != nil && (t.Before(after.Time) || (t.Equal(after.Time) && p.ID <= after.ID)) {
+				continue
+			}
+			if !fn(p) {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Закрытые чанки — из кэша
+	closedEnd := database.ClosedMetricChunksEnd(time.Now())
+	chunkStart := database.MetricChunkStart(start)
+	for chunkStart.Before(closedEnd) && !chunkStart.After(to) {
+		var starts []time.Time
+		for len(starts) < chunkBatch && chunkStart.Before(closedEnd) && !chunkStart.After(to) {
+			starts = append(starts, chunkStart)
+			chunkStart = chunkStart.Add(database.MetricChunkSpan)
+		}
+		chunks, err := s.loadChunks(ctx, serialNumber, metricType, starts)
+		if err != nil {
+			return err
+		}
+		for _, points := range chunks {
+			if !visit(points) {
+				return nil
+			}
+		}
+	}
+	if chunkStart.After(to) {
+		return nil
+	}
+
+	// Незакрытый хвост — общий для всех запросов серии, кэшируется ненадолго
+	tail, err := s.loadTail(ctx, serialNumber, metricType, chunkStart)
+	if err != nil {
+		return err
+	}
+	visit(tail)
+	return nil
+}
+
+// loadTail — точки серии с начала незакрытого хвоста start (не больше часа с небольшим)
+// из кэша stale-while-revalidate (tailFresh, затем tailStale) или PostgreSQL
+func (s *queryService) loadTail(ctx context.Context, serialNumber, metricType string, start time.Time) ([]database.MetricPoint, error) {
+	key := fmt.Sprintf("metric:tail:%s:%s:%d", metricType, serialNumber, start.Unix())
+	return cachedSWR(ctx, s.fill, key, tailFresh, tailStale, func(ctx context.Context) ([]database.MetricPoint, error) {
+		points, err := s.postgresDB.GetMetricPoints(ctx, serialNumber, metricType, start, start.Add(2*database.MetricChunkSpan))
+		if points == nil && err == nil {
+			points = []database.MetricPoint{}
+		}
+		return points, err
+	})
+}
+
+// loadChunks возвращает закрытые чанки по порядку starts: из Redis, промахи — одним запросом к PostgreSQL
+// (один на процесс и, под арендой, на все реплики) с последующим кэшированием.
+// Ошибки Redis не прерывают запрос — данные берутся из PostgreSQL
+func (s *queryService) loadChunks(ctx context.Context, serialNumber, metricType string, starts []time.Time) ([][]database.MetricPoint, error) {
+	chunks, _ := s.redisCache.GetMetricChunks(ctx, metricType, serialNumber, starts) // без Redis — только локальный кэш
+	first, last := -1, -1
+	for i, c := range chunks {
+		if c == nil {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+	if first < 0 {
+		return chunks, nil
+	}
+
+	span := starts[first : last+1]
+	name := fmt.Sprintf("chunks:%s:%s:%d:%d", metricType, serialNumber, span[0].Unix(), span[len(span)-1].Unix())
+	res, err := s.fill.do(ctx, name, func(ctx context.Context) (interface{}, error) {
+		var loaded [][]database.MetricPoint
+		err := s.fill.underLease(ctx, name,
+			func(ctx context.Context) bool {
+				cached, err := s.redisCache.GetMetricChunks(ctx, metricType, serialNumber, span)
+				if err != nil {
+					return false
+				}
+				for _, c := range cached {
+					if c == nil {
+						return false
+					}
+				}
+				loaded = cached
+				return true
+			},
+			func(ctx context.Context) error {
+				var err error
+				loaded, err = s.fetchChunks(ctx, serialNumber, metricType, span)
+				return err
+			})
+		return loaded, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, c := range res.([][]database.MetricPoint) {
+		if chunks[first+i] == nil {
+			chunks[first+i] = c
+		}
+	}
+	return chunks, nil
+}
+
+// fetchChunks читает подряд идущие чанки starts из PostgreSQL одним запросом и кэширует их
+func (s *queryService) fetchChunks(ctx context.Context, serialNumber, metricType string, starts []time.Time) ([][]database.MetricPoint, error) {
+	points, err := s.postgresDB.GetMetricPoints(ctx, serialNumber, metricType, starts[0], starts[len(starts)-1].Add(database.MetricChunkSpan))
+	if err != nil {
+		return nil, err
+	}
+	// Раскладываем точки по чанкам (точки упорядочены по времени)
+	chunks := make([][]database.MetricPoint, len(starts))
+	fill := make([]database.MetricChunk, len(starts))
+	for i, start := range starts {
+		end := start.Add(database.MetricChunkSpan)
+		chunk := []database.MetricPoint{}
+		for len(points) > 0 && points[0].Time().Before(end) {
+			chunk = append(chunk, points[0])
+			points = points[1:]
+		}
+		chunks[i] = chunk
+		fill[i] = database.MetricChunk{Start: start, Points: chunk}
+	}
+	// Чанки, уже лежащие в кэше, SET NX не перезапишет; ошибка кэша не мешает ответу
+	s.redisCache.CacheMetricChunks(ctx, metricType, serialNumber, fill)
+	return chunks, nil
+}
+
+// downsamplePoints усредняет точки по интервалам bucket, выровненным по эпохе (как GetMetricsDownsampled)
+func downsamplePoints(points []database.MetricPoint, bucket time.Duration) []database.MetricValue {
+	size := int64(bucket.Seconds())
+	result := []database.MetricValue{}
+	var cur, sum, n int64
+	flush := func() {
+		if n > 0 {
+			result = append(result, database.MetricValue{Value: int(math.Round(float64(sum) / float64(n))), Time: cur})
+		}
+	}
+	for _, p := range points {
+		b := floorDiv(p.Time().Unix(), size) * size
+		if n > 0 && b != cur {
+			flush()
+			sum, n = 0, 0
+		}
+		cur = b
+		sum += int64(p.Value)
+		n++
+	}
+	flush()
+	return result
+}
+
+// floorDiv — деление с округлением вниз (для времени до 1970 года)
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,226 @@
+// Единая модель ошибок API: код ErrorCode из proto, статус gRPC и HTTP, поля запроса с ошибками.
+// gRPC получает google.rpc.ErrorInfo и google.rpc.BadRequest, HTTP — {"error", "code", "details"}.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// errorDomain — домен google.rpc.ErrorInfo
+const errorDomain = "tr181.api"
+
+// fieldViolation — ошибка в конкретном поле запроса. Field — имя поля proto или JSON тела (serial_number),
+// одинаковое для HTTP и gRPC
+type fieldViolation struct {
+	Field       string
+	Description string
+}
+
+// apiError — ошибка API с машиночитаемым кодом
+type apiError struct {
+	Code       tr181pb.ErrorCode
+	Message    string
+	Violations []fieldViolation
+	HTTPStatus int   // HTTP статус вместо выводимого из кода (0 — по коду)
+	cause      error // исходная ошибка хранилища (в ответ не попадает)
+}
+
+func (e *apiError) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *apiError) Unwrap() error {
+	return e.cause
+}
+
+// Reason — код без префикса ERROR_CODE_ (например MISSING_PARAMETER)
+func (e *apiError) Reason() string {
+	return strings.TrimPrefix(e.Code.String(), "ERROR_CODE_")
+}
+
+// grpcCode — код статуса gRPC для кода ошибки
+func (e *apiError) grpcCode() codes.Code {
+	switch e.Code {
+	case tr181pb.ErrorCode_ERROR_CODE_MISSING_PARAMETER, tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER,
+		tr181pb.ErrorCode_ERROR_CODE_INVALID_METRIC_TYPE, tr181pb.ErrorCode_ERROR_CODE_INVALID_ALERT_TYPE,
+		tr181pb.ErrorCode_ERROR_CODE_INVALID_TIME_RANGE, tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED:
+		return codes.InvalidArgument
+	case tr181pb.ErrorCode_ERROR_CODE_UNAUTHENTICATED:
+		return codes.Unauthenticated
+	case tr181pb.ErrorCode_ERROR_CODE_PERMISSION_DENIED:
+		return codes.PermissionDenied
+	case tr181pb.ErrorCode_ERROR_CODE_NOT_FOUND:
+		return codes.NotFound
+	case tr181pb.ErrorCode_ERROR_CODE_RATE_LIMITED:
+		return codes.ResourceExhausted
+	case tr181pb.ErrorCode_ERROR_CODE_STORAGE_UNAVAILABLE:
+		return codes.Unavailable
+	case tr181pb.ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED:
+		return codes.DeadlineExceeded
+	}
+	return codes.Internal
+}
+
+// httpStatus — HTTP статус для кода ошибки
+func (e *apiError) httpStatus() int {
+	if e.HTTPStatus != 0 {
+		return e.HTTPStatus
+	}
+	switch e.grpcCode() {
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCStatus — статус с ErrorInfo и BadRequest (status.FromError находит его через этот метод)
+func (e *apiError) GRPCStatus() *status.Status {
+	st := status.New(e.grpcCode(), e.Message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{Reason: e.Reason(), Domain: errorDomain}}
+	if len(e.Violations) > 0 {
+		br := &errdetails.BadRequest{}
+		for _, v := range e.Violations {
+			br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       v.Field,
+				Description: v.Description,
+			})
+		}
+		details = append(details, br)
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		return withDetails
+	}
+	return st
+}
+
+// badRequest — ошибка валидации поля field
+func badRequest(code tr181pb.ErrorCode, field, format string, args ...interface{}) *apiError {
+	msg := fmt.Sprintf(format, args...)
+	return &apiError{Code: code, Message: msg, Violations: []fieldViolation{{Field: field, Description: msg}}}
+}
+
+// missingParameter — не задан обязательный параметр field (в сообщении — имя параметра HTTP запроса)
+func missingParameter(field string) *apiError {
+	return badRequest(tr181pb.ErrorCode_ERROR_CODE_MISSING_PARAMETER, field, "%s is required", httpField(field))
+}
+
+// invalidMetricType — неизвестный тип метрики
+func invalidMetricType(metricType string) *apiError {
+	return badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_METRIC_TYPE, "metric_type", "invalid metric type %q", metricType)
+}
+
+// invalidAlertType — неизвестный тип алерта
+func invalidAlertType(alertType string) *apiError {
+	return badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_ALERT_TYPE, "alert_type", "invalid alert type %q", alertType)
+}
+
+// notAcceptable — запрошенный формат ответа не поддерживается (HTTP 406, в gRPC — InvalidArgument)
+func notAcceptable(field, format string, args ...interface{}) *apiError {
+	e := badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, field, format, args...)
+	e.HTTPStatus = http.StatusNotAcceptable
+	return e
+}
+
+// notFound — данные не найдены
+func notFound(format string, args ...interface{}) *apiError {
+	return &apiError{Code: tr181pb.ErrorCode_ERROR_CODE_NOT_FOUND, Message: fmt.Sprintf(format, args...)}
+}
+
+// toAPIError классифицирует ошибку: apiError как есть, отказ доступа, таймаут, недоступность хранилища.
+// Прочие ошибки — INTERNAL с общим сообщением op (подробности только в логе)
+func toAPIError(err error, op string) *apiError {
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, auth.ErrPermissionDenied):
+		return &apiError{Code: tr181pb.ErrorCode_ERROR_CODE_PERMISSION_DENIED, Message: err.Error()}
+	case database.IsTimeout(err):
+		return &apiError{Code: tr181pb.ErrorCode_ERROR_CODE_DEADLINE_EXCEEDED, Message: op + ": deadline exceeded", cause: err}
+	case database.IsUnavailable(err):
+		return &apiError{Code: tr181pb.ErrorCode_ERROR_CODE_STORAGE_UNAVAILABLE, Message: op + ": storage unavailable", cause: err}
+	}
+	return &apiError{Code: tr181pb.ErrorCode_ERROR_CODE_INTERNAL, Message: op, cause: err}
+}
+
+// writeError отправляет HTTP ответ с ошибкой. op — что делал обработчик ("failed to get metrics")
+func writeError(c *gin.Context, err error, op string) {
+	if errors.Is(err, context.Canceled) || status.Code(err) == codes.Canceled {
+		c.AbortWithStatus(499) // клиент закрыл соединение (как в nginx), отвечать некому
+		return
+	}
+	apiErr := toAPIError(err, op)
+	if apiErr.cause != nil {
+		log.Printf("%s %s: %v", c.Request.Method, c.FullPath(), apiErr)
+	}
+	body := gin.H{"error": apiErr.Message, "code": apiErr.Reason()}
+	if len(apiErr.Violations) > 0 {
+		details := make([]gin.H, len(apiErr.Violations))
+		for i, v := range apiErr.Violations {
+			details[i] = gin.H{"field": v.Field, "description": v.Description}
+		}
+		body["details"] = details
+	}
+	c.AbortWithStatusJSON(apiErr.httpStatus(), body)
+}
+
+// grpcError возвращает ошибку gRPC со статусом по модели ошибок
+func grpcError(err error, op string) error {
+	if _, ok := status.FromError(err); ok {
+		return err // уже статус (в том числе apiError)
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+	apiErr := toAPIError(err, op)
+	if apiErr.cause != nil {
+		log.Printf("grpc: %v", apiErr)
+	}
+	return apiErr
+}
+
+// errorInterceptor приводит ошибки unary методов к модели ошибок: клиент не получает codes.Unknown
+func errorInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, grpcError(err, "internal error")
+		}
+		return resp, nil
+	}
+}
+
+// httpField — имя поля proto в виде параметра HTTP запроса (serial_number → serial-number)
+func httpField(field string) string {
+	return strings.ReplaceAll(field, "_", "-")
+}
@@ -0,0 +1,312 @@
+// Выгрузка метрик в CSV, NDJSON и Parquet: потоковая запись из курсора PostgreSQL без буферизации всего результата.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/parquet-go/parquet-go"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	// exportFlushRows — через сколько строк CSV/NDJSON сбрасываются клиенту
+	exportFlushRows = 1000
+	// parquetRowGroupSize — строк в row group Parquet (ограничивает память на выгрузку)
+	parquetRowGroupSize = 100000
+)
+
+// exportFormat описывает формат выгрузки
+type exportFormat struct {
+	Name        string // значение параметра format
+	ContentType string
+	Extension   string
+}
+
+// exportFormats — поддерживаемые форматы выгрузки (порядок — приоритет при Accept: */*)
+var exportFormats = []exportFormat{
+	{Name: "csv", ContentType: "text/csv", Extension: "csv"},
+	{Name: "ndjson", ContentType: "application/x-ndjson", Extension: "ndjson"},
+	{Name: "parquet", ContentType: "application/vnd.apache.parquet", Extension: "parquet"},
+}
+
+// exportContentAliases — альтернативные MIME-типы в заголовке Accept
+var exportContentAliases = map[string]string{
+	"application/csv":       "csv",
+	"application/jsonl":     "ndjson",
+	"application/x-parquet": "parquet",
+}
+
+// metricExporter — потоковая запись строк метрик в конкретном формате
+type metricExporter interface {
+	Write(row database.MetricRow) error
+	Close() error // дописывает хвост (заголовки Parquet, буферы)
+}
+
+// exportMetricsHandler - HTTP обработчик выгрузки метрик
+// (GET /api/v1/export?serial-number=A,B&metric-type=cpu-usage,memory-usage&from=...&to=...&format=csv|ndjson|parquet).
+// Формат — из параметра format или заголовка Accept; по умолчанию CSV
+func exportMetricsHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format, ok := negotiateExportFormat(c.Query("format"), c.GetHeader("Accept"))
+		if !ok {
+			writeError(c, notAcceptable("format", "unsupported export format: use csv, ndjson or parquet"), "")
+			return
+		}
+
+		var rawSerials []string
+		for _, v := range c.QueryArray("serial-number") {
+			rawSerials = append(rawSerials, strings.Split(v, ",")...)
+		}
+		serials, err := normalizeSerials(rawSerials, "serial_number")
+		if err != nil {
+			writeError(c, err, "")
+			return
+		}
+		if err := authz.AuthorizeDevices(c.Request.Context(), auth.RoleViewer, actionExport, serials...); err != nil {
+			writeError(c, err, "authorization unavailable")
+			return
+		}
+
+		var metricTypes []string
+		for _, v := range c.QueryArray("metric-type") {
+			for _, mt := range strings.Split(v, ",") {
+				if mt = strings.TrimSpace(mt); mt == "" {
+					continue
+				}
+				if !isValidMetricType(tr181.MetricType(mt)) {
+					writeError(c, invalidMetricType(mt), "")
+					return
+				}
+				metricTypes = append(metricTypes, mt)
+			}
+		}
+		if len(metricTypes) == 0 {
+			writeError(c, missingParameter("metric_type"), "")
+			return
+		}
+
+		from, to, ok := parseTimeRange(c)
+		if !ok {
+			return
+		}
+		if from, to, err = normalizeRange(from, to, time.Now()); err != nil {
+			writeError(c, err, "")
+			return
+		}
+
+		// Заголовки отправляются до первой строки: после этого статус уже не поменять
+		filename := fmt.Sprintf("metrics-%s-%s.%s", from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z"), format.Extension)
+		c.Header("Content-Type", format.ContentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+
+		exporter := newMetricExporter(format.Name, c.Writer)
+		err = postgresDB.StreamMetrics(c.Request.Context(), serials, metricTypes, from, to, exporter.Write)
+		if err == nil {
+			err = exporter.Close()
+		}
+		if err != nil {
+			// Ответ уже частично отправлен — обрываем соединение без закрытия формата и завершающего
+			// chunk, чтобы клиент увидел ошибку, а не полный с виду файл
+			log.Printf("export: %v", err)
+			c.Error(err)
+			abortConnection(c)
+		}
+	}
+}
+
+// abortConnectionKey — ключ контекста gin: обработчик просит оборвать соединение (см. abortMiddleware)
+const abortConnectionKey = "abort-connection"
+
+// abortConnection помечает ответ как оборванный: abortMiddleware закроет соединение после обработчика
+func abortConnection(c *gin.Context) {
+	c.Set(abortConnectionKey, true)
+	c.Abort()
+}
+
+// abortMiddleware обрывает соединение (panic(http.ErrAbortHandler): net/http закрывает его без завершения
+// ответа и без записи в лог), если обработчик вызвал abortConnection. Подключается до gin.Recovery —
+// тот перехватил бы панику и завершил ответ как успешный
+func abortMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.GetBool(abortConnectionKey) {
+			panic(http.ErrAbortHandler)
+		}
+	}
+}
+
+// negotiateExportFormat выбирает формат по параметру format, иначе по заголовку Accept
+func negotiateExportFormat(param, accept string) (exportFormat, bool) {
+	if param != "" {
+		for _, f := range exportFormats {
+			if f.Name == strings.ToLower(param) {
+				return f, true
+			}
+		}
+		return exportFormat{}, false
+	}
+	if strings.TrimSpace(accept) == "" {
+		return exportFormats[0], true
+	}
+	// Берём первый поддерживаемый тип в порядке перечисления (q-факторы не учитываем)
+	for _, part := range strings.Split(accept, ",") {
+		mediaType := strings.TrimSpace(strings.SplitN(part, ";", 2)[0])
+		if mediaType == "*/*" || mediaType == "text/*" {
+			return exportFormats[0], true
+		}
+		name := exportContentAliases[mediaType]
+		for _, f := range exportFormats {
+			if f.ContentType == mediaType || f.Name == name {
+				return f, true
+			}
+		}
+	}
+	return exportFormat{}, false
+}
+
+// newMetricExporter создаёт exporter для формата (имя уже проверено negotiateExportFormat)
+func newMetricExporter(format string, w gin.ResponseWriter) metricExporter {
+	switch format {
+	case "ndjson":
+		return newNDJSONExporter(w)
+	case "parquet":
+		return &parquetExporter{w: parquet.NewGenericWriter[parquetMetricRow](w,
+			parquet.Compression(&parquet.Zstd),
+			parquet.MaxRowsPerRowGroup(parquetRowGroupSize),
+		)}
+	default:
+		return newCSVExporter(w)
+	}
+}
+
+// csvExporter пишет строки "serial_number,metric_type,timestamp,value"
+type csvExporter struct {
+	w       *csv.Writer
+	flusher http.Flusher
+	rows    int
+	record  []string
+}
+
+// newCSVExporter создаёт CSV exporter и сразу пишет заголовок
+func newCSVExporter(w gin.ResponseWriter) *csvExporter {
+	e := &csvExporter{w: csv.NewWriter(w), flusher: w, record: make([]string, 4)}
+	e.w.Write([]string{"serial_number", "metric_type", "timestamp", "value"})
+	return e
+}
+
+func (e *csvExporter) Write(row database.MetricRow) error {
+	e.record[0] = row.SerialNumber
+	e.record[1] = row.MetricType
+	e.record[2] = row.Time.UTC().Format(time.RFC3339)
+	e.record[3] = strconv.Itoa(row.Value)
+	if err := e.w.Write(e.record); err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		e.w.Flush()
+		e.flusher.Flush()
+		return e.w.Error() // клиент отключился — прекращаем чтение курсора
+	}
+	return nil
+}
+
+func (e *csvExporter) Close() error {
+	e.w.Flush()
+	e.flusher.Flush()
+	return e.w.Error()
+}
+
+// ndjsonExporter пишет по одному JSON объекту на строку
+type ndjsonExporter struct {
+	w    gin.ResponseWriter
+	enc  *json.Encoder
+	rc   *http.ResponseController // Flush исходного http.ResponseWriter — с ошибкой записи
+	rows int
+}
+
+// newNDJSONExporter создаёт NDJSON exporter
+func newNDJSONExporter(w gin.ResponseWriter) *ndjsonExporter {
+	e := &ndjsonExporter{w: w, enc: json.NewEncoder(w), rc: http.NewResponseController(w)}
+	if u, ok := w.(interface{ Unwrap() http.ResponseWriter }); ok {
+		e.rc = http.NewResponseController(u.Unwrap())
+	}
+	return e
+}
+
+// ndjsonMetricRow — JSON представление строки выгрузки
+type ndjsonMetricRow struct {
+	SerialNumber string    `json:"serial_number"`
+	MetricType   string    `json:"metric_type"`
+	Timestamp    time.Time `json:"timestamp"`
+	Value        int       `json:"value"`
+}
+
+func (e *ndjsonExporter) Write(row database.MetricRow) error {
+	err := e.enc.Encode(ndjsonMetricRow{
+		SerialNumber: row.SerialNumber,
+		MetricType:   row.MetricType,
+		Timestamp:    row.Time.UTC(),
+		Value:        row.Value,
+	})
+	if err != nil {
+		return err
+	}
+	e.rows++
+	if e.rows%exportFlushRows == 0 {
+		return e.flush() // клиент отключился — прекращаем чтение курсора
+	}
+	return nil
+}
+
+func (e *ndjsonExporter) Close() error {
+	return e.flush()
+}
+
+// flush отправляет буфер клиенту. Flush у gin.ResponseWriter ошибку записи теряет — её возвращает
+// повторный Flush исходного writer (ошибка соединения сохраняется в его буфере)
+func (e *ndjsonExporter) flush() error {
+	e.w.Flush()
+	return e.rc.Flush()
+}
+
+// parquetMetricRow — схема Parquet файла выгрузки
+type parquetMetricRow struct {
+	SerialNumber string    `parquet:"serial_number,dict"`
+	MetricType   string    `parquet:"metric_type,dict"`
+	Timestamp    time.Time `parquet:"timestamp,timestamp(millisecond)"`
+	Value        int32     `parquet:"value"`
+}
+
+// parquetExporter пишет Parquet; row group сбрасывается в ответ каждые parquetRowGroupSize строк
+type parquetExporter struct {
+	w   *parquet.GenericWriter[parquetMetricRow]
+	buf [1]parquetMetricRow
+}
+
+func (e *parquetExporter) Write(row database.MetricRow) error {
+	e.buf[0] = parquetMetricRow{
+		SerialNumber: row.SerialNumber,
+		MetricType:   row.MetricType,
+		Timestamp:    row.Time.UTC(),
+		Value:        int32(row.Value),
+	}
+	_, err := e.w.Write(e.buf[:])
+	return err
+}
+
+// Close дописывает последний row group и footer
+func (e *parquetExporter) Close() error {
+	return e.w.Close()
+}
@@ -0,0 +1,157 @@
+// Защита от лавины промахов кэша: одинаковые заполнения внутри процесса объединяются (singleflight),
+// между репликами заполнение идёт под арендой в Redis (остальные ждут результат в кэше),
+// устаревшие записи отдаются сразу, пока одна реплика обновляет их в фоне (stale-while-revalidate).
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	fillTimeout = 30 * time.Second      // предел заполнения (не зависит от отмены запроса, который его начал)
+	leaseTTL    = 10 * time.Second      // аренда заполнения (истекает, если реплика упала посреди загрузки)
+	leaseWait   = 3 * time.Second       // сколько ждать чужое заполнение, прежде чем читать из базы самому
+	leasePoll   = 50 * time.Millisecond // период проверки кэша при ожидании
+)
+
+// cacheFiller — координация заполнения кэша
+type cacheFiller struct {
+	cache *database.RedisCache
+	group singleflight.Group
+}
+
+// newCacheFiller создаёт координатор заполнения для кэша cache
+func newCacheFiller(cache *database.RedisCache) *cacheFiller {
+	return &cacheFiller{cache: cache}
+}
+
+// do выполняет fn один раз для одновременных вызовов с одинаковым key. fn получает контекст,
+// который не отменяется вызывающими (результат нужен всем ждущим); каждый вызывающий ждёт не дольше своего ctx
+func (f *cacheFiller) do(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	ch := f.group.DoChan(key, func() (interface{}, error) {
+		fillCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), fillTimeout)
+		defer cancel()
+		return fn(fillCtx)
+	})
+	select {
+	case res := <-ch:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// underLease выполняет load под арендой name. Если аренду держит другая реплика, ждёт, пока poll не найдёт
+// результат в кэше (true) или аренда не освободится, и только потом читает сам. Ошибки Redis — сразу load
+func (f *cacheFiller) underLease(ctx context.Context, name string, poll func(context.Context) bool, load func(context.Context) error) error {
+	token, ok, err := f.cache.AcquireLease(ctx, name, leaseTTL)
+	if err != nil {
+		return load(ctx)
+	}
+	if ok {
+		defer f.cache.ReleaseLease(context.WithoutCancel(ctx), name, token)
+		return load(ctx)
+	}
+
+	deadline := time.Now().Add(leaseWait)
+	for time.Now().Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(leasePoll):
+		}
+		if poll(ctx) {
+			return nil
+		}
+		if held, err := f.cache.LeaseHeld(ctx, name); err != nil || !held {
+			break // заполнение закончилось без результата в кэше (например, чанк помечен устаревшим)
+		}
+	}
+	return load(ctx)
+}
+
+// cachedSWR возвращает значение из записи stale-while-revalidate key: свежее — сразу; устаревшее — сразу,
+// запуская обновление в фоне; при промахе — load (один раз на процесс и, под арендой, на все реплики)
+func cachedSWR[T any](ctx context.Context, f *cacheFiller, key string, fresh, stale time.Duration, load func(context.Context) (T, error)) (T, error) {
+	if v, isFresh, ok := getSWR[T](ctx, f, key); ok {
+		if !isFresh {
+			go revalidateSWR(context.WithoutCancel(ctx), f, key, fresh, stale, load)
+		}
+		return v, nil
+	}
+
+	res, err := f.do(ctx, key, func(ctx context.Context) (interface{}, error) {
+		var v T
+		err := f.underLease(ctx, key,
+			func(ctx context.Context) bool {
+				cached, _, ok := getSWR[T](ctx, f, key)
+				v = cached
+				return ok
+			},
+			func(ctx context.Context) error {
+				loaded, err := load(ctx)
+				if err != nil {
+					return err
+				}
+				v = loaded
+				setSWR(ctx, f, key, v, fresh, stale)
+				return nil
+			})
+		return v, err
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return res.(T), nil
+}
+
+// revalidateSWR обновляет устаревшую запись в фоне: одно обновление на процесс, под арендой — одно на все реплики
+func revalidateSWR[T any](ctx context.Context, f *cacheFiller, key string, fresh, stale time.Duration, load func(context.Context) (T, error)) {
+	_, err := f.do(ctx, "refresh:"+key, func(ctx context.Context) (interface{}, error) {
+		token, ok, err := f.cache.AcquireLease(ctx, key, leaseTTL)
+		if err == nil && !ok {
+			return nil, nil // обновляет другая реплика
+		}
+		if ok {
+			defer f.cache.ReleaseLease(context.WithoutCancel(ctx), key, token)
+		}
+		// Без Redis (деградированный режим) обновляем сами: запись живёт в локальном кэше
+		v, err := load(ctx)
+		if err != nil {
+			return nil, err
+		}
+		setSWR(ctx, f, key, v, fresh, stale)
+		return nil, nil
+	})
+	if err != nil {
+		log.Printf("cache refresh %s: %v", key, err)
+	}
+}
+
+// getSWR читает и декодирует запись; ok=false — промах или ошибка Redis
+func getSWR[T any](ctx context.Context, f *cacheFiller, key string) (v T, fresh, ok bool) {
+	data, fresh, found, err := f.cache.GetSWR(ctx, key)
+	if err != nil || !found {
+		return v, false, false
+	}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return v, false, false
+	}
+	return v, fresh, true
+}
+
+// setSWR сохраняет значение (ошибка кэша не мешает ответу)
+func setSWR[T any](ctx context.Context, f *cacheFiller, key string, v T, fresh, stale time.Duration) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	f.cache.SetSWR(ctx, key, data, fresh, stale)
+}
@@ -0,0 +1,86 @@
+// Состояние сервиса: /health (доступность кэша) и /metrics (уровни кэша и попадания в формате Prometheus).
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"golang-test-dev/pkg/database"
+)
+
+// defaultLocalCacheMB — объём локального кэша по умолчанию
+const defaultLocalCacheMB = 64
+
+// localCacheBytes — объём локального кэша из LOCAL_CACHE_MB (0 — выключен)
+func localCacheBytes() int64 {
+	mb := defaultLocalCacheMB
+	if v, err := strconv.Atoi(os.Getenv("LOCAL_CACHE_MB")); err == nil && v >= 0 {
+		mb = v
+	}
+	return int64(mb) << 20
+}
+
+// healthHandler - статус сервиса: ok или degraded (Redis недоступен). Оба — 200: запросы обслуживаются
+func healthHandler(cache *database.RedisCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := cache.Stats()
+		status, redis := "ok", "up"
+		if !st.RedisUp {
+			status, redis = "degraded", "down"
+		}
+		body := gin.H{"status": status, "cache": gin.H{
+			"redis":           redis,
+			"redis_hit_ratio": hitRatio(st.RedisHits, st.RedisMisses+st.RedisErrors),
+		}}
+		if st.LocalEnabled {
+			body["cache"].(gin.H)["local"] = gin.H{
+				"entries":   st.LocalEntries,
+				"bytes":     st.LocalBytes,
+				"hit_ratio": hitRatio(st.LocalHits, st.LocalMisses),
+			}
+		}
+		c.JSON(http.StatusOK, body)
+	}
+}
+
+// metricsHandler - метрики кэша в текстовом формате Prometheus
+func metricsHandler(cache *database.RedisCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		st := cache.Stats()
+		up := 0
+		if st.RedisUp {
+			up = 1
+		}
+		var b strings.Builder
+		fmt.Fprintf(&b, "# HELP api_gateway_cache_redis_up Redis availability (0 - degraded mode).\n")
+		fmt.Fprintf(&b, "# TYPE api_gateway_cache_redis_up gauge\napi_gateway_cache_redis_up %d\n", up)
+		fmt.Fprintf(&b, "# HELP api_gateway_cache_requests_total Cache lookups by tier and result.\n")
+		fmt.Fprintf(&b, "# TYPE api_gateway_cache_requests_total counter\n")
+		if st.LocalEnabled {
+			fmt.Fprintf(&b, "api_gateway_cache_requests_total{tier=\"local\",result=\"hit\"} %d\n", st.LocalHits)
+			fmt.Fprintf(&b, "api_gateway_cache_requests_total{tier=\"local\",result=\"miss\"} %d\n", st.LocalMisses)
+		}
+		fmt.Fprintf(&b, "api_gateway_cache_requests_total{tier=\"redis\",result=\"hit\"} %d\n", st.RedisHits)
+		fmt.Fprintf(&b, "api_gateway_cache_requests_total{tier=\"redis\",result=\"miss\"} %d\n", st.RedisMisses)
+		fmt.Fprintf(&b, "api_gateway_cache_requests_total{tier=\"redis\",result=\"error\"} %d\n", st.RedisErrors)
+		if st.LocalEnabled {
+			fmt.Fprintf(&b, "# HELP api_gateway_cache_local_entries Entries in the in-process cache.\n")
+			fmt.Fprintf(&b, "# TYPE api_gateway_cache_local_entries gauge\napi_gateway_cache_local_entries %d\n", st.LocalEntries)
+			fmt.Fprintf(&b, "# HELP api_gateway_cache_local_bytes Approximate size of the in-process cache.\n")
+			fmt.Fprintf(&b, "# TYPE api_gateway_cache_local_bytes gauge\napi_gateway_cache_local_bytes %d\n", st.LocalBytes)
+		}
+		c.Data(http.StatusOK, "text/plain; version=0.0.4; charset=utf-8", []byte(b.String()))
+	}
+}
+
+// hitRatio — доля попаданий (0, если обращений не было)
+func hitRatio(hits, misses uint64) float64 {
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
@@ -0,0 +1,186 @@
+// Инциденты алертов (открываются и закрываются alert-processor): HTTP и gRPC обработчики поверх queryService.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	defaultIncidentLimit = 100  // инцидентов в ответе по умолчанию
+	maxIncidentLimit     = 1000 // максимум инцидентов в ответе
+)
+
+// incidentQuery — параметры запроса инцидентов (нулевое время — значение по умолчанию)
+type incidentQuery struct {
+	SerialNumber string
+	AlertType    string // пусто — все типы
+	Status       string // open, closed или пусто
+	From         time.Time
+	To           time.Time
+	Limit        int // 0 — defaultIncidentLimit
+}
+
+// incidentResponse — HTTP представление инцидента
+type incidentResponse struct {
+	database.Incident
+	DurationSeconds int64 `json:"duration_seconds"` // до конца или до текущего момента для открытого
+}
+
+// Incidents возвращает инциденты устройства, пересекающиеся с периодом
+func (s *queryService) Incidents(ctx context.Context, q incidentQuery) ([]database.Incident, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if q.AlertType != "" && !isValidAlertType(tr181.AlertType(q.AlertType)) {
+		return nil, invalidAlertType(q.AlertType)
+	}
+	if q.Status != "" && q.Status != "open" && q.Status != "closed" {
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "status", "status must be open or closed")
+	}
+	var err error
+	if q.Limit, err = resultLimit(q.Limit, defaultIncidentLimit, maxIncidentLimit, "incidents"); err != nil {
+		return nil, err
+	}
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionAlertRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	incidents, err := s.postgresDB.GetIncidents(ctx, database.IncidentFilter{
+		SerialNumber: q.SerialNumber,
+		AlertType:    q.AlertType,
+		Status:       q.Status,
+		From:         q.From,
+		To:           q.To,
+		Limit:        q.Limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get incidents: %w", err)
+	}
+	return incidents, nil
+}
+
+// GetIncidents - gRPC метод получения инцидентов алертов
+func (s *apiServer) GetIncidents(ctx context.Context, req *tr181pb.IncidentRequest) (*tr181pb.IncidentResponse, error) {
+	from, to, err := grpcTimeRange(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+
+	incidents, err := s.svc.Incidents(ctx, incidentQuery{
+		SerialNumber: req.SerialNumber,
+		AlertType:    req.AlertType,
+		Status:       req.Status,
+		From:         from,
+		To:           to,
+		Limit:        int(req.Limit),
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to get incidents")
+	}
+
+	now := time.Now()
+	resp := &tr181pb.IncidentResponse{Incidents: make([]*tr181pb.Incident, len(incidents))}
+	for i, in := range incidents {
+		pb := &tr181pb.Incident{
+			Id:              in.ID,
+			SerialNumber:    in.SerialNumber,
+			AlertType:       in.AlertType,
+			Severity:        in.Severity,
+			StartedAt:       in.StartedAt.Unix(),
+			LastSeenAt:      in.LastSeenAt.Unix(),
+			PeakValue:       int32(in.PeakValue),
+			LastValue:       int32(in.LastValue),
+			Samples:         int32(in.Samples),
+			DurationSeconds: incidentDuration(now, in),
+		}
+		if in.EndedAt != nil {
+			pb.EndedAt = in.EndedAt.Unix()
+		}
+		resp.Incidents[i] = pb
+	}
+	return resp, nil
+}
+
+// getIncidentsHandler - HTTP обработчик инцидентов
+// (GET /api/v1/incidents?serial-number=&alert-type=&status=open|closed&from=&to=&limit=)
+func getIncidentsHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, ok := parseTimeRange(c)
+		if !ok {
+			return
+		}
+		limit, ok := parseLimit(c)
+		if !ok {
+			return
+		}
+
+		incidents, err := svc.Incidents(c.Request.Context(), incidentQuery{
+			SerialNumber: c.Query("serial-number"),
+			AlertType:    c.Query("alert-type"),
+			Status:       c.Query("status"),
+			From:         from,
+			To:           to,
+			Limit:        limit,
+		})
+		if err != nil {
+			writeError(c, err, "failed to get incidents")
+			return
+		}
+
+		now := time.Now()
+		result := make([]incidentResponse, len(incidents))
+		for i, in := range incidents {
+			result[i] = incidentResponse{Incident: in, DurationSeconds: incidentDuration(now, in)}
+		}
+		c.JSON(http.StatusOK, gin.H{"incidents": result})
+	}
+}
+
+// resultLimit проверяет лимит числа записей в ответе (0 — def); what — что ограничивается (для ошибки)
+func resultLimit(limit, def, maxLimit int, what string) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "limit", "limit must be positive")
+	case limit == 0:
+		return def, nil
+	case limit > maxLimit:
+		return 0, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "limit", "limit exceeds maximum of %d %s", maxLimit, what)
+	}
+	return limit, nil
+}
+
+// parseLimit разбирает параметр limit HTTP запроса (пусто — 0); при ошибке отправляет ответ 400
+func parseLimit(c *gin.Context) (int, bool) {
+	v := c.Query("limit")
+	if v == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(v)
+	if err != nil {
+		writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "limit", "invalid limit parameter"), "")
+		return 0, false
+	}
+	return limit, true
+}
+
+// incidentDuration — длительность инцидента в секундах (открытого — до now)
+func incidentDuration(now time.Time, in database.Incident) int64 {
+	end := now
+	if in.EndedAt != nil {
+		end = *in.EndedAt
+	}
+	return int64(staleness(end, in.StartedAt).Seconds())
+}
@@ -0,0 +1,96 @@
+// Управление API-ключами (только для администраторов): выпуск, список, отзыв.
+package main
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+// createAPIKeyRequest — тело POST /api/v1/keys
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Role   string   `json:"role"`   // viewer (по умолчанию), operator или admin
+	Scopes []string `json:"scopes"` // group:<имя>, pattern:<glob> или *
+}
+
+// createAPIKeyHandler - выпускает новый ключ; сам ключ возвращается только в этом ответе
+func createAPIKeyHandler(postgresDB *database.PostgresDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createAPIKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "body", "invalid request body"), "")
+			return
+		}
+		if req.Name = strings.TrimSpace(req.Name); req.Name == "" {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_MISSING_PARAMETER, "name", "name is required"), "")
+			return
+		}
+		if req.Role == "" {
+			req.Role = auth.RoleViewer
+		}
+		if !auth.ValidRole(req.Role) {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "role", "invalid role: use viewer, operator or admin"), "")
+			return
+		}
+		if req.Scopes == nil {
+			req.Scopes = []string{}
+		}
+		for _, scope := range req.Scopes {
+			if err := auth.ValidateScope(scope); err != nil {
+				writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "scopes", "%s", err), "")
+				return
+			}
+		}
+
+		key, prefix, hash, err := auth.GenerateAPIKey()
+		if err != nil {
+			writeError(c, err, "failed to generate api key")
+			return
+		}
+		stored, err := postgresDB.CreateAPIKey(c.Request.Context(), req.Name, prefix, hash, req.Role, req.Scopes)
+		if err != nil {
+			writeError(c, err, "failed to save api key")
+			return
+		}
+		c.JSON(http.StatusCreated, gin.H{"key": key, "api_key": stored})
+	}
+}
+
+// listAPIKeysHandler - список ключей (без секретов)
+func listAPIKeysHandler(postgresDB *database.PostgresDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := postgresDB.ListAPIKeys(c.Request.Context())
+		if err != nil {
+			writeError(c, err, "failed to list api keys")
+			return
+		}
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+// revokeAPIKeyHandler - отзывает ключ (DELETE /api/v1/keys/:id)
+func revokeAPIKeyHandler(postgresDB *database.PostgresDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "id", "invalid key id"), "")
+			return
+		}
+		revoked, err := postgresDB.RevokeAPIKey(c.Request.Context(), id)
+		if err != nil {
+			writeError(c, err, "failed to revoke api key")
+			return
+		}
+		if !revoked {
+			writeError(c, notFound("api key not found"), "")
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -10,114 +10,119 @@ import (
 	"net/http"  // HTTP сервер и клиент
 	"os"        // Переменные окружения, выход из программы
 	"os/signal" // Обработка сигналов ОС (Ctrl+C)
+	"strconv"   // Разбор limit и downsample
+	"strings"   // Разбор TRUSTED_PROXIES
 	"syscall"   // Системные вызовы (SIGINT, SIGTERM)
 	"time"      // Работа со временем
 
 	"github.com/gin-gonic/gin"           // HTTP веб-фреймворк
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime" // REST /api/v2 поверх gRPC методов
+	"golang-test-dev/api/openapi"        // OpenAPI описание REST API
 	tr181pb "golang-test-dev/api/tr181pb/api/proto" // Сгенерированный gRPC код
 	"golang-test-dev/pkg/database"      // PostgreSQL и Redis
 	"golang-test-dev/pkg/logcollector"
 	"golang-test-dev/pkg/tr181"         // Модель данных TR181
+	"golang-test-dev/services/api-gateway/auth" // Аутентификация API-ключами и JWT
+	"golang-test-dev/services/api-gateway/ratelimit" // Ограничение частоты запросов
 	"google.golang.org/grpc"             // gRPC сервер
 	"google.golang.org/grpc/reflection"  // Рефлексия для grpcurl
+	"google.golang.org/protobuf/encoding/protojson" // JSON для REST /api/v2
 )
 
-// apiServer - реализует gRPC интерфейс TR181ApiServer
+// apiServer - реализует gRPC интерфейс TR181ApiServer (он же обслуживает REST /api/v2 через gRPC-Gateway)
 type apiServer struct {
 	tr181pb.UnimplementedTR181ApiServer // Встраиваем для обратной совместимости
-	postgresDB  *database.PostgresDB   // Подключение к PostgreSQL
-	redisCache  *database.RedisCache   // Подключение к Redis для кэша
+	svc *queryService                   // Общая логика запросов
 }
 
 // GetMetric - gRPC метод получения метрик по устройству и периоду
 func (s *apiServer) GetMetric(ctx context.Context, req *tr181pb.MetricRequest) (*tr181pb.MetricResponse, error) {
-	// Проверяем обязательный параметр
-	if req.SerialNumber == "" {
-		return nil, fmt.Errorf("serial_number is required")
-	}
-	// Проверяем валидность типа метрики
-	if !isValidMetricType(tr181.MetricType(req.MetricType)) {
-		return nil, fmt.Errorf("invalid metric type")
-	}
-
-	// Преобразуем Unix timestamp в time.Time для начала периода
-	from := time.Unix(req.From, 0)
-	// Если не указан - берём последние 24 часа
-	if req.From == 0 {
-		from = time.Now().Add(-24 * time.Hour)
-	}
-	// Преобразуем конец периода
-	to := time.Unix(req.To, 0)
-	// Если не указан - текущее время
-	if req.To == 0 {
-		to = time.Now()
-	}
-
-	// Формируем ключ кэша из параметров запроса
-	cacheKey := fmt.Sprintf("metric:%s:%s:%d:%d", req.MetricType, req.SerialNumber, from.Unix(), to.Unix())
-	// Пробуем получить данные из Redis кэша
-	if cached, err := s.redisCache.GetCachedMetrics(ctx, cacheKey); err == nil && cached != nil {
-		// Конвертируем в gRPC формат и возвращаем
-		metrics := make([]*tr181pb.MetricValue, len(cached))
-		for i, m := range cached {
-			metrics[i] = &tr181pb.MetricValue{Value: int32(m.Value), Time: m.Time}
-		}
-		return &tr181pb.MetricResponse{Metrics: metrics}, nil
+	spec, err := grpcRangeSpec(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
 	}
-
-	// Запрашиваем метрики из PostgreSQL
-	metrics, err := s.postgresDB.GetMetrics(ctx, req.SerialNumber, req.MetricType, from, to)
+	from, to, err := spec.resolve(time.Now())
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+
+	// Получаем страницу метрик с учётом лимитов (кэш → PostgreSQL)
+	page, err := s.svc.Metrics(ctx, metricQuery{
+		SerialNumber: req.SerialNumber,
+		MetricType:   req.MetricType,
+		From:         from,
+		To:           to,
+		Limit:        int(req.Limit),
+		PageToken:    req.PageToken,
+		Downsample:   req.Downsample,
+		RangeSpec:    spec,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get metrics: %w", err)
+		return nil, grpcError(err, "failed to get metrics")
 	}
 
-	// Сохраняем результат в кэш на 30 секунд
-	s.redisCache.CacheMetrics(ctx, cacheKey, metrics, 30*time.Second)
-
 	// Конвертируем в gRPC формат
-	pbMetrics := make([]*tr181pb.MetricValue, len(metrics))
-	for i, m := range metrics {
+	pbMetrics := make([]*tr181pb.MetricValue, len(page.Metrics))
+	for i, m := range page.Metrics {
 		pbMetrics[i] = &tr181pb.MetricValue{Value: int32(m.Value), Time: m.Time}
 	}
-	return &tr181pb.MetricResponse{Metrics: pbMetrics}, nil
+	return &tr181pb.MetricResponse{
+		Metrics:       pbMetrics,
+		NextPageToken: page.NextPageToken,
+		BucketSeconds: page.BucketSeconds,
+	}, nil
 }
 
 // GetAlert - gRPC метод получения статистики по алертам
 func (s *apiServer) GetAlert(ctx context.Context, req *tr181pb.AlertRequest) (*tr181pb.AlertResponse, error) {
-	// Проверяем обязательный параметр
-	if req.SerialNumber == "" {
-		return nil, fmt.Errorf("serial_number is required")
-	}
-	// Проверяем валидность типа алерта
-	if !isValidAlertType(tr181.AlertType(req.AlertType)) {
-		return nil, fmt.Errorf("invalid alert type")
+	from, to, err := grpcTimeRange(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
 	}
 
-	// Парсим период времени
-	from := time.Unix(req.From, 0)
-	if req.From == 0 {
-		from = time.Now().Add(-24 * time.Hour)
-	}
-	to := time.Unix(req.To, 0)
-	if req.To == 0 {
-		to = time.Now()
+	stats, err := s.svc.AlertStats(ctx, alertQuery{
+		SerialNumber: req.SerialNumber,
+		AlertType:    req.AlertType,
+		Severities:   req.Severity,
+		From:         from,
+		To:           to,
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to get alert stats")
 	}
-
-	// Пробуем получить из кэша
-	cacheKey := fmt.Sprintf("alert:%s:%s:%d:%d", req.AlertType, req.SerialNumber, from.Unix(), to.Unix())
-	if cached, err := s.redisCache.GetCachedAlertStats(ctx, cacheKey); err == nil && cached != nil {
-		return &tr181pb.AlertResponse{Value: int32(cached.Value), Count: int32(cached.Count)}, nil
+	severities := make(map[string]int32, len(stats.Severities))
+	for sev, n := range stats.Severities {
+		severities[sev] = int32(n)
 	}
+	return &tr181pb.AlertResponse{Value: int32(stats.Value), Count: int32(stats.Count), Severities: severities}, nil
+}
 
-	// Запрашиваем из БД
-	stats, err := s.postgresDB.GetAlertStats(ctx, req.SerialNumber, req.AlertType, from, to)
+// grpcTimeRange - период gRPC запроса по тем же правилам, что и в HTTP. Unix-время from/to
+// и строковые from_expr/to_expr взаимоисключающие; 0 и пустая строка — значение по умолчанию
+func grpcTimeRange(from, to int64, fromExpr, toExpr, rangeName, tz string) (time.Time, time.Time, error) {
+	spec, err := grpcRangeSpec(from, to, fromExpr, toExpr, rangeName, tz)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get alert stats: %w", err)
+		return time.Time{}, time.Time{}, err
 	}
+	return spec.resolve(time.Now())
+}
 
-	// Кэшируем результат
-	s.redisCache.CacheAlertStats(ctx, cacheKey, stats, 30*time.Second)
-	return &tr181pb.AlertResponse{Value: int32(stats.Value), Count: int32(stats.Count)}, nil
+// grpcRangeSpec - период gRPC запроса в виде строк, как в HTTP (Unix-время from/to — строкой)
+func grpcRangeSpec(from, to int64, fromExpr, toExpr, rangeName, tz string) (timeRangeSpec, error) {
+	if from != 0 && fromExpr != "" {
+		return timeRangeSpec{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "from_expr", "from and from_expr are mutually exclusive")
+	}
+	if to != 0 && toExpr != "" {
+		return timeRangeSpec{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "to_expr", "to and to_expr are mutually exclusive")
+	}
+	spec := timeRangeSpec{From: fromExpr, To: toExpr, Range: rangeName, TZ: tz}
+	if from != 0 {
+		spec.From = strconv.FormatInt(from, 10)
+	}
+	if to != 0 {
+		spec.To = strconv.FormatInt(to, 10)
+	}
+	return spec, nil
 }
 
 func main() {
@@ -149,12 +154,35 @@ func main() {
 		redisAddr = "localhost:6379"
 	}
 
-	// Подключаемся к Redis для кэширования
-	redisCache, err := database.NewRedisCache(redisAddr)
+	// Redis для кэширования: без него gateway работает в деградированном режиме (локальный кэш и PostgreSQL)
+	// и переподключается автоматически. LOCAL_CACHE_MB — объём локального кэша перед Redis (0 — выключен)
+	redisCache := database.OpenRedisCache(redisAddr, localCacheBytes())
+	defer redisCache.Close()
+
+	// Лимиты запросов метрик (METRIC_MAX_POINTS, METRIC_MAX_RANGE)
+	limits := loadQueryLimits()
+
+	// Аутентификация (AUTH_MODE): nil — выключена, все запросы разрешены
+	authn, err := auth.New(auth.LoadConfig(), postgresDB)
+	if err != nil {
+		log.Fatalf("Failed to configure authentication: %v", err)
+	}
+	if authn == nil {
+		log.Printf("Warning: authentication is disabled (set AUTH_MODE=apikey and/or jwt)")
+	}
+	// Авторизация по ролям и группам устройств (nil при выключенной аутентификации)
+	authz := auth.NewAuthorizer(authn, postgresDB, postgresDB)
+
+	// Сервис запросов — общий для HTTP /api/v1, gRPC и REST /api/v2
+	svc := &queryService{postgresDB: postgresDB, redisCache: redisCache, limits: limits, authz: authz, fill: newCacheFiller(redisCache)}
+	server := &apiServer{svc: svc}
+
+	// Ограничение частоты запросов (RATE_LIMIT_DEFAULT, RATE_LIMIT_ROUTES): корзины в Redis, общие для реплик
+	rateCfg, err := ratelimit.LoadConfig()
 	if err != nil {
-		log.Fatalf("Failed to connect to Redis: %v", err)
+		log.Fatalf("Failed to configure rate limiting: %v", err)
 	}
-	defer redisCache.Close()
+	limiter := ratelimit.New(rateCfg, redisCache)
 
 	// Настраиваем Gin в release режиме (без отладочной информации)
 	if os.Getenv("GIN_MODE") == "" {
@@ -162,7 +190,16 @@ func main() {
 	}
 	// Создаём HTTP роутер с цветным логгером по коду ответа
 	router := gin.New()
-	router.Use(gin.Recovery())
+	router.Use(abortMiddleware(), gin.Recovery())
+	// IP клиента из X-Forwarded-For берётся только от доверенных прокси (TRUSTED_PROXIES),
+	// иначе клиент мог бы обойти ограничение частоты, подставив чужой IP
+	var trustedProxies []string
+	if v := os.Getenv("TRUSTED_PROXIES"); v != "" {
+		trustedProxies = strings.Split(v, ",")
+	}
+	if err := router.SetTrustedProxies(trustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 	logColl := logcollector.New("api-gateway") // опционально: при PULSAR_URL — для log-viewer
 	if logColl != nil {
 		defer logColl.Close()
@@ -171,18 +208,82 @@ func main() {
 
 	// Группа роутов с префиксом /api/v1
 	api := router.Group("/api/v1")
+	api.Use(limiter.IPMiddleware(), authn.Middleware(), limiter.Middleware())
 	{
-		// GET /api/v1/metric/:metricType - получение метрик
-		api.GET("/metric/:metricType", getMetricHandler(postgresDB, redisCache))
-		// GET /api/v1/alert/:alertType - получение статистики алертов
-		api.GET("/alert/:alertType", getAlertHandler(postgresDB, redisCache))
+		// GET /api/v1/metric/:metricType - получение метрик (устаревший, см. /api/v2)
+		api.GET("/metric/:metricType", getMetricHandler(server))
+		// GET /api/v1/metric/:metricType/summary - сводная статистика метрики (min, max, mean, median, p95, stddev)
+		api.GET("/metric/:metricType/summary", getMetricSummaryHandler(svc))
+		// GET /api/v1/metric/:metricType/baseline - сравнение устройства с парком или группой устройств
+		api.GET("/metric/:metricType/baseline", getMetricBaselineHandler(svc))
+		// GET /api/v1/metric/:metricType/anomaly-model - модель аномалий устройства (EWMA среднего и отклонения)
+		api.GET("/metric/:metricType/anomaly-model", getAnomalyModelHandler(svc))
+		// GET /api/v1/alert/:alertType - получение статистики алертов (устаревший, см. /api/v2)
+		api.GET("/alert/:alertType", getAlertHandler(server))
+		// GET /api/v1/incidents?serial-number= - инциденты алертов устройства (начало, конец, пик)
+		api.GET("/incidents", getIncidentsHandler(svc))
+		// GET /api/v1/reboots?serial-number= - история перезагрузок устройства
+		api.GET("/reboots", getRebootsHandler(svc))
+		// GET /api/v1/state?serial-number=A,B - последнее состояние нескольких устройств
+		api.GET("/state", getDeviceStatesHandler(svc))
+		// GET /api/v1/state/:serialNumber - последнее состояние одного устройства
+		api.GET("/state/:serialNumber", getDeviceStateHandler(svc))
+		// GET /api/v1/export - потоковая выгрузка метрик (CSV, NDJSON, Parquet)
+		api.GET("/export", exportMetricsHandler(postgresDB, authz))
+
+		// Prometheus-совместимый API для Grafana (GET и POST, как в Prometheus)
+		api.Match([]string{http.MethodGet, http.MethodPost}, "/query", promQueryHandler(postgresDB, authz, limits))
+		api.Match([]string{http.MethodGet, http.MethodPost}, "/query_range", promQueryRangeHandler(postgresDB, authz, limits))
+		api.Match([]string{http.MethodGet, http.MethodPost}, "/series", promSeriesHandler(postgresDB, authz))
+		api.Match([]string{http.MethodGet, http.MethodPost}, "/labels", promLabelsHandler())
+		api.GET("/label/:name/values", promLabelValuesHandler(postgresDB, authz))
+
+		// Тишины: для своих устройств (serial_number=) — operator, остальные — admin; список, снятие
+		api.POST("/silences", createSilenceHandler(postgresDB, redisCache, authz))
+		api.GET("/silences", listSilencesHandler(postgresDB, authz))
+		api.DELETE("/silences/:id", expireSilenceHandler(postgresDB, redisCache, authz))
+		// Повторяющиеся окна обслуживания групп устройств (только администраторы)
+		maint := api.Group("/maintenance-windows", authz.RequireRole(auth.RoleAdmin, actionMaint))
+		maint.POST("", createMaintenanceWindowHandler(postgresDB, redisCache))
+		maint.GET("", listMaintenanceWindowsHandler(postgresDB))
+		maint.DELETE("/:id", deleteMaintenanceWindowHandler(postgresDB, redisCache))
+
+		// Управление API-ключами и группами устройств (только администраторы, при включённой аутентификации)
+		if authz != nil {
+			keys := api.Group("/keys", authz.RequireRole(auth.RoleAdmin, actionManageKeys))
+			keys.POST("", createAPIKeyHandler(postgresDB))
+			keys.GET("", listAPIKeysHandler(postgresDB))
+			keys.DELETE("/:id", revokeAPIKeyHandler(postgresDB))
+
+			groups := api.Group("/device-groups", authz.RequireRole(auth.RoleAdmin, actionManageGrps))
+			groups.POST("/:group/devices", addGroupMembersHandler(postgresDB, redisCache, authz))
+			groups.GET("/:group/devices", listGroupMembersHandler(postgresDB))
+			groups.DELETE("/:group/devices/:serialNumber", removeGroupMemberHandler(postgresDB, redisCache, authz))
+		}
+	}
+
+	// REST /api/v2 — gRPC-Gateway по HTTP-аннотациям из tr181_api.proto (вызывает apiServer напрямую,
+	// аутентификация и ограничение частоты — те же middleware, что у /api/v1)
+	gwMux := runtime.NewServeMux(runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions:   protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true},
+		UnmarshalOptions: protojson.UnmarshalOptions{DiscardUnknown: true},
+	}))
+	if err := tr181pb.RegisterTR181ApiHandlerServer(context.Background(), gwMux, server); err != nil {
+		log.Fatalf("Failed to register REST gateway: %v", err)
 	}
+	v2 := router.Group("/api/v2", limiter.IPMiddleware(), authn.Middleware(), limiter.Middleware())
+	v2.Any("/*path", gin.WrapH(gwMux))
 
-	// Health check - проверка работоспособности
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	// OpenAPI описание REST /api/v2 (генерируется buf generate вместе с gRPC кодом)
+	router.GET("/api/openapi.json", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", openapi.Spec)
 	})
 
+	// Health check - проверка работоспособности (status=degraded — Redis недоступен, запросы обслуживаются без него)
+	router.GET("/health", healthHandler(redisCache))
+	// Метрики кэша в формате Prometheus
+	router.GET("/metrics", metricsHandler(redisCache))
+
 	// Порт для HTTP (по умолчанию 8080)
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -214,15 +315,17 @@ func main() {
 		log.Fatalf("Failed to listen for gRPC: %v", err)
 	}
 
-	// Создаём gRPC сервер
-	grpcServer := grpc.NewServer()
+	// Создаём gRPC сервер с проверкой учётных данных и ограничением частоты (IP-квота — до аутентификации)
+	grpcServer := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(errorInterceptor(), limiter.IPUnaryInterceptor(), authn.UnaryInterceptor(), limiter.UnaryInterceptor()),
+		grpc.ChainStreamInterceptor(limiter.IPStreamInterceptor(), authn.StreamInterceptor(), limiter.StreamInterceptor()),
+	)
 	// Регистрируем наш сервис
-	tr181pb.RegisterTR181ApiServer(grpcServer, &apiServer{
-		postgresDB: postgresDB,
-		redisCache: redisCache,
-	})
-	// Включаем рефлексию для grpcurl
-	reflection.Register(grpcServer)
+	tr181pb.RegisterTR181ApiServer(grpcServer, server)
+	// Включаем рефлексию для grpcurl (GRPC_REFLECTION=off — выключить)
+	if authn.ReflectionMode() != auth.ReflectionOff {
+		reflection.Register(grpcServer)
+	}
 
 	// Запускаем gRPC сервер в отдельной горутине
 	go func() {
@@ -256,145 +359,85 @@ func main() {
 	log.Println("Server exited")
 }
 
-// getMetricHandler - HTTP обработчик для получения метрик
-func getMetricHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache) gin.HandlerFunc {
+// getMetricHandler - HTTP обработчик для получения метрик (устаревший, обёртка над GetMetric — см. v1.go).
+// Тело ответа — массив точек; токен следующей страницы — в заголовке X-Next-Page-Token,
+// интервал усреднения при downsample=true — в заголовке X-Bucket-Seconds
+func getMetricHandler(server *apiServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлекаем параметры из URL
-		metricType := c.Param("metricType")
-		serialNumber := c.Query("serial-number")
-		fromStr := c.Query("from")
-		toStr := c.Query("to")
-
-		// Проверка обязательного параметра
-		if serialNumber == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "serial-number is required"})
+		req := &tr181pb.MetricRequest{}
+		if err := populateV1Request(c, req, map[string]string{"metric_type": c.Param("metricType")}); err != nil {
+			writeError(c, err, "")
 			return
 		}
-
-		// Парсим время начала периода
-		from, err := parseTime(fromStr)
+		resp, err := server.GetMetric(c.Request.Context(), req)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from parameter"})
+			writeError(c, err, "failed to get metrics")
 			return
 		}
 
-		// Парсим время конца периода
-		to, err := parseTime(toStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to parameter"})
-			return
+		if resp.NextPageToken != "" {
+			c.Header("X-Next-Page-Token", resp.NextPageToken)
 		}
-
-		// Проверяем валидность типа метрики
-		if !isValidMetricType(tr181.MetricType(metricType)) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid metric type"})
-			return
-		}
-
-		// Формируем ключ кэша
-		cacheKey := fmt.Sprintf("metric:%s:%s:%d:%d", metricType, serialNumber, from.Unix(), to.Unix())
-		ctx := c.Request.Context()
-
-		// Пробуем получить из кэша
-		if cached, err := redisCache.GetCachedMetrics(ctx, cacheKey); err == nil && cached != nil {
-			c.JSON(http.StatusOK, cached)
-			return
+		if resp.BucketSeconds > 0 {
+			c.Header("X-Bucket-Seconds", strconv.FormatInt(resp.BucketSeconds, 10))
 		}
-
-		// Запрашиваем из PostgreSQL
-		metrics, err := postgresDB.GetMetrics(ctx, serialNumber, metricType, from, to)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get metrics"})
-			return
+		metrics := make([]database.MetricValue, len(resp.Metrics))
+		for i, m := range resp.Metrics {
+			metrics[i] = database.MetricValue{Value: int(m.Value), Time: m.Time}
 		}
-
-		// Сохраняем в кэш
-		redisCache.CacheMetrics(ctx, cacheKey, metrics, 30*time.Second)
-		// Возвращаем результат
 		c.JSON(http.StatusOK, metrics)
 	}
 }
 
-// getAlertHandler - HTTP обработчик для получения статистики алертов
-func getAlertHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache) gin.HandlerFunc {
+// getAlertHandler - HTTP обработчик для получения статистики алертов (устаревший, обёртка над GetAlert)
+func getAlertHandler(server *apiServer) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Извлекаем параметры из URL
-		alertType := c.Param("alertType")
-		serialNumber := c.Query("serial-number")
-		fromStr := c.Query("from")
-		toStr := c.Query("to")
-
-		// Проверка обязательного параметра
-		if serialNumber == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "serial-number is required"})
+		req := &tr181pb.AlertRequest{}
+		if err := populateV1Request(c, req, map[string]string{"alert_type": c.Param("alertType")}); err != nil {
+			writeError(c, err, "")
 			return
 		}
-
-		// Парсим время начала периода
-		from, err := parseTime(fromStr)
+		resp, err := server.GetAlert(c.Request.Context(), req)
 		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from parameter"})
+			writeError(c, err, "failed to get alert stats")
 			return
 		}
 
-		// Парсим время конца периода
-		to, err := parseTime(toStr)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to parameter"})
-			return
+		stats := &database.AlertStats{Value: int(resp.Value), Count: int(resp.Count), Severities: make(map[string]int, len(resp.Severities))}
+		for sev, n := range resp.Severities {
+			stats.Severities[sev] = int(n)
 		}
-
-		// Проверяем валидность типа алерта
-		if !isValidAlertType(tr181.AlertType(alertType)) {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid alert type"})
-			return
-		}
-
-		// Формируем ключ кэша
-		cacheKey := fmt.Sprintf("alert:%s:%s:%d:%d", alertType, serialNumber, from.Unix(), to.Unix())
-		ctx := c.Request.Context()
-
-		// Пробуем получить из кэша
-		if cached, err := redisCache.GetCachedAlertStats(ctx, cacheKey); err == nil && cached != nil {
-			c.JSON(http.StatusOK, cached)
-			return
-		}
-
-		// Запрашиваем из PostgreSQL
-		stats, err := postgresDB.GetAlertStats(ctx, serialNumber, alertType, from, to)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get alert stats"})
-			return
-		}
-
-		// Сохраняем в кэш на 30 секунд и возвращаем результат
-		redisCache.CacheAlertStats(ctx, cacheKey, stats, 30*time.Second)
 		c.JSON(http.StatusOK, stats)
 	}
 }
 
-// parseTime парсит время. Допустим только RFC3339 (например 2006-01-02T15:04:05Z07:00).
-// Пустая строка = последние 24 часа. Клиенты конвертируют свои форматы на своей стороне.
-func parseTime(timeStr string) (time.Time, error) {
-	if timeStr == "" {
-		return time.Now().Add(-24 * time.Hour), nil
-	}
-	t, err := time.Parse(time.RFC3339, timeStr)
+// parseTimeRange разбирает параметры from, to, range и tz HTTP запроса (форматы — в timerange.go);
+// при ошибке отправляет ответ 400. Незаданные границы — нулевое время (значения по умолчанию — в сервисе запросов)
+func parseTimeRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, to, err := queryRangeSpec(c).resolve(time.Now())
 	if err != nil {
-		return time.Time{}, fmt.Errorf("invalid time format: use RFC3339 (e.g. 2006-01-02T15:04:05Z)")
+		writeError(c, err, "")
+		return time.Time{}, time.Time{}, false
 	}
-	return t, nil
+	return from, to, true
+}
+
+// queryRangeSpec - параметры from, to, range и tz HTTP запроса без разбора
+func queryRangeSpec(c *gin.Context) timeRangeSpec {
+	return timeRangeSpec{From: c.Query("from"), To: c.Query("to"), Range: c.Query("range"), TZ: c.Query("tz")}
+}
+
+// validMetricTypes - все типы метрик, доступные через API
+var validMetricTypes = []tr181.MetricType{
+	tr181.MetricCPUUsage, tr181.MetricMemoryUsage, tr181.MetricCPUTemperature,
+	tr181.MetricBoardTemperature, tr181.MetricRadioTemperature,
+	tr181.MetricWiFi2GHzSignal, tr181.MetricWiFi5GHzSignal, tr181.MetricWiFi6GHzSignal,
+	tr181.MetricEthernetBytesSent, tr181.MetricEthernetBytesRecv, tr181.MetricUptime,
 }
 
 // isValidMetricType - проверяет допустимость типа метрики
 func isValidMetricType(mt tr181.MetricType) bool {
-	validTypes := []tr181.MetricType{
-		tr181.MetricCPUUsage, tr181.MetricMemoryUsage, tr181.MetricCPUTemperature,
-		tr181.MetricBoardTemperature, tr181.MetricRadioTemperature,
-		tr181.MetricWiFi2GHzSignal, tr181.MetricWiFi5GHzSignal, tr181.MetricWiFi6GHzSignal,
-		tr181.MetricEthernetBytesSent, tr181.MetricEthernetBytesRecv, tr181.MetricUptime,
-	}
-	for _, vt := range validTypes {
+	for _, vt := range validMetricTypes {
 		if mt == vt {
 			return true
 		}
@@ -402,9 +445,23 @@ func isValidMetricType(mt tr181.MetricType) bool {
 	return false
 }
 
+// validAlertTypes - все типы алертов, доступные через API
+var validAlertTypes = []tr181.AlertType{
+	tr181.AlertHighCPUUsage, tr181.AlertHighMemoryUsage, tr181.AlertHighCPUTemperature,
+	tr181.AlertHighBoardTemperature, tr181.AlertHighRadioTemperature,
+	tr181.AlertLowWiFi, tr181.AlertLowWiFi2GHz, tr181.AlertLowWiFi5GHz, tr181.AlertLowWiFi6GHz,
+	tr181.AlertAnomaly, tr181.AlertDeviceOffline, tr181.AlertDeviceOnline,
+	tr181.AlertDeviceRebooted, tr181.AlertRebootLoop,
+}
+
 // isValidAlertType - проверяет допустимость типа алерта
 func isValidAlertType(at tr181.AlertType) bool {
-	return at == tr181.AlertHighCPUUsage || at == tr181.AlertLowWiFi
+	for _, vt := range validAlertTypes {
+		if at == vt {
+			return true
+		}
+	}
+	return false
 }
 
 // ANSI коды для цветного фона: зелёный (2xx), жёлтый (4xx), красный (5xx)
@@ -0,0 +1,202 @@
+// Запрос метрик: лимиты, постраничная выдача (continuation token) и downsample — общее для HTTP и gRPC.
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	defaultMaxPoints = 10000               // максимум точек в одном ответе по умолчанию
+	defaultMaxRange  = 31 * 24 * time.Hour // максимальный период для сырых точек по умолчанию
+)
+
+// queryLimits — ограничения запросов метрик
+type queryLimits struct {
+	MaxPoints int           // максимум точек в одном ответе (METRIC_MAX_POINTS)
+	MaxRange  time.Duration // максимальная длина периода без downsample (METRIC_MAX_RANGE)
+}
+
+// loadQueryLimits читает лимиты из переменных окружения с дефолтами
+func loadQueryLimits() queryLimits {
+	limits := queryLimits{MaxPoints: defaultMaxPoints, MaxRange: defaultMaxRange}
+	if v, err := strconv.Atoi(os.Getenv("METRIC_MAX_POINTS")); err == nil && v > 0 {
+		limits.MaxPoints = v
+	}
+	if v, err := time.ParseDuration(os.Getenv("METRIC_MAX_RANGE")); err == nil && v > 0 {
+		limits.MaxRange = v
+	}
+	return limits
+}
+
+// metricQuery — параметры запроса метрик после разбора HTTP/gRPC (нулевое время — значение по умолчанию)
+type metricQuery struct {
+	SerialNumber string
+	MetricType   string
+	From         time.Time
+	To           time.Time
+	Limit        int    // 0 — limits.MaxPoints
+	PageToken    string // продолжение с предыдущей страницы
+	Downsample   bool   // усреднять по интервалам вместо постраничной выдачи
+	// Период, как его задал клиент: токен страницы привязан к нему, а не к вычисленным From и To
+	// (now-1h между страницами сдвигается)
+	RangeSpec timeRangeSpec
+}
+
+// Metrics проверяет параметры, права и лимиты и возвращает страницу метрик (из кэша или PostgreSQL)
+func (s *queryService) Metrics(ctx context.Context, q metricQuery) (*database.MetricPage, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if !isValidMetricType(tr181.MetricType(q.MetricType)) {
+		return nil, invalidMetricType(q.MetricType)
+	}
+	var err error
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionMetricRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	limits := s.limits
+	limit := q.Limit
+	switch {
+	case limit < 0:
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "limit", "limit must be positive")
+	case limit == 0:
+		limit = limits.MaxPoints
+	case limit > limits.MaxPoints:
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "limit", "limit exceeds maximum of %d points", limits.MaxPoints)
+	}
+
+	rangeLen := q.To.Sub(q.From)
+	if !q.Downsample && rangeLen > limits.MaxRange {
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "from", "time range exceeds maximum of %s: use a shorter range or downsample", limits.MaxRange)
+	}
+	if q.Downsample && q.PageToken != "" {
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "page_token", "page token cannot be used with downsample")
+	}
+
+	var cursor *database.MetricCursor
+	if q.PageToken != "" {
+		c, hash, err := decodePageToken(q.PageToken)
+		if err != nil {
+			return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "page_token", "invalid page token")
+		}
+		if hash != q.hash() {
+			return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "page_token", "page token does not match the query")
+		}
+		cursor = c
+	}
+
+	if q.Downsample {
+		return s.downsampledMetrics(ctx, q, downsampleBucket(rangeLen, limit))
+	}
+
+	// Сырые точки: страница собирается из чанков кэша и хвоста из PostgreSQL
+	page := &database.MetricPage{Metrics: make([]database.MetricValue, 0, min(limit, 1024))}
+	var last database.MetricPoint
+	more := false
+	err = s.scanPoints(ctx, q.SerialNumber, q.MetricType, q.From, q.To, cursor, func(p database.MetricPoint) bool {
+		if len(page.Metrics) == limit {
+			more = true // есть хотя бы одна точка после страницы
+			return false
+		}
+		page.Metrics = append(page.Metrics, database.MetricValue{Value: p.Value, Time: p.Time().Unix()})
+		last = p
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get metrics: %w", err)
+	}
+	if more {
+		page.NextPageToken = encodePageToken(&database.MetricCursor{Time: last.Time(), ID: last.ID}, q.hash())
+	}
+	return page, nil
+}
+
+// downsampledMetrics — средние по интервалам bucket. Периоды до chunkDownsampleMaxRange
+// усредняются из чанков кэша, более длинные — в PostgreSQL (ответ в кэше stale-while-revalidate)
+func (s *queryService) downsampledMetrics(ctx context.Context, q metricQuery, bucket time.Duration) (*database.MetricPage, error) {
+	page := &database.MetricPage{BucketSeconds: int64(bucket.Seconds())}
+
+	if q.To.Sub(q.From) <= chunkDownsampleMaxRange {
+		var points []database.MetricPoint
+		err := s.scanPoints(ctx, q.SerialNumber, q.MetricType, q.From, q.To, nil, func(p database.MetricPoint) bool {
+			points = append(points, p)
+			return true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metrics: %w", err)
+		}
+		page.Metrics = downsamplePoints(points, bucket)
+		return page, nil
+	}
+
+	cacheKey := fmt.Sprintf("metric:%s:%s:%d:%d:%d", q.MetricType, q.SerialNumber, q.From.Unix(), q.To.Unix(), page.BucketSeconds)
+	return cachedSWR(ctx, s.fill, cacheKey, responseFresh, responseStale, func(ctx context.Context) (*database.MetricPage, error) {
+		metrics, err := s.postgresDB.GetMetricsDownsampled(ctx, q.SerialNumber, q.MetricType, q.From, q.To, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metrics: %w", err)
+		}
+		page.Metrics = metrics
+		if page.Metrics == nil {
+			page.Metrics = []database.MetricValue{} // в JSON — [] вместо null
+		}
+		return page, nil
+	})
+}
+
+// downsampleBucket — интервал усреднения (целые секунды, не меньше 1), дающий не больше limit точек
+func downsampleBucket(rangeLen time.Duration, limit int) time.Duration {
+	seconds := int64(rangeLen.Seconds())/int64(limit) + 1
+	return time.Duration(seconds) * time.Second
+}
+
+// hash — отпечаток запроса в токене страницы: устройство, метрика и период. Токен, применённый
+// к другому запросу, иначе молча пропустил бы данные
+func (q metricQuery) hash() string {
+	r := q.RangeSpec
+	sum := sha256.Sum256([]byte(strings.Join([]string{q.SerialNumber, q.MetricType, r.From, r.To, r.Range, r.TZ}, "\x00")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encodePageToken кодирует курсор и отпечаток запроса в непрозрачный токен: base64("<unix nano>:<id>:<hash>")
+func encodePageToken(c *database.MetricCursor, hash string) string {
+	raw := fmt.Sprintf("%d:%d:%s", c.Time.UnixNano(), c.ID, hash)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodePageToken разбирает токен, созданный encodePageToken
+func decodePageToken(token string) (*database.MetricCursor, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, "", err
+	}
+	parts := strings.SplitN(string(raw), ":", 3)
+	if len(parts) != 3 {
+		return nil, "", fmt.Errorf("malformed page token")
+	}
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, "", err
+	}
+	id, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, "", err
+	}
+	return &database.MetricCursor{Time: time.Unix(0, nanos), ID: id}, parts[2], nil
+}
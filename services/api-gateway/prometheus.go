@@ -0,0 +1,471 @@
+// Prometheus-совместимый HTTP API (подмножество) для Grafana: /api/v1/query, /query_range, /series, /labels.
+// Тип метрики cpu-usage отображается в имя cpu_usage, серийный номер — в метку serial_number.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	promLookback      = 5 * time.Minute // окно поиска последней точки для мгновенного селектора
+	promMaxSeries     = 1000            // максимум рядов на один селектор
+	promMaxSamples    = 2000000         // максимум загружаемых точек на запрос
+	promMaxSteps      = 11000           // максимум шагов query_range (как в Prometheus)
+	promDefaultWindow = 24 * time.Hour  // период по умолчанию для /series и /label/.../values
+)
+
+// errPromLimit — запрос превышает лимиты (ответ 422, как execution error в Prometheus)
+var errPromLimit = errors.New("query exceeds limits")
+
+// promMetricName — имя метрики Prometheus для типа метрики (cpu-usage → cpu_usage)
+func promMetricName(metricType string) string {
+	return strings.ReplaceAll(metricType, "-", "_")
+}
+
+// promSuccess отправляет успешный ответ в формате Prometheus
+func promSuccess(c *gin.Context, data interface{}) {
+	c.JSON(http.StatusOK, gin.H{"status": "success", "data": data})
+}
+
+// promError отправляет ошибку в формате Prometheus
+func promError(c *gin.Context, code int, errType string, err error) {
+	c.JSON(code, gin.H{"status": "error", "errorType": errType, "error": err.Error()})
+}
+
+// promExecError отправляет ошибку вычисления: превышение лимитов — 422, остальное — 500
+func promExecError(c *gin.Context, err error) {
+	if errors.Is(err, errPromLimit) {
+		promError(c, http.StatusUnprocessableEntity, "execution", err)
+		return
+	}
+	promError(c, http.StatusInternalServerError, "internal", err)
+}
+
+// parsePromTime разбирает время: Unix секунды (дробные) или RFC3339. Пусто — def
+func parsePromTime(s string, def time.Time) (time.Time, error) {
+	if s == "" {
+		return def, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		sec, frac := math.Modf(f)
+		return time.Unix(int64(sec), int64(frac*1e9)), nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("cannot parse %q to a valid timestamp", s)
+	}
+	return t, nil
+}
+
+// parsePromStep разбирает шаг: секунды (дробные) или длительность PromQL
+func parsePromStep(s string) (time.Duration, error) {
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		if f <= 0 {
+			return 0, fmt.Errorf("zero or negative query resolution step widths are not accepted")
+		}
+		return time.Duration(f * float64(time.Second)), nil
+	}
+	return parsePromDuration(s)
+}
+
+// checkPromSelectorRanges проверяет, что range-селекторы ([5m]) не длиннее limits.MaxRange:
+// иначе запрос загружал бы период без ограничения, как from/to в /api/v1/metric
+func checkPromSelectorRanges(expr promExpr, limits queryLimits) error {
+	for _, sel := range promSelectors(expr) {
+		if sel.Range > limits.MaxRange {
+			return fmt.Errorf("range selector of %s exceeds maximum of %s", sel.Range, limits.MaxRange)
+		}
+	}
+	return nil
+}
+
+// promQueryHandler - мгновенный запрос (GET/POST /api/v1/query)
+func promQueryHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer, limits queryLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expr, err := parsePromQL(c.Request.FormValue("query"))
+		if err == nil {
+			err = checkPromSelectorRanges(expr, limits)
+		}
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		ts, err := parsePromTime(c.Request.FormValue("time"), time.Now())
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+
+		ev, err := loadPromData(c.Request.Context(), postgresDB, authz, expr, ts, ts)
+		if err != nil {
+			promExecError(c, err)
+			return
+		}
+		v, err := ev.eval(expr, ts.UnixMilli())
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+
+		t := float64(ts.UnixMilli()) / 1000
+		if v.IsScalar {
+			promSuccess(c, gin.H{"resultType": "scalar", "result": []interface{}{t, formatPromValue(v.Scalar)}})
+			return
+		}
+		result := make([]gin.H, len(v.Vector))
+		for i, s := range v.Vector {
+			result[i] = gin.H{"metric": s.Labels, "value": []interface{}{t, formatPromValue(s.Value)}}
+		}
+		promSuccess(c, gin.H{"resultType": "vector", "result": result})
+	}
+}
+
+// promQueryRangeHandler - запрос за период с шагом (GET/POST /api/v1/query_range)
+func promQueryRangeHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer, limits queryLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		expr, err := parsePromQL(c.Request.FormValue("query"))
+		if err == nil {
+			err = checkPromSelectorRanges(expr, limits)
+		}
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		start, err := parsePromTime(c.Request.FormValue("start"), time.Time{})
+		if err == nil && start.IsZero() {
+			err = fmt.Errorf("start is required")
+		}
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		end, err := parsePromTime(c.Request.FormValue("end"), time.Time{})
+		if err == nil && end.IsZero() {
+			err = fmt.Errorf("end is required")
+		}
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		step, err := parsePromStep(c.Request.FormValue("step"))
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		if end.Before(start) {
+			promError(c, http.StatusBadRequest, "bad_data", fmt.Errorf("end timestamp must not be before start time"))
+			return
+		}
+		if end.Sub(start)/step > promMaxSteps {
+			promError(c, http.StatusBadRequest, "bad_data", fmt.Errorf("exceeded maximum resolution of %d points per timeseries", promMaxSteps))
+			return
+		}
+		if end.Sub(start) > limits.MaxRange {
+			promError(c, http.StatusBadRequest, "bad_data", fmt.Errorf("time range exceeds maximum of %s", limits.MaxRange))
+			return
+		}
+
+		ev, err := loadPromData(c.Request.Context(), postgresDB, authz, expr, start, end)
+		if err != nil {
+			promExecError(c, err)
+			return
+		}
+
+		// Вычисляем выражение на каждом шаге и собираем ряды по набору меток
+		type matrixSeries struct {
+			Metric map[string]string `json:"metric"`
+			Values [][]interface{}   `json:"values"`
+		}
+		byKey := make(map[string]*matrixSeries)
+		var order []string
+		for t := start; !t.After(end); t = t.Add(step) {
+			v, err := ev.eval(expr, t.UnixMilli())
+			if err != nil {
+				promError(c, http.StatusBadRequest, "bad_data", err)
+				return
+			}
+			samples := v.Vector
+			if v.IsScalar {
+				samples = []promSample{{Labels: map[string]string{}, Value: v.Scalar}}
+			}
+			for _, s := range samples {
+				key := labelsKey(s.Labels)
+				ms, ok := byKey[key]
+				if !ok {
+					ms = &matrixSeries{Metric: s.Labels}
+					byKey[key] = ms
+					order = append(order, key)
+				}
+				ms.Values = append(ms.Values, []interface{}{float64(t.UnixMilli()) / 1000, formatPromValue(s.Value)})
+			}
+		}
+
+		sort.Strings(order)
+		result := make([]*matrixSeries, len(order))
+		for i, key := range order {
+			result[i] = byKey[key]
+		}
+		promSuccess(c, gin.H{"resultType": "matrix", "result": result})
+	}
+}
+
+// promSeriesHandler - список рядов по селекторам match[] (GET/POST /api/v1/series)
+func promSeriesHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, err := promMetadataRange(c)
+		if err != nil {
+			promError(c, http.StatusBadRequest, "bad_data", err)
+			return
+		}
+		c.Request.ParseForm()
+		matches := c.Request.Form["match[]"]
+		if len(matches) == 0 {
+			promError(c, http.StatusBadRequest, "bad_data", fmt.Errorf("no match[] parameter provided"))
+			return
+		}
+
+		seen := make(map[string]bool)
+		result := []map[string]string{}
+		for _, m := range matches {
+			expr, err := parsePromQL(m)
+			if err != nil {
+				promError(c, http.StatusBadRequest, "bad_data", err)
+				return
+			}
+			sel, ok := expr.(*promSelector)
+			if !ok || sel.Range > 0 {
+				promError(c, http.StatusBadRequest, "bad_data", fmt.Errorf("match[] must be a series selector"))
+				return
+			}
+			keys, err := findPromSeries(c.Request.Context(), postgresDB, authz, sel, from, to)
+			if err != nil {
+				promExecError(c, err)
+				return
+			}
+			for _, k := range keys {
+				labels := promLabels(k)
+				if key := labelsKey(labels); !seen[key] {
+					seen[key] = true
+					result = append(result, labels)
+				}
+			}
+		}
+		promSuccess(c, result)
+	}
+}
+
+// promLabelsHandler - имена меток (GET/POST /api/v1/labels)
+func promLabelsHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		promSuccess(c, []string{promNameLabel, promSerialLabel})
+	}
+}
+
+// promLabelValuesHandler - значения метки (GET /api/v1/label/:name/values)
+func promLabelValuesHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Param("name") {
+		case promNameLabel:
+			names := make([]string, len(validMetricTypes))
+			for i, mt := range validMetricTypes {
+				names[i] = promMetricName(string(mt))
+			}
+			sort.Strings(names)
+			promSuccess(c, names)
+		case promSerialLabel:
+			from, to, err := promMetadataRange(c)
+			if err != nil {
+				promError(c, http.StatusBadRequest, "bad_data", err)
+				return
+			}
+			serials, err := postgresDB.FindSerialNumbers(c.Request.Context(), allMetricTypeNames(), nil, from, to, promMaxSeries)
+			if err == nil {
+				serials, err = authz.FilterDevices(c.Request.Context(), serials)
+			}
+			if err != nil {
+				promExecError(c, err)
+				return
+			}
+			if serials == nil {
+				serials = []string{}
+			}
+			promSuccess(c, serials)
+		default:
+			promSuccess(c, []string{})
+		}
+	}
+}
+
+// promMetadataRange — период для /series и /label/.../values (по умолчанию последние 24 часа)
+func promMetadataRange(c *gin.Context) (time.Time, time.Time, error) {
+	to, err := parsePromTime(c.Request.FormValue("end"), time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := parsePromTime(c.Request.FormValue("start"), to.Add(-promDefaultWindow))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// allMetricTypeNames — все типы метрик строками (для запросов к БД)
+func allMetricTypeNames() []string {
+	names := make([]string, len(validMetricTypes))
+	for i, mt := range validMetricTypes {
+		names[i] = string(mt)
+	}
+	return names
+}
+
+// promLabels — метки ряда
+func promLabels(k database.SeriesKey) map[string]string {
+	return map[string]string{
+		promNameLabel:   promMetricName(k.MetricType),
+		promSerialLabel: k.SerialNumber,
+	}
+}
+
+// findPromSeries находит ряды, подходящие под селектор: имя фильтруется в Go (типов немного),
+// условия на serial_number передаются в SQL. Ряды устройств вне области доступа principal отбрасываются
+func findPromSeries(ctx context.Context, postgresDB *database.PostgresDB, authz *auth.Authorizer, sel *promSelector, from, to time.Time) ([]database.SeriesKey, error) {
+	var metricTypes []string
+	for _, mt := range validMetricTypes {
+		if promMatchersAccept(sel.Matchers, promNameLabel, promMetricName(string(mt))) {
+			metricTypes = append(metricTypes, string(mt))
+		}
+	}
+	var serialMatchers []database.LabelMatcher
+	for _, m := range sel.Matchers {
+		switch m.Label {
+		case promNameLabel:
+			// уже учтено выше
+		case promSerialLabel:
+			serialMatchers = append(serialMatchers, database.LabelMatcher{Op: m.Op, Value: m.Value})
+		default:
+			// Других меток у рядов нет: значение считается пустым
+			if !m.matches("") {
+				return nil, nil
+			}
+		}
+	}
+	if len(metricTypes) == 0 {
+		return nil, nil
+	}
+
+	keys, err := postgresDB.FindSeries(ctx, metricTypes, serialMatchers, from, to, promMaxSeries+1)
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) > promMaxSeries {
+		return nil, fmt.Errorf("%w: selector matches more than %d series", errPromLimit, promMaxSeries)
+	}
+	return filterSeriesKeys(ctx, authz, keys)
+}
+
+// filterSeriesKeys оставляет ряды устройств, доступных principal
+func filterSeriesKeys(ctx context.Context, authz *auth.Authorizer, keys []database.SeriesKey) ([]database.SeriesKey, error) {
+	serialSet := make(map[string]bool)
+	for _, k := range keys {
+		serialSet[k.SerialNumber] = true
+	}
+	allowed, err := authz.FilterDevices(ctx, setKeys(serialSet))
+	if err != nil {
+		return nil, err
+	}
+	if len(allowed) == len(serialSet) {
+		return keys, nil
+	}
+	allowedSet := make(map[string]bool, len(allowed))
+	for _, sn := range allowed {
+		allowedSet[sn] = true
+	}
+	filtered := keys[:0]
+	for _, k := range keys {
+		if allowedSet[k.SerialNumber] {
+			filtered = append(filtered, k)
+		}
+	}
+	return filtered, nil
+}
+
+// promMatchersAccept проверяет все условия на метку label
+func promMatchersAccept(matchers []*promMatcher, label, value string) bool {
+	for _, m := range matchers {
+		if m.Label == label && !m.matches(value) {
+			return false
+		}
+	}
+	return true
+}
+
+// loadPromData загружает точки всех селекторов выражения за [start - окно, end] одним проходом на селектор
+func loadPromData(ctx context.Context, postgresDB *database.PostgresDB, authz *auth.Authorizer, expr promExpr, start, end time.Time) (*promEvaluator, error) {
+	ev := &promEvaluator{series: make(map[*promSelector][]*promSeries), lookback: promLookback}
+	total := 0
+	for _, sel := range promSelectors(expr) {
+		window := promLookback
+		if sel.Range > 0 {
+			window = sel.Range
+		}
+		from := start.Add(-window)
+
+		keys, err := findPromSeries(ctx, postgresDB, authz, sel, from, end)
+		if err != nil {
+			return nil, err
+		}
+		if len(keys) == 0 {
+			continue
+		}
+
+		// Загружаем точки найденных рядов через курсор выгрузки
+		bySeries := make(map[database.SeriesKey]*promSeries, len(keys))
+		serialSet, typeSet := make(map[string]bool), make(map[string]bool)
+		for _, k := range keys {
+			bySeries[k] = &promSeries{Labels: promLabels(k)}
+			serialSet[k.SerialNumber] = true
+			typeSet[k.MetricType] = true
+		}
+		err = postgresDB.StreamMetrics(ctx, setKeys(serialSet), setKeys(typeSet), from, end, func(r database.MetricRow) error {
+			s, ok := bySeries[database.SeriesKey{SerialNumber: r.SerialNumber, MetricType: r.MetricType}]
+			if !ok {
+				return nil
+			}
+			total++
+			if total > promMaxSamples {
+				return fmt.Errorf("%w: query loads more than %d samples", errPromLimit, promMaxSamples)
+			}
+			s.Times = append(s.Times, r.Time.UnixMilli())
+			s.Values = append(s.Values, float64(r.Value))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range keys {
+			ev.series[sel] = append(ev.series[sel], bySeries[k])
+		}
+	}
+	return ev, nil
+}
+
+// setKeys — ключи множества строк
+func setKeys(set map[string]bool) []string {
+	out := make([]string, 0, len(set))
+	for k := range set {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,597 @@
+// Подмножество PromQL для Prometheus-совместимого API: селекторы, rate, avg_over_time, topk
+// и арифметика над числами (нужна для проверки datasource в Grafana).
+package main
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	promNameLabel   = "__name__"      // метка с именем метрики
+	promSerialLabel = "serial_number" // метка с серийным номером устройства
+)
+
+// promExpr — узел разобранного выражения
+type promExpr interface{}
+
+// promNumber — числовой литерал
+type promNumber struct {
+	Value float64
+}
+
+// promBinary — арифметика над двумя скалярами (+ - * /)
+type promBinary struct {
+	Op          byte
+	Left, Right promExpr
+}
+
+// promMatcher — условие на метку в селекторе
+type promMatcher struct {
+	Label string
+	Op    string // =, !=, =~, !~
+	Value string
+	re    *regexp.Regexp
+}
+
+// matches проверяет значение метки
+func (m *promMatcher) matches(v string) bool {
+	switch m.Op {
+	case "=":
+		return v == m.Value
+	case "!=":
+		return v != m.Value
+	case "=~":
+		return m.re.MatchString(v)
+	default: // !~
+		return !m.re.MatchString(v)
+	}
+}
+
+// promSelector — селектор рядов; Range > 0 — range-селектор ([5m])
+type promSelector struct {
+	Matchers []*promMatcher
+	Range    time.Duration
+}
+
+// promCall — вызов функции: rate, avg_over_time, topk
+type promCall struct {
+	Func string
+	Args []promExpr
+}
+
+// promFuncs — поддерживаемые функции и число аргументов
+var promFuncs = map[string]int{
+	"rate":          1,
+	"avg_over_time": 1,
+	"topk":          2,
+}
+
+// parsePromQL разбирает выражение из поддерживаемого подмножества PromQL
+func parsePromQL(input string) (promExpr, error) {
+	p := &promParser{in: input}
+	expr, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.in) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.in[p.pos:], p.pos)
+	}
+	return expr, nil
+}
+
+// promParser — рекурсивный спуск по строке запроса
+type promParser struct {
+	in  string
+	pos int
+}
+
+func (p *promParser) skipSpaces() {
+	for p.pos < len(p.in) && unicode.IsSpace(rune(p.in[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek возвращает следующий непробельный символ (0 — конец строки)
+func (p *promParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.in) {
+		return 0
+	}
+	return p.in[p.pos]
+}
+
+func (p *promParser) expect(c byte) error {
+	if p.peek() != c {
+		return fmt.Errorf("expected %q at position %d", c, p.pos)
+	}
+	p.pos++
+	return nil
+}
+
+// parseExpr: term (('+'|'-') term)*
+func (p *promParser) parseExpr() (promExpr, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '+' || c == '-'; c = p.peek() {
+		p.pos++
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &promBinary{Op: c, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseTerm: primary (('*'|'/') primary)*
+func (p *promParser) parseTerm() (promExpr, error) {
+	left, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for c := p.peek(); c == '*' || c == '/'; c = p.peek() {
+		p.pos++
+		right, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		left = &promBinary{Op: c, Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parsePrimary: число | '(' expr ')' | функция(...) | селектор
+func (p *promParser) parsePrimary() (promExpr, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of query")
+	case c == '(':
+		p.pos++
+		expr, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(')')
+	case c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case c == '{':
+		return p.parseSelector("")
+	}
+
+	name := p.parseIdent()
+	if name == "" {
+		return nil, fmt.Errorf("unexpected %q at position %d", c, p.pos)
+	}
+	if p.peek() != '(' {
+		return p.parseSelector(name)
+	}
+	nargs, ok := promFuncs[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported function %q", name)
+	}
+	p.pos++ // '('
+	call := &promCall{Func: name}
+	for i := 0; i < nargs; i++ {
+		if i > 0 {
+			if err := p.expect(','); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+	}
+	if err := p.expect(')'); err != nil {
+		return nil, err
+	}
+	return call, validatePromCall(call)
+}
+
+// validatePromCall проверяет типы аргументов функции
+func validatePromCall(call *promCall) error {
+	switch call.Func {
+	case "rate", "avg_over_time":
+		sel, ok := call.Args[0].(*promSelector)
+		if !ok || sel.Range == 0 {
+			return fmt.Errorf("%s: expected range vector selector, e.g. metric[5m]", call.Func)
+		}
+	case "topk":
+		if _, ok := call.Args[0].(*promNumber); !ok {
+			return fmt.Errorf("topk: first argument must be a number")
+		}
+		if sel, ok := call.Args[1].(*promSelector); ok && sel.Range > 0 {
+			return fmt.Errorf("topk: expected instant vector")
+		}
+	}
+	return nil
+}
+
+func (p *promParser) parseNumber() (promExpr, error) {
+	start := p.pos
+	if p.in[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(p.in) && (p.in[p.pos] == '.' || p.in[p.pos] == 'e' || (p.in[p.pos] >= '0' && p.in[p.pos] <= '9')) {
+		p.pos++
+	}
+	v, err := strconv.ParseFloat(p.in[start:p.pos], 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %q", p.in[start:p.pos])
+	}
+	return &promNumber{Value: v}, nil
+}
+
+// parseIdent читает имя метрики/метки/функции: [a-zA-Z_:][a-zA-Z0-9_:]*
+func (p *promParser) parseIdent() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.in) {
+		c := p.in[p.pos]
+		if c == '_' || c == ':' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (p.pos > start && c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.in[start:p.pos]
+}
+
+// parseSelector: [name] ['{' matchers '}'] ['[' duration ']']
+func (p *promParser) parseSelector(name string) (promExpr, error) {
+	sel := &promSelector{}
+	if name != "" {
+		sel.Matchers = append(sel.Matchers, &promMatcher{Label: promNameLabel, Op: "=", Value: name})
+	}
+	if p.peek() == '{' {
+		p.pos++
+		for p.peek() != '}' {
+			m, err := p.parseMatcher()
+			if err != nil {
+				return nil, err
+			}
+			sel.Matchers = append(sel.Matchers, m)
+			if p.peek() == ',' {
+				p.pos++
+			} else if p.peek() != '}' {
+				return nil, fmt.Errorf("expected ',' or '}' at position %d", p.pos)
+			}
+		}
+		p.pos++ // '}'
+	}
+	if len(sel.Matchers) == 0 {
+		return nil, fmt.Errorf("vector selector must contain at least one matcher")
+	}
+	if p.peek() == '[' {
+		p.pos++
+		end := strings.IndexByte(p.in[p.pos:], ']')
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed range selector")
+		}
+		d, err := parsePromDuration(strings.TrimSpace(p.in[p.pos : p.pos+end]))
+		if err != nil {
+			return nil, err
+		}
+		sel.Range = d
+		p.pos += end + 1
+	}
+	return sel, nil
+}
+
+// parseMatcher: label op "value"
+func (p *promParser) parseMatcher() (*promMatcher, error) {
+	label := p.parseIdent()
+	if label == "" {
+		return nil, fmt.Errorf("expected label name at position %d", p.pos)
+	}
+	p.skipSpaces()
+	var op string
+	for _, candidate := range []string{"=~", "!~", "!=", "="} {
+		if strings.HasPrefix(p.in[p.pos:], candidate) {
+			op = candidate
+			break
+		}
+	}
+	if op == "" {
+		return nil, fmt.Errorf("expected matcher operator at position %d", p.pos)
+	}
+	p.pos += len(op)
+	value, err := p.parseString()
+	if err != nil {
+		return nil, err
+	}
+	m := &promMatcher{Label: label, Op: op, Value: value}
+	if op == "=~" || op == "!~" {
+		if m.re, err = regexp.Compile("^(?:" + value + ")$"); err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %w", value, err)
+		}
+	}
+	return m, nil
+}
+
+// parseString читает строку в двойных, одинарных или обратных кавычках
+func (p *promParser) parseString() (string, error) {
+	q := p.peek()
+	if q != '"' && q != '\'' && q != '`' {
+		return "", fmt.Errorf("expected string at position %d", p.pos)
+	}
+	start := p.pos
+	p.pos++
+	for p.pos < len(p.in) && p.in[p.pos] != q {
+		if p.in[p.pos] == '\\' && q != '`' {
+			p.pos++
+		}
+		p.pos++
+	}
+	if p.pos >= len(p.in) {
+		return "", fmt.Errorf("unterminated string")
+	}
+	p.pos++
+	raw := p.in[start:p.pos]
+	if q == '\'' {
+		// Одинарные кавычки: меняем на двойные для strconv.Unquote
+		raw = `"` + strings.ReplaceAll(raw[1:len(raw)-1], `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(raw)
+}
+
+// promDurationUnits — единицы длительности PromQL
+var promDurationUnits = map[string]time.Duration{
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+	"d":  24 * time.Hour,
+	"w":  7 * 24 * time.Hour,
+	"y":  365 * 24 * time.Hour,
+}
+
+// promDurationRe — одна или несколько пар "число+единица", например 1h30m
+var promDurationRe = regexp.MustCompile(`^(?:[0-9]+(?:ms|s|m|h|d|w|y))+$`)
+var promDurationPartRe = regexp.MustCompile(`([0-9]+)(ms|s|m|h|d|w|y)`)
+
+// parsePromDuration разбирает длительность PromQL (5m, 1h30m, 7d)
+func parsePromDuration(s string) (time.Duration, error) {
+	if !promDurationRe.MatchString(s) {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	var d time.Duration
+	for _, part := range promDurationPartRe.FindAllStringSubmatch(s, -1) {
+		n, _ := strconv.ParseInt(part[1], 10, 64)
+		d += time.Duration(n) * promDurationUnits[part[2]]
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("duration must be positive")
+	}
+	return d, nil
+}
+
+// promSelectors собирает все селекторы выражения (для предварительной загрузки данных)
+func promSelectors(expr promExpr) []*promSelector {
+	switch e := expr.(type) {
+	case *promSelector:
+		return []*promSelector{e}
+	case *promCall:
+		var out []*promSelector
+		for _, a := range e.Args {
+			out = append(out, promSelectors(a)...)
+		}
+		return out
+	case *promBinary:
+		return append(promSelectors(e.Left), promSelectors(e.Right)...)
+	}
+	return nil
+}
+
+// promSeries — загруженный ряд: метки и точки, отсортированные по времени (мс)
+type promSeries struct {
+	Labels map[string]string
+	Times  []int64
+	Values []float64
+}
+
+// promSample — значение ряда в момент вычисления
+type promSample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// promValue — результат вычисления: скаляр или мгновенный вектор
+type promValue struct {
+	Scalar   float64
+	IsScalar bool
+	Vector   []promSample
+}
+
+// promEvaluator вычисляет выражение в заданный момент по заранее загруженным рядам
+type promEvaluator struct {
+	series   map[*promSelector][]*promSeries
+	lookback time.Duration
+}
+
+// eval вычисляет выражение в момент t (Unix миллисекунды)
+func (ev *promEvaluator) eval(expr promExpr, t int64) (promValue, error) {
+	switch e := expr.(type) {
+	case *promNumber:
+		return promValue{Scalar: e.Value, IsScalar: true}, nil
+	case *promBinary:
+		l, err := ev.eval(e.Left, t)
+		if err != nil {
+			return promValue{}, err
+		}
+		r, err := ev.eval(e.Right, t)
+		if err != nil {
+			return promValue{}, err
+		}
+		if !l.IsScalar || !r.IsScalar {
+			return promValue{}, fmt.Errorf("binary operators are supported only between numbers")
+		}
+		return promValue{Scalar: applyPromOp(e.Op, l.Scalar, r.Scalar), IsScalar: true}, nil
+	case *promSelector:
+		return promValue{Vector: ev.instant(e, t)}, nil
+	case *promCall:
+		return ev.call(e, t)
+	}
+	return promValue{}, fmt.Errorf("unsupported expression")
+}
+
+// applyPromOp выполняет арифметическую операцию
+func applyPromOp(op byte, a, b float64) float64 {
+	switch op {
+	case '+':
+		return a + b
+	case '-':
+		return a - b
+	case '*':
+		return a * b
+	default:
+		return a / b // деление на 0 даёт ±Inf/NaN, как в Prometheus
+	}
+}
+
+// instant — последнее значение каждого ряда в окне lookback до t
+func (ev *promEvaluator) instant(sel *promSelector, t int64) []promSample {
+	var out []promSample
+	minT := t - ev.lookback.Milliseconds()
+	for _, s := range ev.series[sel] {
+		i := sort.Search(len(s.Times), func(i int) bool { return s.Times[i] > t }) - 1
+		if i >= 0 && s.Times[i] > minT {
+			out = append(out, promSample{Labels: s.Labels, Value: s.Values[i]})
+		}
+	}
+	return out
+}
+
+// window — точки ряда в полуинтервале (t-rng, t]
+func window(s *promSeries, t int64, rng time.Duration) ([]int64, []float64) {
+	lo := sort.Search(len(s.Times), func(i int) bool { return s.Times[i] > t-rng.Milliseconds() })
+	hi := sort.Search(len(s.Times), func(i int) bool { return s.Times[i] > t })
+	return s.Times[lo:hi], s.Values[lo:hi]
+}
+
+// call вычисляет функцию
+func (ev *promEvaluator) call(c *promCall, t int64) (promValue, error) {
+	switch c.Func {
+	case "rate", "avg_over_time":
+		sel := c.Args[0].(*promSelector)
+		var out []promSample
+		for _, s := range ev.series[sel] {
+			times, values := window(s, t, sel.Range)
+			var v float64
+			var ok bool
+			if c.Func == "rate" {
+				v, ok = promRate(times, values)
+			} else {
+				v, ok = promAvg(values)
+			}
+			if ok {
+				out = append(out, promSample{Labels: withoutName(s.Labels), Value: v})
+			}
+		}
+		return promValue{Vector: out}, nil
+	case "topk":
+		k := int(c.Args[0].(*promNumber).Value)
+		arg, err := ev.eval(c.Args[1], t)
+		if err != nil {
+			return promValue{}, err
+		}
+		if arg.IsScalar {
+			return promValue{}, fmt.Errorf("topk: expected instant vector")
+		}
+		vec := append([]promSample(nil), arg.Vector...)
+		sort.SliceStable(vec, func(i, j int) bool { return vec[i].Value > vec[j].Value })
+		if k < 0 {
+			k = 0
+		}
+		if len(vec) > k {
+			vec = vec[:k]
+		}
+		return promValue{Vector: vec}, nil
+	}
+	return promValue{}, fmt.Errorf("unsupported function %q", c.Func)
+}
+
+// promRate — скорость роста счётчика в секунду с учётом сбросов (без экстраполяции на границы окна)
+func promRate(times []int64, values []float64) (float64, bool) {
+	if len(values) < 2 {
+		return 0, false
+	}
+	increase := 0.0
+	for i := 1; i < len(values); i++ {
+		if values[i] < values[i-1] {
+			increase += values[i] // сброс счётчика (например после перезагрузки)
+		} else {
+			increase += values[i] - values[i-1]
+		}
+	}
+	seconds := float64(times[len(times)-1]-times[0]) / 1000
+	if seconds <= 0 {
+		return 0, false
+	}
+	return increase / seconds, true
+}
+
+// promAvg — среднее значение точек окна
+func promAvg(values []float64) (float64, bool) {
+	if len(values) == 0 {
+		return 0, false
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values)), true
+}
+
+// withoutName — копия меток без __name__ (функции над рядами убирают имя, как в Prometheus)
+func withoutName(labels map[string]string) map[string]string {
+	out := make(map[string]string, len(labels))
+	for k, v := range labels {
+		if k != promNameLabel {
+			out[k] = v
+		}
+	}
+	return out
+}
+
+// labelsKey — стабильный ключ набора меток (для сборки матрицы по шагам)
+func labelsKey(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	for _, k := range keys {
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(strconv.Quote(labels[k]))
+		b.WriteByte(',')
+	}
+	return b.String()
+}
+
+// formatPromValue — значение в строковом виде, как его отдаёт Prometheus
+func formatPromValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
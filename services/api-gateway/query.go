@@ -0,0 +1,126 @@
+// Сервис запросов — общий для всех транспортов: HTTP /api/v1 (Gin), gRPC и REST /api/v2 (gRPC-Gateway).
+// Транспорт только разбирает параметры и форматирует ответ; проверка параметров, значения
+// по умолчанию, права доступа, кэш и обращения к хранилищу — здесь.
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+// defaultQueryWindow — период запроса, если from не задан
+const defaultQueryWindow = 24 * time.Hour
+
+// Кэш ответов (статистика алертов, длинный downsample): свежий ответ, затем ещё responseStale
+// он отдаётся устаревшим, пока обновляется в фоне
+const (
+	responseFresh = 30 * time.Second
+	responseStale = 5 * time.Minute
+)
+
+// queryService — запросы метрик, алертов и состояния устройств
+type queryService struct {
+	postgresDB *database.PostgresDB // PostgreSQL (метрики, алерты)
+	redisCache *database.RedisCache // Redis (кэш, состояние устройств)
+	limits     queryLimits          // лимиты запросов метрик
+	authz      *auth.Authorizer     // проверка прав (nil — аутентификация выключена)
+	fill       *cacheFiller         // защита от лавины промахов кэша
+}
+
+// alertQuery — параметры запроса статистики алертов (нулевое время — значение по умолчанию)
+type alertQuery struct {
+	SerialNumber string
+	AlertType    string
+	Severities   []string // только эти уровни важности (пусто — все)
+	From         time.Time
+	To           time.Time
+}
+
+// AlertStats возвращает статистику алертов устройства за период (кэш stale-while-revalidate)
+func (s *queryService) AlertStats(ctx context.Context, q alertQuery) (*database.AlertStats, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if !isValidAlertType(tr181.AlertType(q.AlertType)) {
+		return nil, invalidAlertType(q.AlertType)
+	}
+	severities, err := normalizeSeverities(q.Severities)
+	if err != nil {
+		return nil, err
+	}
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionAlertRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	cacheKey := fmt.Sprintf("alert:%s:%s:%d:%d:%s", q.AlertType, q.SerialNumber, q.From.Unix(), q.To.Unix(), strings.Join(severities, ","))
+	return cachedSWR(ctx, s.fill, cacheKey, responseFresh, responseStale, func(ctx context.Context) (*database.AlertStats, error) {
+		stats, err := s.postgresDB.GetAlertStats(ctx, q.SerialNumber, q.AlertType, q.From, q.To, severities)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get alert stats: %w", err)
+		}
+		// В разбивке — все запрошенные (или все известные) уровни, в том числе без алертов
+		keys := severities
+		if len(keys) == 0 {
+			for _, sev := range tr181.AlertSeverities {
+				keys = append(keys, string(sev))
+			}
+		}
+		for _, sev := range keys {
+			if _, ok := stats.Severities[sev]; !ok {
+				stats.Severities[sev] = 0
+			}
+		}
+		return stats, nil
+	})
+}
+
+// normalizeSeverities проверяет уровни важности фильтра: пустые и повторы отбрасываются,
+// результат упорядочен по возрастанию уровня (стабильный ключ кэша)
+func normalizeSeverities(raw []string) ([]string, error) {
+	var severities []string
+	seen := make(map[tr181.AlertSeverity]bool, len(raw))
+	for _, v := range raw {
+		sev := tr181.AlertSeverity(strings.TrimSpace(v))
+		if sev == "" || seen[sev] {
+			continue
+		}
+		if sev.Rank() == 0 {
+			return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "severity", "invalid severity: %s", sev)
+		}
+		seen[sev] = true
+	}
+	for _, sev := range tr181.AlertSeverities {
+		if seen[sev] {
+			severities = append(severities, string(sev))
+		}
+	}
+	return severities, nil
+}
+
+// DeviceStates возвращает последние состояния устройств и список устройств без состояния.
+// field — имя параметра со списком устройств (для ошибок валидации)
+func (s *queryService) DeviceStates(ctx context.Context, raw []string, field string) ([]database.DeviceState, []string, error) {
+	serials, err := normalizeSerials(raw, field)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionStateRead, serials...); err != nil {
+		return nil, nil, err
+	}
+
+	states, err := s.redisCache.GetDeviceStates(ctx, serials)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get device state: %w", err)
+	}
+	return states, missingSerials(serials, states), nil
+}
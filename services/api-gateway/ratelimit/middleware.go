@@ -0,0 +1,132 @@
+// Gin middleware и gRPC interceptors. Квоты клиентов ставятся после аутентификации, чтобы считаться на ключ,
+// а не на IP; IP-квота (IPMiddleware, IPUnaryInterceptor) — до неё.
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Middleware ограничивает частоту HTTP запросов: превышение — 429 с заголовком Retry-After
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return httpMiddleware(func(c *gin.Context) Decision {
+		routePath := c.FullPath()
+		if routePath == "" {
+			routePath = c.Request.URL.Path
+		}
+		return l.Allow(c.Request.Context(), routePath, c.ClientIP())
+	})
+}
+
+// IPMiddleware ограничивает частоту HTTP запросов с одного IP (ставится до аутентификации)
+func (l *Limiter) IPMiddleware() gin.HandlerFunc {
+	return httpMiddleware(func(c *gin.Context) Decision {
+		return l.AllowIP(c.Request.Context(), c.ClientIP())
+	})
+}
+
+// httpMiddleware отвечает 429 с заголовком Retry-After, если allow не пропускает запрос
+func httpMiddleware(allow func(c *gin.Context) Decision) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := allow(c)
+		if d.Limit > 0 {
+			c.Header("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+			c.Header("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		}
+		if !d.Allowed {
+			seconds := retryAfterSeconds(d.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded", "code": "RATE_LIMITED", "retry_after_seconds": seconds})
+			return
+		}
+		c.Next()
+	}
+}
+
+// UnaryInterceptor ограничивает частоту unary вызовов
+func (l *Limiter) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return unaryInterceptor(func(ctx context.Context, method string) Decision {
+		return l.Allow(ctx, method, peerIP(ctx))
+	})
+}
+
+// StreamInterceptor ограничивает частоту открытия потоков (один токен на поток)
+func (l *Limiter) StreamInterceptor() grpc.StreamServerInterceptor {
+	return streamInterceptor(func(ctx context.Context, method string) Decision {
+		return l.Allow(ctx, method, peerIP(ctx))
+	})
+}
+
+// IPUnaryInterceptor ограничивает частоту unary вызовов с одного IP (ставится до аутентификации)
+func (l *Limiter) IPUnaryInterceptor() grpc.UnaryServerInterceptor {
+	return unaryInterceptor(func(ctx context.Context, _ string) Decision {
+		return l.AllowIP(ctx, peerIP(ctx))
+	})
+}
+
+// IPStreamInterceptor ограничивает частоту открытия потоков с одного IP (ставится до аутентификации)
+func (l *Limiter) IPStreamInterceptor() grpc.StreamServerInterceptor {
+	return streamInterceptor(func(ctx context.Context, _ string) Decision {
+		return l.AllowIP(ctx, peerIP(ctx))
+	})
+}
+
+// grpcAllowFunc — проверка квоты для вызова метода method
+type grpcAllowFunc func(ctx context.Context, method string) Decision
+
+func unaryInterceptor(allow grpcAllowFunc) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := allowGRPC(ctx, allow(ctx, info.FullMethod)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func streamInterceptor(allow grpcAllowFunc) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allowGRPC(ss.Context(), allow(ss.Context(), info.FullMethod)); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
+
+// allowGRPC возвращает ResourceExhausted с RetryInfo и заголовком retry-after, если d не пропускает вызов
+func allowGRPC(ctx context.Context, d Decision) error {
+	if d.Allowed {
+		return nil
+	}
+	seconds := retryAfterSeconds(d.RetryAfter)
+	grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(seconds)))
+
+	st := status.New(codes.ResourceExhausted, "rate limit exceeded")
+	if withInfo, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(d.RetryAfter)}); err == nil {
+		st = withInfo
+	}
+	return st.Err()
+}
+
+// peerIP — IP адрес gRPC клиента
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "unknown"
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
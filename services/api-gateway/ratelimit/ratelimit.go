@@ -0,0 +1,220 @@
+// Package ratelimit — ограничение частоты запросов api-gateway: token bucket в Redis
+// (общий для всех реплик) на клиента (API-ключ/JWT subject или IP) и маршрут. Общий код для HTTP (Gin) и gRPC.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	// defaultRate, defaultBurst — квота по умолчанию: 20 запросов в секунду, всплеск до 40
+	defaultRate  = 20
+	defaultBurst = 40
+	// defaultIPRate, defaultIPBurst — квота IP до аутентификации (за одним IP может быть несколько клиентов)
+	defaultIPRate  = 100
+	defaultIPBurst = 200
+	// errorLogInterval — не чаще одного сообщения об ошибке Redis за интервал
+	errorLogInterval = time.Minute
+)
+
+// Store — хранилище корзин (реализуется database.RedisCache)
+type Store interface {
+	TakeToken(ctx context.Context, key string, rate float64, burst int) (*database.RateLimitResult, error)
+}
+
+// Quota — квота: Rate запросов в секунду в среднем, Burst подряд
+type Quota struct {
+	Rate  float64
+	Burst int
+}
+
+// route — квота для маршрутов с префиксом Prefix (nil Quota — без ограничений)
+type route struct {
+	Prefix string
+	Quota  *Quota
+}
+
+// Config — настройки из переменных окружения
+type Config struct {
+	Default *Quota  // RATE_LIMIT_DEFAULT — квота маршрутов без своей квоты (nil — без ограничений)
+	Routes  []route // RATE_LIMIT_ROUTES — квоты по префиксу маршрута, самый длинный префикс первым
+	IP      *Quota  // RATE_LIMIT_IP — квота IP на все маршруты до аутентификации (nil — без ограничений)
+}
+
+// LoadConfig читает настройки:
+//
+//	RATE_LIMIT_DEFAULT=20:40 — запросов в секунду:всплеск (off — выключить ограничение)
+//	RATE_LIMIT_ROUTES=/api/v1/export=0.2:2,/tr181.TR181Api/GetMetric=10:20,/api/v1/state=off
+//	RATE_LIMIT_IP=100:200 — до аутентификации, на IP
+//
+// Префиксы HTTP маршрутов сравниваются с шаблоном маршрута Gin (/api/v1/metric/:metricType),
+// gRPC — с полным именем метода
+func LoadConfig() (Config, error) {
+	cfg := Config{
+		Default: &Quota{Rate: defaultRate, Burst: defaultBurst},
+		IP:      &Quota{Rate: defaultIPRate, Burst: defaultIPBurst},
+	}
+	if v := strings.TrimSpace(os.Getenv("RATE_LIMIT_DEFAULT")); v != "" {
+		q, err := parseQuota(v)
+		if err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT_DEFAULT: %w", err)
+		}
+		cfg.Default = q
+	}
+	if v := strings.TrimSpace(os.Getenv("RATE_LIMIT_IP")); v != "" {
+		q, err := parseQuota(v)
+		if err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT_IP: %w", err)
+		}
+		cfg.IP = q
+	}
+	for _, item := range strings.Split(os.Getenv("RATE_LIMIT_ROUTES"), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		prefix, value, ok := strings.Cut(item, "=")
+		if !ok || !strings.HasPrefix(prefix, "/") {
+			return cfg, fmt.Errorf("RATE_LIMIT_ROUTES: invalid entry %q: use /prefix=rate:burst", item)
+		}
+		q, err := parseQuota(value)
+		if err != nil {
+			return cfg, fmt.Errorf("RATE_LIMIT_ROUTES: %s: %w", prefix, err)
+		}
+		cfg.Routes = append(cfg.Routes, route{Prefix: prefix, Quota: q})
+	}
+	sort.SliceStable(cfg.Routes, func(i, j int) bool {
+		return len(cfg.Routes[i].Prefix) > len(cfg.Routes[j].Prefix)
+	})
+	return cfg, nil
+}
+
+// parseQuota разбирает "rate:burst" или "off" (nil — без ограничений)
+func parseQuota(s string) (*Quota, error) {
+	if strings.EqualFold(s, "off") {
+		return nil, nil
+	}
+	rateStr, burstStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return nil, fmt.Errorf("invalid quota %q: use rate:burst or off", s)
+	}
+	rate, err := strconv.ParseFloat(rateStr, 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate %q", rateStr)
+	}
+	burst, err := strconv.Atoi(burstStr)
+	if err != nil || burst < 1 {
+		return nil, fmt.Errorf("invalid burst %q", burstStr)
+	}
+	return &Quota{Rate: rate, Burst: burst}, nil
+}
+
+// Decision — результат проверки запроса
+type Decision struct {
+	Allowed    bool
+	Limit      int // ёмкость корзины (0 — маршрут без ограничений)
+	Remaining  int
+	RetryAfter time.Duration
+}
+
+// Limiter ограничивает частоту запросов. nil — ограничение выключено
+type Limiter struct {
+	cfg   Config
+	store Store
+
+	mu          sync.Mutex
+	lastErrorAt time.Time
+}
+
+// New создаёт Limiter. Если ни у одного маршрута нет квоты — возвращает nil
+func New(cfg Config, store Store) *Limiter {
+	enabled := cfg.Default != nil || cfg.IP != nil
+	for _, r := range cfg.Routes {
+		enabled = enabled || r.Quota != nil
+	}
+	if !enabled {
+		return nil
+	}
+	return &Limiter{cfg: cfg, store: store}
+}
+
+// Allow забирает токен клиента для маршрута routePath. При недоступном Redis запрос пропускается:
+// ограничение частоты не должно останавливать API
+func (l *Limiter) Allow(ctx context.Context, routePath, clientIP string) Decision {
+	if l == nil {
+		return Decision{Allowed: true}
+	}
+	rule, q := l.quota(routePath)
+	if q == nil {
+		return Decision{Allowed: true}
+	}
+
+	return l.take(ctx, "ratelimit:"+rule+":"+clientKey(ctx, clientIP), q)
+}
+
+// AllowIP забирает токен IP-квоты. Проверяется до аутентификации: перебор неверных ключей
+// с одного адреса не доходит до PostgreSQL
+func (l *Limiter) AllowIP(ctx context.Context, clientIP string) Decision {
+	if l == nil || l.cfg.IP == nil {
+		return Decision{Allowed: true}
+	}
+	return l.take(ctx, "ratelimit:ip:"+clientIP, l.cfg.IP)
+}
+
+// take забирает токен корзины key с квотой q
+func (l *Limiter) take(ctx context.Context, key string, q *Quota) Decision {
+	res, err := l.store.TakeToken(ctx, key, q.Rate, q.Burst)
+	if err != nil {
+		l.logError(err)
+		return Decision{Allowed: true, Limit: q.Burst}
+	}
+	return Decision{Allowed: res.Allowed, Limit: q.Burst, Remaining: res.Remaining, RetryAfter: res.RetryAfter}
+}
+
+// quota выбирает квоту по самому длинному совпавшему префиксу; rule — имя корзины
+func (l *Limiter) quota(routePath string) (string, *Quota) {
+	for _, r := range l.cfg.Routes {
+		if strings.HasPrefix(routePath, r.Prefix) {
+			return r.Prefix, r.Quota
+		}
+	}
+	return "default", l.cfg.Default
+}
+
+// clientKey — идентификатор клиента: principal (API-ключ или JWT subject), без аутентификации — IP
+func clientKey(ctx context.Context, clientIP string) string {
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		return p.ID
+	}
+	return "ip:" + clientIP
+}
+
+// logError пишет ошибку Redis не чаще errorLogInterval
+func (l *Limiter) logError(err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if time.Since(l.lastErrorAt) < errorLogInterval {
+		return
+	}
+	l.lastErrorAt = time.Now()
+	log.Printf("ratelimit: %v (requests are not limited)", err)
+}
+
+// retryAfterSeconds — значение Retry-After в целых секундах (не меньше 1)
+func retryAfterSeconds(d time.Duration) int {
+	s := int((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		s = 1
+	}
+	return s
+}
@@ -0,0 +1,102 @@
+// История перезагрузок устройства (алерты device-rebooted от alert-processor): HTTP и gRPC обработчики
+// поверх queryService.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	defaultRebootLimit = 100  // перезагрузок в ответе по умолчанию
+	maxRebootLimit     = 1000 // максимум перезагрузок в ответе
+)
+
+// rebootQuery — параметры запроса перезагрузок (нулевое время — значение по умолчанию)
+type rebootQuery struct {
+	SerialNumber string
+	From         time.Time
+	To           time.Time
+	Limit        int // 0 — defaultRebootLimit
+}
+
+// Reboots возвращает перезагрузки устройства за период
+func (s *queryService) Reboots(ctx context.Context, q rebootQuery) ([]database.Reboot, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	var err error
+	if q.Limit, err = resultLimit(q.Limit, defaultRebootLimit, maxRebootLimit, "reboots"); err != nil {
+		return nil, err
+	}
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionAlertRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	reboots, err := s.postgresDB.GetReboots(ctx, q.SerialNumber, q.From, q.To, q.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get reboots: %w", err)
+	}
+	return reboots, nil
+}
+
+// GetReboots - gRPC метод получения истории перезагрузок
+func (s *apiServer) GetReboots(ctx context.Context, req *tr181pb.RebootRequest) (*tr181pb.RebootResponse, error) {
+	from, to, err := grpcTimeRange(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+
+	reboots, err := s.svc.Reboots(ctx, rebootQuery{
+		SerialNumber: req.SerialNumber,
+		From:         from,
+		To:           to,
+		Limit:        int(req.Limit),
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to get reboots")
+	}
+
+	resp := &tr181pb.RebootResponse{Reboots: make([]*tr181pb.Reboot, len(reboots))}
+	for i, r := range reboots {
+		resp.Reboots[i] = &tr181pb.Reboot{Time: r.Time.Unix(), UptimeBeforeSeconds: r.UptimeBefore, Loop: r.Loop}
+	}
+	return resp, nil
+}
+
+// getRebootsHandler - HTTP обработчик истории перезагрузок
+// (GET /api/v1/reboots?serial-number=&from=&to=&limit=)
+func getRebootsHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, ok := parseTimeRange(c)
+		if !ok {
+			return
+		}
+		limit, ok := parseLimit(c)
+		if !ok {
+			return
+		}
+
+		reboots, err := svc.Reboots(c.Request.Context(), rebootQuery{
+			SerialNumber: c.Query("serial-number"),
+			From:         from,
+			To:           to,
+			Limit:        limit,
+		})
+		if err != nil {
+			writeError(c, err, "failed to get reboots")
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"reboots": reboots})
+	}
+}
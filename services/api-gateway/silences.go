@@ -0,0 +1,393 @@
+// Тишины и окна обслуживания: alert-processor сохраняет совпавшие алерты с отметкой и не отправляет
+// уведомления о них. После изменения gateway сообщает alert-processor через Redis, чтобы тот перечитал их сразу. HTTP обработчики поверх PostgresDB (как ключи и группы устройств).
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/alerting"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+const (
+	defaultSilenceLimit = 100  // тишин в ответе по умолчанию
+	maxSilenceLimit     = 1000 // максимум тишин в ответе
+	maxMatchers         = 20   // матчеров в тишине или окне обслуживания
+	maxCommentLength    = 1000
+)
+
+// createSilenceRequest — тело POST /api/v1/silences. Конец задаётся ends_at или duration
+type createSilenceRequest struct {
+	Matchers  []string   `json:"matchers"`
+	StartsAt  *time.Time `json:"starts_at"` // по умолчанию сейчас
+	EndsAt    *time.Time `json:"ends_at"`
+	Duration  string     `json:"duration"` // например 2h
+	Comment   string     `json:"comment"`
+	CreatedBy string     `json:"created_by"` // без аутентификации; иначе — имя principal
+}
+
+// createMaintenanceWindowRequest — тело POST /api/v1/maintenance-windows
+type createMaintenanceWindowRequest struct {
+	Name      string   `json:"name"`
+	Group     string   `json:"group"`
+	Days      []string `json:"days"`       // mon..sun, пусто — каждый день
+	StartTime string   `json:"start_time"` // ЧЧ:ММ
+	Duration  string   `json:"duration"`   // до 24h
+	Timezone  string   `json:"timezone"`   // по умолчанию UTC
+	Matchers  []string `json:"matchers"`
+	Comment   string   `json:"comment"`
+	CreatedBy string   `json:"created_by"`
+}
+
+// maintenanceWindowResponse — окно обслуживания с текущим состоянием
+type maintenanceWindowResponse struct {
+	database.MaintenanceWindow
+	Active    bool      `json:"active"`
+	NextStart time.Time `json:"next_start"` // начало текущего или ближайшего окна
+}
+
+// parseMatchers проверяет матчеры и возвращает их в каноническом виде
+func parseMatchers(ss []string) ([]alerting.Matcher, []string, error) {
+	if len(ss) > maxMatchers {
+		return nil, nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "matchers", "too many matchers: max %d", maxMatchers)
+	}
+	matchers, err := alerting.ParseMatchers(ss)
+	if err != nil {
+		return nil, nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "matchers", "%v", err)
+	}
+	canonical := make([]string, len(matchers))
+	for i, m := range matchers {
+		canonical[i] = m.String()
+	}
+	return matchers, canonical, nil
+}
+
+// notifyPolicyChanged просит alert-processor перечитать тишины и окна сразу. Если Redis недоступен,
+// изменение применится при следующем периодическом перечитывании (POLICY_RELOAD, по умолчанию 30 секунд)
+func notifyPolicyChanged(ctx context.Context, redisCache *database.RedisCache) {
+	if err := redisCache.NotifyPolicyChanged(ctx); err != nil {
+		log.Printf("notify policy changed: %v", err)
+	}
+}
+
+// authorizeSilence — тишину для конкретных устройств (serial_number=...) может создать и снять оператор
+// с доступом к ним, остальные (по типу, группе, шаблону) — только администратор
+func authorizeSilence(ctx context.Context, authz *auth.Authorizer, matchers []alerting.Matcher) error {
+	serials := silenceDevices(matchers)
+	if len(serials) == 0 {
+		return authz.AuthorizeRole(ctx, auth.RoleAdmin, actionSilences)
+	}
+	return authz.AuthorizeDevices(ctx, auth.RoleOperator, actionSilences, serials...)
+}
+
+// silenceDevices — устройства из матчеров serial_number=...
+func silenceDevices(matchers []alerting.Matcher) []string {
+	var serials []string
+	for _, m := range matchers {
+		if m.Label == alerting.LabelSerialNumber && m.Op == alerting.OpEqual && m.Value != "" {
+			serials = append(serials, m.Value)
+		}
+	}
+	return serials
+}
+
+// createdBy — автор записи: principal при включённой аутентификации, иначе значение из запроса
+func createdBy(ctx context.Context, fromRequest string) string {
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		return p.Name
+	}
+	return strings.TrimSpace(fromRequest)
+}
+
+// createSilenceHandler - создаёт тишину (POST /api/v1/silences)
+func createSilenceHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createSilenceRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "body", "invalid request body"), "")
+			return
+		}
+		if len(req.Matchers) == 0 {
+			writeError(c, missingParameter("matchers"), "")
+			return
+		}
+		matchers, canonical, err := parseMatchers(req.Matchers)
+		if err != nil {
+			writeError(c, err, "")
+			return
+		}
+		positive := false
+		for _, m := range matchers {
+			positive = positive || m.Positive()
+		}
+		if !positive {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "matchers",
+				"at least one matcher must select alerts (= or =~ with a non-empty value)"), "")
+			return
+		}
+		if req.Comment = strings.TrimSpace(req.Comment); req.Comment == "" {
+			writeError(c, missingParameter("comment"), "")
+			return
+		}
+		if len(req.Comment) > maxCommentLength {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "comment", "comment is too long: max %d", maxCommentLength), "")
+			return
+		}
+
+		now := time.Now()
+		s := database.Silence{Matchers: canonical, StartsAt: now, Comment: req.Comment}
+		if req.StartsAt != nil {
+			s.StartsAt = *req.StartsAt
+		}
+		switch {
+		case req.EndsAt != nil && req.Duration != "":
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "duration", "ends_at and duration are mutually exclusive"), "")
+			return
+		case req.EndsAt != nil:
+			s.EndsAt = *req.EndsAt
+		case req.Duration != "":
+			d, err := time.ParseDuration(req.Duration)
+			if err != nil || d <= 0 {
+				writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "duration", "invalid duration %q", req.Duration), "")
+				return
+			}
+			s.EndsAt = s.StartsAt.Add(d)
+		default:
+			writeError(c, missingParameter("ends_at"), "")
+			return
+		}
+		if !s.EndsAt.After(s.StartsAt) || !s.EndsAt.After(now) {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_TIME_RANGE, "ends_at", "ends_at must be after starts_at and in the future"), "")
+			return
+		}
+
+		if err := authorizeSilence(c.Request.Context(), authz, matchers); err != nil {
+			writeError(c, err, "")
+			return
+		}
+		s.CreatedBy = createdBy(c.Request.Context(), req.CreatedBy)
+		if err := postgresDB.CreateSilence(c.Request.Context(), &s); err != nil {
+			writeError(c, err, "failed to create silence")
+			return
+		}
+		notifyPolicyChanged(c.Request.Context(), redisCache)
+		c.JSON(http.StatusCreated, s)
+	}
+}
+
+// listSilencesHandler - тишины (GET /api/v1/silences?state=active|pending|expired&limit=).
+// Не администратору видны только тишины его устройств
+func listSilencesHandler(postgresDB *database.PostgresDB, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		state := c.Query("state")
+		if state != "" && state != database.SilenceActive && state != database.SilencePending && state != database.SilenceExpired {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "state", "state must be active, pending or expired"), "")
+			return
+		}
+		limit, ok := parseLimit(c)
+		if !ok {
+			return
+		}
+		limit, err := resultLimit(limit, defaultSilenceLimit, maxSilenceLimit, "silences")
+		if err != nil {
+			writeError(c, err, "")
+			return
+		}
+		if err := authz.AuthorizeRole(ctx, auth.RoleViewer, actionAlertRead); err != nil {
+			writeError(c, err, "")
+			return
+		}
+
+		silences, err := postgresDB.GetSilences(ctx, state, time.Now(), limit)
+		if err != nil {
+			writeError(c, err, "failed to get silences")
+			return
+		}
+		if authz != nil && !auth.PrincipalFromContext(ctx).HasRole(auth.RoleAdmin) {
+			visible := silences[:0]
+			for _, s := range silences {
+				matchers, err := alerting.ParseMatchers(s.Matchers)
+				if err != nil {
+					continue
+				}
+				serials := silenceDevices(matchers)
+				allowed, err := authz.FilterDevices(ctx, serials)
+				if err != nil {
+					writeError(c, err, "failed to get silences")
+					return
+				}
+				if len(serials) > 0 && len(allowed) == len(serials) {
+					visible = append(visible, s)
+				}
+			}
+			silences = visible
+		}
+		c.JSON(http.StatusOK, gin.H{"silences": silences})
+	}
+}
+
+// expireSilenceHandler - снимает тишину (DELETE /api/v1/silences/:id): конец переносится на текущий момент
+func expireSilenceHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache, authz *auth.Authorizer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "id", "invalid silence id"), "")
+			return
+		}
+		s, err := postgresDB.GetSilence(ctx, id)
+		if err != nil {
+			writeError(c, err, "failed to get silence")
+			return
+		}
+		if s == nil {
+			writeError(c, notFound("silence not found"), "")
+			return
+		}
+		matchers, err := alerting.ParseMatchers(s.Matchers)
+		if err != nil {
+			matchers = nil // запись в обход API: снять может только администратор
+		}
+		if err := authorizeSilence(ctx, authz, matchers); err != nil {
+			writeError(c, err, "")
+			return
+		}
+
+		expired, err := postgresDB.ExpireSilence(ctx, id, time.Now())
+		if err != nil {
+			writeError(c, err, "failed to expire silence")
+			return
+		}
+		if !expired {
+			writeError(c, notFound("silence has already expired"), "")
+			return
+		}
+		notifyPolicyChanged(ctx, redisCache)
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// createMaintenanceWindowHandler - создаёт окно обслуживания группы (POST /api/v1/maintenance-windows)
+func createMaintenanceWindowHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req createMaintenanceWindowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "body", "invalid request body"), "")
+			return
+		}
+		w := database.MaintenanceWindow{
+			Name:      strings.TrimSpace(req.Name),
+			Group:     strings.TrimSpace(req.Group),
+			Days:      req.Days,
+			StartTime: req.StartTime,
+			Timezone:  req.Timezone,
+			Comment:   strings.TrimSpace(req.Comment),
+		}
+		switch {
+		case w.Name == "":
+			writeError(c, missingParameter("name"), "")
+			return
+		case len(w.Name) > 100:
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "name", "name is too long: max 100"), "")
+			return
+		case w.Group == "":
+			writeError(c, missingParameter("group"), "")
+			return
+		case w.StartTime == "":
+			writeError(c, missingParameter("start_time"), "")
+			return
+		case req.Duration == "":
+			writeError(c, missingParameter("duration"), "")
+			return
+		case len(w.Comment) > maxCommentLength:
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "comment", "comment is too long: max %d", maxCommentLength), "")
+			return
+		}
+		if w.Days == nil {
+			w.Days = []string{}
+		}
+		for i, d := range w.Days {
+			w.Days[i] = strings.ToLower(strings.TrimSpace(d))
+		}
+		if w.Timezone == "" {
+			w.Timezone = "UTC"
+		}
+		d, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "duration", "invalid duration %q", req.Duration), "")
+			return
+		}
+		w.DurationSeconds = int(d.Seconds())
+		schedule, err := alerting.NewSchedule(w.Days, w.StartTime, d, w.Timezone)
+		if err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "schedule", "%v", err), "")
+			return
+		}
+		if _, w.Matchers, err = parseMatchers(req.Matchers); err != nil {
+			writeError(c, err, "")
+			return
+		}
+
+		w.CreatedBy = createdBy(c.Request.Context(), req.CreatedBy)
+		if err := postgresDB.CreateMaintenanceWindow(c.Request.Context(), &w); err != nil {
+			writeError(c, err, "failed to create maintenance window")
+			return
+		}
+		notifyPolicyChanged(c.Request.Context(), redisCache)
+		now := time.Now()
+		c.JSON(http.StatusCreated, maintenanceWindowResponse{MaintenanceWindow: w, Active: schedule.Active(now), NextStart: schedule.Next(now)})
+	}
+}
+
+// listMaintenanceWindowsHandler - окна обслуживания (GET /api/v1/maintenance-windows?group=)
+func listMaintenanceWindowsHandler(postgresDB *database.PostgresDB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		windows, err := postgresDB.GetMaintenanceWindows(c.Request.Context(), c.Query("group"))
+		if err != nil {
+			writeError(c, err, "failed to get maintenance windows")
+			return
+		}
+		now := time.Now()
+		result := make([]maintenanceWindowResponse, len(windows))
+		for i, w := range windows {
+			result[i] = maintenanceWindowResponse{MaintenanceWindow: w}
+			schedule, err := alerting.NewSchedule(w.Days, w.StartTime, time.Duration(w.DurationSeconds)*time.Second, w.Timezone)
+			if err == nil {
+				result[i].Active = schedule.Active(now)
+				result[i].NextStart = schedule.Next(now)
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{"maintenance_windows": result})
+	}
+}
+
+// deleteMaintenanceWindowHandler - удаляет окно обслуживания (DELETE /api/v1/maintenance-windows/:id)
+func deleteMaintenanceWindowHandler(postgresDB *database.PostgresDB, redisCache *database.RedisCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+		if err != nil {
+			writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "id", "invalid maintenance window id"), "")
+			return
+		}
+		deleted, err := postgresDB.DeleteMaintenanceWindow(c.Request.Context(), id)
+		if err != nil {
+			writeError(c, err, "failed to delete maintenance window")
+			return
+		}
+		if !deleted {
+			writeError(c, notFound("maintenance window not found"), "")
+			return
+		}
+		notifyPolicyChanged(c.Request.Context(), redisCache)
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,177 @@
+// Последнее состояние устройств: HTTP и gRPC обработчики поверх Redis (без запроса к hypertable).
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+)
+
+const (
+	// stateStaleAfter — после скольких секунд без данных снимок считается устаревшим (3 интервала по 30 сек)
+	stateStaleAfter = 90 * time.Second
+	// maxStateDevices — максимум устройств в одном запросе состояния
+	maxStateDevices = 100
+)
+
+// deviceStateResponse — HTTP представление состояния устройства
+type deviceStateResponse struct {
+	SerialNumber     string           `json:"serial_number"`
+	Timestamp        time.Time        `json:"timestamp"`
+	StalenessSeconds int64            `json:"staleness_seconds"`
+	Stale            bool             `json:"stale"`
+	Data             tr181.DeviceData `json:"data"`
+}
+
+// GetDeviceState - gRPC метод получения последнего состояния устройств
+func (s *apiServer) GetDeviceState(ctx context.Context, req *tr181pb.DeviceStateRequest) (*tr181pb.DeviceStateResponse, error) {
+	states, missing, err := s.svc.DeviceStates(ctx, req.SerialNumbers, "serial_numbers")
+	if err != nil {
+		return nil, grpcError(err, "failed to get device state")
+	}
+
+	now := time.Now()
+	resp := &tr181pb.DeviceStateResponse{Missing: missing}
+	for _, st := range states {
+		params, err := deviceParameters(&st.Data)
+		if err != nil {
+			return nil, grpcError(err, "failed to encode device state")
+		}
+		age := staleness(now, st.Timestamp)
+		resp.States = append(resp.States, &tr181pb.DeviceState{
+			SerialNumber:     st.SerialNumber,
+			Time:             st.Timestamp.Unix(),
+			StalenessSeconds: int64(age.Seconds()),
+			Stale:            age > stateStaleAfter,
+			Parameters:       params,
+		})
+	}
+	return resp, nil
+}
+
+// getDeviceStateHandler - HTTP обработчик состояния одного устройства (GET /api/v1/state/:serialNumber)
+func getDeviceStateHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		states, _, err := svc.DeviceStates(c.Request.Context(), []string{c.Param("serialNumber")}, "serial_number")
+		if err != nil {
+			writeError(c, err, "failed to get device state")
+			return
+		}
+		if len(states) == 0 {
+			writeError(c, notFound("device state not found"), "")
+			return
+		}
+		c.JSON(http.StatusOK, toDeviceStateResponse(time.Now(), states[0]))
+	}
+}
+
+// getDeviceStatesHandler - HTTP обработчик состояния нескольких устройств
+// (GET /api/v1/state?serial-number=A,B или serial-number=A&serial-number=B)
+func getDeviceStatesHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var raw []string
+		for _, v := range c.QueryArray("serial-number") {
+			raw = append(raw, strings.Split(v, ",")...)
+		}
+		states, missing, err := svc.DeviceStates(c.Request.Context(), raw, "serial_number")
+		if err != nil {
+			writeError(c, err, "failed to get device state")
+			return
+		}
+
+		now := time.Now()
+		result := make([]deviceStateResponse, len(states))
+		for i, st := range states {
+			result[i] = toDeviceStateResponse(now, st)
+		}
+		c.JSON(http.StatusOK, gin.H{"states": result, "missing": missing})
+	}
+}
+
+// normalizeSerials убирает пустые значения и дубликаты, проверяет лимит. field — имя параметра для ошибок
+func normalizeSerials(raw []string, field string) ([]string, error) {
+	seen := make(map[string]bool, len(raw))
+	serials := make([]string, 0, len(raw))
+	for _, sn := range raw {
+		sn = strings.TrimSpace(sn)
+		if sn == "" || seen[sn] {
+			continue
+		}
+		seen[sn] = true
+		serials = append(serials, sn)
+	}
+	if len(serials) == 0 {
+		return nil, missingParameter(field)
+	}
+	if len(serials) > maxStateDevices {
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, field, "too many serial numbers: max %d", maxStateDevices)
+	}
+	return serials, nil
+}
+
+// missingSerials — запрошенные устройства, для которых нет состояния
+func missingSerials(serials []string, states []database.DeviceState) []string {
+	found := make(map[string]bool, len(states))
+	for _, st := range states {
+		found[st.SerialNumber] = true
+	}
+	missing := []string{}
+	for _, sn := range serials {
+		if !found[sn] {
+			missing = append(missing, sn)
+		}
+	}
+	return missing
+}
+
+// staleness — возраст снимка (не отрицательный при расхождении часов)
+func staleness(now, ts time.Time) time.Duration {
+	if age := now.Sub(ts); age > 0 {
+		return age
+	}
+	return 0
+}
+
+// toDeviceStateResponse конвертирует состояние из Redis в HTTP ответ
+func toDeviceStateResponse(now time.Time, st database.DeviceState) deviceStateResponse {
+	age := staleness(now, st.Timestamp)
+	return deviceStateResponse{
+		SerialNumber:     st.SerialNumber,
+		Timestamp:        st.Timestamp,
+		StalenessSeconds: int64(age.Seconds()),
+		Stale:            age > stateStaleAfter,
+		Data:             st.Data,
+	}
+}
+
+// deviceParameters раскладывает DeviceData в map "параметр TR181 → значение" для gRPC
+func deviceParameters(d *tr181.DeviceData) (map[string]int64, error) {
+	raw, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var fields map[string]json.Number
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]int64, len(fields))
+	for name, value := range fields {
+		n, err := value.Int64()
+		if err != nil {
+			return nil, err
+		}
+		params[name] = n
+	}
+	return params, nil
+}
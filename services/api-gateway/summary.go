@@ -0,0 +1,150 @@
+// Сводная статистика метрики (min, max, mean, median, p95, stddev, count): считается в PostgreSQL,
+// за весь период и, по запросу, по интервалам; HTTP и gRPC обработчики поверх queryService.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+	"golang-test-dev/services/api-gateway/auth"
+)
+
+// summaryQuery — параметры запроса сводной статистики (нулевое время — значение по умолчанию)
+type summaryQuery struct {
+	SerialNumber string
+	MetricType   string
+	From         time.Time
+	To           time.Time
+	Bucket       time.Duration // > 0 — дополнительно статистика по интервалам такой длины
+}
+
+// metricSummaryResult — статистика за период и по интервалам
+type metricSummaryResult struct {
+	Summary       database.MetricSummary   `json:"summary"`
+	Buckets       []database.MetricSummary `json:"buckets,omitempty"`
+	BucketSeconds int64                    `json:"bucket_seconds,omitempty"`
+}
+
+// Summary проверяет параметры и права и возвращает сводную статистику (кэш stale-while-revalidate)
+func (s *queryService) Summary(ctx context.Context, q summaryQuery) (*metricSummaryResult, error) {
+	if q.SerialNumber == "" {
+		return nil, missingParameter("serial_number")
+	}
+	if !isValidMetricType(tr181.MetricType(q.MetricType)) {
+		return nil, invalidMetricType(q.MetricType)
+	}
+	var err error
+	if q.From, q.To, err = normalizeRange(q.From, q.To, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.authz.AuthorizeDevices(ctx, auth.RoleViewer, actionMetricRead, q.SerialNumber); err != nil {
+		return nil, err
+	}
+
+	// Интервалы — целые секунды; их число ограничено так же, как число точек в ответе
+	bucketSec := int64(q.Bucket / time.Second)
+	switch {
+	case q.Bucket < 0 || (q.Bucket > 0 && bucketSec == 0):
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "bucket", "bucket must be at least 1s")
+	case bucketSec > 0 && int64(q.To.Sub(q.From)/time.Second)/bucketSec >= int64(s.limits.MaxPoints):
+		return nil, badRequest(tr181pb.ErrorCode_ERROR_CODE_LIMIT_EXCEEDED, "bucket", "too many buckets: max %d", s.limits.MaxPoints)
+	}
+
+	cacheKey := fmt.Sprintf("summary:%s:%s:%d:%d:%d", q.MetricType, q.SerialNumber, q.From.Unix(), q.To.Unix(), bucketSec)
+	return cachedSWR(ctx, s.fill, cacheKey, responseFresh, responseStale, func(ctx context.Context) (*metricSummaryResult, error) {
+		summary, err := s.postgresDB.GetMetricSummary(ctx, q.SerialNumber, q.MetricType, q.From, q.To)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get metric summary: %w", err)
+		}
+		result := &metricSummaryResult{Summary: *summary}
+		if bucketSec > 0 {
+			bucket := time.Duration(bucketSec) * time.Second
+			if result.Buckets, err = s.postgresDB.GetMetricSummaryBuckets(ctx, q.SerialNumber, q.MetricType, q.From, q.To, bucket); err != nil {
+				return nil, fmt.Errorf("failed to get metric summary: %w", err)
+			}
+			result.BucketSeconds = bucketSec
+		}
+		return result, nil
+	})
+}
+
+// GetMetricSummary - gRPC метод получения сводной статистики метрики
+func (s *apiServer) GetMetricSummary(ctx context.Context, req *tr181pb.MetricSummaryRequest) (*tr181pb.MetricSummaryResponse, error) {
+	from, to, err := grpcTimeRange(req.From, req.To, req.FromExpr, req.ToExpr, req.Range, req.Tz)
+	if err != nil {
+		return nil, grpcError(err, "")
+	}
+
+	result, err := s.svc.Summary(ctx, summaryQuery{
+		SerialNumber: req.SerialNumber,
+		MetricType:   req.MetricType,
+		From:         from,
+		To:           to,
+		Bucket:       time.Duration(req.BucketSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, grpcError(err, "failed to get metric summary")
+	}
+
+	resp := &tr181pb.MetricSummaryResponse{
+		Summary:       toPBSummary(result.Summary),
+		BucketSeconds: result.BucketSeconds,
+	}
+	for _, b := range result.Buckets {
+		resp.Buckets = append(resp.Buckets, toPBSummary(b))
+	}
+	return resp, nil
+}
+
+// getMetricSummaryHandler - HTTP обработчик сводной статистики
+// (GET /api/v1/metric/:metricType/summary?serial-number=&from=&to=&bucket=1h)
+func getMetricSummaryHandler(svc *queryService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		from, to, ok := parseTimeRange(c)
+		if !ok {
+			return
+		}
+
+		var bucket time.Duration
+		if v := c.Query("bucket"); v != "" {
+			var err error
+			if bucket, err = time.ParseDuration(v); err != nil {
+				writeError(c, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "bucket", "invalid bucket parameter"), "")
+				return
+			}
+		}
+
+		result, err := svc.Summary(c.Request.Context(), summaryQuery{
+			SerialNumber: c.Query("serial-number"),
+			MetricType:   c.Param("metricType"),
+			From:         from,
+			To:           to,
+			Bucket:       bucket,
+		})
+		if err != nil {
+			writeError(c, err, "failed to get metric summary")
+			return
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// toPBSummary конвертирует статистику в gRPC формат
+func toPBSummary(s database.MetricSummary) *tr181pb.MetricSummary {
+	return &tr181pb.MetricSummary{
+		Time:   s.Time,
+		Count:  s.Count,
+		Min:    int32(s.Min),
+		Max:    int32(s.Max),
+		Mean:   s.Mean,
+		Median: s.Median,
+		P95:    s.P95,
+		Stddev: s.StdDev,
+	}
+}
@@ -0,0 +1,246 @@
+// Разбор периода запроса — общий для HTTP (/api/v1) и gRPC/REST v2 (поля from_expr, to_expr, range, tz).
+//
+// Форматы from и to:
+//   - RFC3339: 2024-01-01T12:00:00Z, 2024-01-01T15:00:00+03:00
+//   - дата или время без смещения в часовом поясе tz: 2024-01-01, 2024-01-01T12:00:00
+//   - Unix-время в секундах (1704110400) или миллисекундах (1704110400000)
+//   - относительное время: now, now-6h, now+30m, now-7d/d (округление до начала дня в tz)
+//
+// Единицы: s, m, h, d, w, M (месяц), y. Округление /unit в to — до конца интервала (как в Grafana):
+// from=now/d&to=now/d — текущие сутки целиком.
+//
+// Именованный период range (вместо from и to): today, yesterday, this-week, last-week,
+// this-month, last-month. Неделя начинается с понедельника; текущие периоды заканчиваются сейчас.
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // база часовых поясов для tz (в контейнере её может не быть)
+
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+)
+
+// timeRangeSpec — период запроса в виде строк из параметров запроса
+type timeRangeSpec struct {
+	From  string // начало периода (пусто — по умолчанию)
+	To    string // конец периода (пусто — сейчас)
+	Range string // именованный период, исключает From и To
+	TZ    string // часовой пояс IANA (Europe/Moscow), по умолчанию UTC
+}
+
+// resolve разбирает период относительно now. Незаданные границы остаются нулевыми:
+// значения по умолчанию и проверку from < to выполняет сервис запросов (normalizeRange)
+func (s timeRangeSpec) resolve(now time.Time) (time.Time, time.Time, error) {
+	loc := time.UTC
+	if s.TZ != "" {
+		l, err := time.LoadLocation(s.TZ)
+		if err != nil {
+			return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "tz", "unknown time zone %q", s.TZ)
+		}
+		loc = l
+	}
+	now = now.In(loc)
+
+	if s.Range != "" {
+		if s.From != "" || s.To != "" {
+			return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "range", "range cannot be combined with from or to")
+		}
+		from, to, ok := namedRange(s.Range, now)
+		if !ok {
+			return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "range",
+				"unknown range %q: use today, yesterday, this-week, last-week, this-month or last-month", s.Range)
+		}
+		return from, to, nil
+	}
+
+	from, err := parseTimeExpr(s.From, now, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "from", "invalid from parameter: %v", err)
+	}
+	to, err := parseTimeExpr(s.To, now, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, "to", "invalid to parameter: %v", err)
+	}
+	return from, to, nil
+}
+
+// normalizeRange подставляет значения по умолчанию независимо для каждой границы
+// (to — сейчас, from — за defaultQueryWindow до to) и проверяет, что from раньше to
+func normalizeRange(from, to, now time.Time) (time.Time, time.Time, error) {
+	if to.IsZero() {
+		to = now
+	}
+	if from.IsZero() {
+		from = to.Add(-defaultQueryWindow)
+	}
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_TIME_RANGE, "from", "from must be before to")
+	}
+	return from, to, nil
+}
+
+// unixMillisThreshold — числа от этого значения считаются миллисекундами (1e11 секунд — 5138 год)
+const unixMillisThreshold = 100_000_000_000
+
+// parseTimeExpr разбирает одну границу периода. now задаёт часовой пояс для дат без смещения и округления;
+// upper — граница to: округление /unit идёт до конца интервала. Пустая строка — нулевое время
+func parseTimeExpr(expr string, now time.Time, upper bool) (time.Time, error) {
+	expr = strings.TrimSpace(expr)
+	switch {
+	case expr == "":
+		return time.Time{}, nil
+	case strings.HasPrefix(expr, "now"):
+		return parseRelative(expr[len("now"):], now, upper)
+	case isDigits(expr):
+		n, err := strconv.ParseInt(expr, 10, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unix time out of range")
+		}
+		if n >= unixMillisThreshold {
+			return time.UnixMilli(n), nil
+		}
+		return time.Unix(n, 0), nil
+	}
+	if t, err := time.Parse(time.RFC3339, expr); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, expr, now.Location()); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("use RFC3339, Unix time or now-6h")
+}
+
+// parseRelative разбирает хвост выражения после now: смещения ±N<unit> и необязательное округление /<unit> в конце
+func parseRelative(rest string, now time.Time, upper bool) (time.Time, error) {
+	t := now
+	for rest != "" {
+		op := rest[0]
+		rest = rest[1:]
+		switch op {
+		case '+', '-':
+			i := 0
+			for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+				i++
+			}
+			if i == 0 || i == len(rest) {
+				return time.Time{}, fmt.Errorf("expected offset like now-6h")
+			}
+			n, err := strconv.Atoi(rest[:i])
+			if err != nil {
+				return time.Time{}, fmt.Errorf("offset out of range")
+			}
+			if op == '-' {
+				n = -n
+			}
+			shifted, ok := addUnits(t, n, rest[i])
+			if !ok {
+				return time.Time{}, fmt.Errorf("unknown unit %q: use s, m, h, d, w, M or y", rest[i])
+			}
+			t = shifted
+			rest = rest[i+1:]
+		case '/':
+			if len(rest) != 1 {
+				return time.Time{}, fmt.Errorf("rounding must end the expression, e.g. now-7d/d")
+			}
+			start, ok := truncateUnit(t, rest[0])
+			if !ok {
+				return time.Time{}, fmt.Errorf("unknown unit %q: use s, m, h, d, w, M or y", rest[0])
+			}
+			if upper {
+				start, _ = addUnits(start, 1, rest[0])
+			}
+			return start, nil
+		default:
+			return time.Time{}, fmt.Errorf("expected now, now-6h or now-7d/d")
+		}
+	}
+	return t, nil
+}
+
+// addUnits сдвигает t на n единиц. Дни и крупнее — календарные (с учётом перехода на летнее время в tz)
+func addUnits(t time.Time, n int, unit byte) (time.Time, bool) {
+	switch unit {
+	case 's':
+		return t.Add(time.Duration(n) * time.Second), true
+	case 'm':
+		return t.Add(time.Duration(n) * time.Minute), true
+	case 'h':
+		return t.Add(time.Duration(n) * time.Hour), true
+	case 'd':
+		return t.AddDate(0, 0, n), true
+	case 'w':
+		return t.AddDate(0, 0, 7*n), true
+	case 'M':
+		return t.AddDate(0, n, 0), true
+	case 'y':
+		return t.AddDate(n, 0, 0), true
+	}
+	return time.Time{}, false
+}
+
+// truncateUnit округляет t вниз до начала единицы в часовом поясе t (неделя — с понедельника)
+func truncateUnit(t time.Time, unit byte) (time.Time, bool) {
+	y, mo, d := t.Date()
+	loc := t.Location()
+	switch unit {
+	case 's':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, loc), true
+	case 'm':
+		return time.Date(y, mo, d, t.Hour(), t.Minute(), 0, 0, loc), true
+	case 'h':
+		return time.Date(y, mo, d, t.Hour(), 0, 0, 0, loc), true
+	case 'd':
+		return time.Date(y, mo, d, 0, 0, 0, 0, loc), true
+	case 'w':
+		offset := (int(t.Weekday()) + 6) % 7 // дней с понедельника
+		return time.Date(y, mo, d-offset, 0, 0, 0, 0, loc), true
+	case 'M':
+		return time.Date(y, mo, 1, 0, 0, 0, 0, loc), true
+	case 'y':
+		return time.Date(y, 1, 1, 0, 0, 0, 0, loc), true
+	}
+	return time.Time{}, false
+}
+
+// namedRange — границы именованного периода в часовом поясе now; текущие периоды заканчиваются в now
+func namedRange(name string, now time.Time) (time.Time, time.Time, bool) {
+	var unit byte
+	shift := 0
+	switch name {
+	case "today":
+		unit = 'd'
+	case "yesterday":
+		unit, shift = 'd', -1
+	case "this-week":
+		unit = 'w'
+	case "last-week":
+		unit, shift = 'w', -1
+	case "this-month":
+		unit = 'M'
+	case "last-month":
+		unit, shift = 'M', -1
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+	start, _ := truncateUnit(now, unit)
+	if shift == 0 {
+		return start, now, true
+	}
+	from, _ := addUnits(start, shift, unit)
+	return from, start, true
+}
+
+// isDigits — строка из одних цифр
+func isDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
@@ -0,0 +1,63 @@
+// Устаревшие /api/v1/metric и /api/v1/alert — обёртки над методами gRPC (те же, что у /api/v2): параметры
+// запроса переводятся в поля proto тем же разбором, что и в gRPC-Gateway, проверки и значения по умолчанию —
+// только в apiServer. Ответы сохраняют прежний формат /api/v1.
+package main
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/utilities"
+	"google.golang.org/protobuf/proto"
+
+	tr181pb "golang-test-dev/api/tr181pb/api/proto"
+)
+
+// v1FieldNames — параметры /api/v1, имя которых не совпадает с полем proto после замены "-" на "_"
+// (from и to в /api/v1 — строки, как from_expr и to_expr; Unix-время в from/to proto — число)
+var v1FieldNames = map[string]string{
+	"from": "from_expr",
+	"to":   "to_expr",
+}
+
+// v1ListFields — поля со списком через запятую (severity=warning,error)
+var v1ListFields = map[string]bool{
+	"severity": true,
+}
+
+// populateV1Request заполняет msg из параметров запроса /api/v1 (serial-number → serial_number)
+// и параметров пути path (поле proto → значение; важнее одноимённых параметров запроса).
+// Отвечает устаревшим маршрутам заголовком Deprecation
+func populateV1Request(c *gin.Context, msg proto.Message, path map[string]string) error {
+	c.Header("Deprecation", "true")
+	for key, values := range c.Request.URL.Query() {
+		field := strings.ReplaceAll(key, "-", "_")
+		if name, ok := v1FieldNames[field]; ok {
+			field = name
+		}
+		if v1ListFields[field] {
+			var items []string
+			for _, v := range values {
+				items = append(items, strings.Split(v, ",")...)
+			}
+			values = items
+		}
+		if err := populateField(msg, field, values); err != nil {
+			return badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, field, "invalid %s parameter", key)
+		}
+	}
+	for field, value := range path {
+		if err := populateField(msg, field, []string{value}); err != nil {
+			return badRequest(tr181pb.ErrorCode_ERROR_CODE_INVALID_PARAMETER, field, "invalid %s", httpField(field))
+		}
+	}
+	return nil
+}
+
+// populateField разбирает одно поле, как gRPC-Gateway разбирает параметры запроса /api/v2
+// (неизвестные поля пропускаются)
+func populateField(msg proto.Message, field string, values []string) error {
+	return runtime.PopulateQueryParameters(msg, url.Values{field: values}, utilities.NewDoubleArray(nil))
+}
@@ -1,4 +1,5 @@
-// Сервис data-ingestion: читает TR181 данные из Pulsar и сохраняет метрики в PostgreSQL.
+// Сервис data-ingestion: читает TR181 данные из Pulsar, сохраняет метрики в PostgreSQL
+// и последнее состояние устройств в Redis.
 package main
 
 import (
@@ -31,6 +32,11 @@ func main() {
 		log.Printf("schema: %v", err)
 	}
 
+	// Redis для последнего состояния устройств и сброса кэша чанков. Пока он недоступен, сохраняются
+	// только метрики в БД; кэш переподключается сам
+	cache := database.OpenRedisCache(cfg.RedisAddr, 0)
+	defer cache.Close()
+
 	// Подключаемся к Pulsar
 	client, err := pulsar.NewClient(cfg.PulsarURL)
 	if err != nil {
@@ -52,7 +58,7 @@ func main() {
 	}
 	defer consumer.Close()
 
-	storage := NewMetricStorage(db)
+	storage := NewMetricStorage(db, cache)
 	handler := NewMessageHandler(storage, consumer, logColl)
 
 	// Горутина: бесконечный цикл приёма и обработки сообщений
@@ -68,6 +74,17 @@ func main() {
 		}
 	}()
 
+	// Горутина: повторный сброс чанков, которые не удалось сбросить, пока Redis был недоступен
+	go func() {
+		ticker := time.NewTicker(invalidationReplayInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if err := storage.ReplayInvalidations(context.Background()); err != nil {
+				log.Printf("replay chunk invalidations: %v", err)
+			}
+		}
+	}()
+
 	log.Println("data-ingestion started")
 
 	// Ожидаем сигнал завершения
@@ -3,7 +3,9 @@ package main
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	"golang-test-dev/pkg/database"
 	"golang-test-dev/pkg/tr181"
@@ -25,17 +27,21 @@ var metricTypes = []tr181.MetricType{
 }
 
 // MetricStorage обёртка над PostgresDB для массового сохранения метрик.
+// cache хранит последнее состояние каждого устройства и кэш чанков метрик.
 type MetricStorage struct {
-	db *database.PostgresDB
+	db    *database.PostgresDB
+	cache *database.RedisCache
 }
 
 // NewMetricStorage создаёт storage для метрик.
-func NewMetricStorage(db *database.PostgresDB) *MetricStorage {
-	return &MetricStorage{db: db}
+func NewMetricStorage(db *database.PostgresDB, cache *database.RedisCache) *MetricStorage {
+	return &MetricStorage{db: db, cache: cache}
 }
 
 // Save сохраняет все метрики устройства в БД (по одной записи на каждый тип).
+// Запоздавшие данные (в уже закрытом часовом чанке) сбрасывают кэш чанка в api-gateway.
 func (s *MetricStorage) Save(ctx context.Context, device *tr181.TR181Device) error {
+	late := database.IsLateMetric(device.Timestamp, time.Now())
 	for _, mt := range metricTypes {
 		value, ok := device.Data.GetMetricValue(mt)
 		if !ok {
@@ -44,6 +50,58 @@ func (s *MetricStorage) Save(ctx context.Context, device *tr181.TR181Device) err
 		// Вставляем в таблицу metrics
 		if err := s.db.SaveMetric(ctx, device.SerialNumber, string(mt), value, device.Timestamp); err != nil {
 			log.Printf("save metric %s: %v", mt, err)
+			continue
+		}
+		// Чанк сбрасывается после вставки: gateway перечитает его уже с новой точкой
+		if late {
+			s.invalidateChunk(ctx, string(mt), device.SerialNumber, device.Timestamp)
+		}
+	}
+	// Обновляем последнее состояние устройства (для /api/v1/state); пока Redis недоступен — пропускаем
+	if s.cache.Healthy() {
+		if err := s.cache.SaveDeviceState(ctx, device); err != nil {
+			log.Printf("save device state %s: %v", device.SerialNumber, err)
+		}
+	}
+	return nil
+}
+
+const (
+	invalidationReplayInterval = 5 * time.Second // период повторного сброса чанков
+	invalidationBatch          = 500             // сколько несброшенных чанков читается из PostgreSQL за раз
+)
+
+// invalidateChunk сбрасывает кэш чанка с точкой ts. Если Redis недоступен, чанк записывается
+// в PostgreSQL и сбрасывается позже (ReplayInvalidations), иначе gateway отдавал бы его устаревшим до истечения TTL
+func (s *MetricStorage) invalidateChunk(ctx context.Context, metricType, serialNumber string, ts time.Time) {
+	err := s.cache.InvalidateMetricChunk(ctx, metricType, serialNumber, ts)
+	if err == nil {
+		return
+	}
+	log.Printf("invalidate metric chunk %s/%s: %v (queued)", serialNumber, metricType, err)
+	if err := s.db.SaveChunkInvalidation(ctx, metricType, serialNumber, ts); err != nil {
+		log.Printf("queue metric chunk invalidation %s/%s: %v", serialNumber, metricType, err)
+	}
+}
+
+// ReplayInvalidations сбрасывает чанки, которые не удалось сбросить раньше. Пока Redis недоступен — ничего не делает;
+// на первой ошибке Redis останавливается (остальные чанки остаются в очереди)
+func (s *MetricStorage) ReplayInvalidations(ctx context.Context) error {
+	for s.cache.Healthy() {
+		refs, err := s.db.GetChunkInvalidations(ctx, invalidationBatch)
+		if err != nil {
+			return fmt.Errorf("get chunk invalidations: %w", err)
+		}
+		for _, ref := range refs {
+			if err := s.cache.InvalidateMetricChunk(ctx, ref.MetricType, ref.SerialNumber, ref.Start); err != nil {
+				return fmt.Errorf("invalidate metric chunk %s/%s: %w", ref.SerialNumber, ref.MetricType, err)
+			}
+			if err := s.db.DeleteChunkInvalidation(ctx, ref); err != nil {
+				return fmt.Errorf("delete chunk invalidation: %w", err)
+			}
+		}
+		if len(refs) < invalidationBatch {
+			return nil
 		}
 	}
 	return nil
@@ -0,0 +1,168 @@
+// Каналы уведомлений: описание в YAML (NOTIFY_CHANNELS_FILE) и общий интерфейс отправки.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"golang-test-dev/pkg/tr181"
+	"gopkg.in/yaml.v3"
+)
+
+// Типы каналов
+const (
+	channelWebhook  = "webhook"  // JSON события с подписью HMAC-SHA256
+	channelSlack    = "slack"    // incoming webhook Slack (и совместимые: Mattermost, Rocket.Chat)
+	channelTelegram = "telegram" // Bot API sendMessage
+	channelEmail    = "email"    // SMTP
+)
+
+// Значения по умолчанию для повторов и таймаута
+const (
+	defaultRetries = 5
+	defaultBackoff = 2 * time.Second
+	defaultTimeout = 10 * time.Second
+	maxBackoff     = 5 * time.Minute
+)
+
+// channelNamePattern — допустимое имя канала (пишется в notification_deliveries)
+var channelNamePattern = regexp.MustCompile(`^[a-z0-9]+([-_][a-z0-9]+)*$`)
+
+// ChannelConfig — канал уведомлений
+type ChannelConfig struct {
+	Name     string        `yaml:"name"`     // уникальное имя
+	Type     string        `yaml:"type"`     // webhook, slack, telegram или email
+	URL      string        `yaml:"url"`      // адрес webhook; для telegram — адрес Bot API (по умолчанию api.telegram.org)
+	Secret   string        `yaml:"secret"`   // ключ подписи webhook (пусто — без подписи)
+	Token    string        `yaml:"token"`    // токен бота telegram
+	ChatID   string        `yaml:"chat_id"`  // чат telegram
+	SMTP     SMTPConfig    `yaml:"smtp"`     // сервер email
+	To       []string      `yaml:"to"`       // получатели email
+	Template string        `yaml:"template"` // шаблон текста (text/template над tr181.AlertEvent)
+	Subject  string        `yaml:"subject"`  // шаблон темы email
+	Retries  *int          `yaml:"retries"`  // повторов после неудачной попытки (по умолчанию 5)
+	Backoff  time.Duration `yaml:"backoff"`  // пауза перед первым повтором, дальше удваивается (до 5m)
+	Timeout  time.Duration `yaml:"timeout"`  // таймаут одной попытки
+}
+
+// SMTPConfig — SMTP сервер канала email
+type SMTPConfig struct {
+	Addr     string `yaml:"addr"` // host:port
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// channelFile — формат YAML файла каналов
+type channelFile struct {
+	Channels []ChannelConfig `yaml:"channels"`
+}
+
+// Sender отправляет уведомление о событии в канал
+type Sender interface {
+	Send(ctx context.Context, e tr181.AlertEvent) error
+}
+
+// permanentError — канал отверг уведомление (неверный адрес, токен, получатель): повтор не поможет
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// isPermanent — ошибку не имеет смысла повторять
+func isPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// loadChannels читает каналы из YAML файла. Переменные окружения (${SLACK_URL}) подставляются —
+// секреты не нужно хранить в файле
+func loadChannels(file string) ([]ChannelConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var f channelFile
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	if len(f.Channels) == 0 {
+		return nil, fmt.Errorf("%s: no channels", file)
+	}
+	names := make(map[string]bool, len(f.Channels))
+	for i := range f.Channels {
+		c := &f.Channels[i]
+		if err := c.validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if names[c.Name] {
+			return nil, fmt.Errorf("%s: duplicate channel %q", file, c.Name)
+		}
+		names[c.Name] = true
+	}
+	return f.Channels, nil
+}
+
+// validate проверяет канал и подставляет значения по умолчанию
+func (c *ChannelConfig) validate() error {
+	if !channelNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("invalid channel name %q", c.Name)
+	}
+	switch c.Type {
+	case channelWebhook, channelSlack:
+		if c.URL == "" {
+			return fmt.Errorf("channel %s: url is required", c.Name)
+		}
+	case channelTelegram:
+		if c.Token == "" || c.ChatID == "" {
+			return fmt.Errorf("channel %s: token and chat_id are required", c.Name)
+		}
+	case channelEmail:
+		if c.SMTP.Addr == "" || c.SMTP.From == "" || len(c.To) == 0 {
+			return fmt.Errorf("channel %s: smtp.addr, smtp.from and to are required", c.Name)
+		}
+	default:
+		return fmt.Errorf("channel %s: unknown type %q", c.Name, c.Type)
+	}
+	if c.Retries == nil {
+		n := defaultRetries
+		c.Retries = &n
+	}
+	if *c.Retries < 0 {
+		return fmt.Errorf("channel %s: retries must not be negative", c.Name)
+	}
+	if c.Backoff < 0 || c.Timeout < 0 {
+		return fmt.Errorf("channel %s: backoff and timeout must not be negative", c.Name)
+	}
+	if c.Backoff == 0 {
+		c.Backoff = defaultBackoff
+	}
+	if c.Timeout == 0 {
+		c.Timeout = defaultTimeout
+	}
+	return nil
+}
+
+// newSender создаёт отправку для канала (канал проверен, см. validate)
+func newSender(c ChannelConfig) (Sender, error) {
+	msg, err := newMessageTemplate(c.Template, c.Subject)
+	if err != nil {
+		return nil, fmt.Errorf("channel %s: %w", c.Name, err)
+	}
+	switch c.Type {
+	case channelWebhook:
+		return newWebhookSender(c, msg), nil
+	case channelSlack:
+		return newSlackSender(c, msg), nil
+	case channelTelegram:
+		return newTelegramSender(c, msg), nil
+	default:
+		return newEmailSender(c, msg), nil
+	}
+}
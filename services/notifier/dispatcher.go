@@ -0,0 +1,165 @@
+// Доставка событий алертов во все каналы: повторы с экспоненциальной паузой, статус — в notification_deliveries.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/logcollector"
+	"golang-test-dev/pkg/tr181"
+)
+
+// DeliveryStore — статус доставки (реализуется database.PostgresDB)
+type DeliveryStore interface {
+	BeginDelivery(ctx context.Context, d database.NotificationDelivery) (int64, bool, error)
+	UpdateDelivery(ctx context.Context, id int64, status string, attempts int, lastError string) error
+}
+
+// channel — канал с настройками повторов
+type channel struct {
+	cfg    ChannelConfig
+	sender Sender
+}
+
+// Dispatcher доставляет события во все каналы параллельно
+type Dispatcher struct {
+	store    DeliveryStore
+	channels []channel
+	logColl  *logcollector.Collector
+	sleep    func(ctx context.Context, d time.Duration) bool // пауза перед повтором; false — ctx отменён
+}
+
+// NewDispatcher создаёт отправку для каждого канала
+func NewDispatcher(store DeliveryStore, configs []ChannelConfig, logColl *logcollector.Collector) (*Dispatcher, error) {
+	d := &Dispatcher{store: store, logColl: logColl, sleep: sleepCtx}
+	for _, c := range configs {
+		sender, err := newSender(c)
+		if err != nil {
+			return nil, err
+		}
+		d.channels = append(d.channels, channel{cfg: c, sender: sender})
+	}
+	return d, nil
+}
+
+// Dispatch доставляет событие в каналы e.Receivers (пусто — во все) и ждёт завершения. Ошибка — только
+// если статус доставки не удалось записать: тогда событие нужно обработать повторно (уже доставленные
+// каналы пропускаются). Исчерпанные попытки — не ошибка: статус failed остаётся в БД
+func (d *Dispatcher) Dispatch(ctx context.Context, e tr181.AlertEvent) error {
+	channels := d.route(e)
+	var wg sync.WaitGroup
+	errs := make([]error, len(channels))
+	for i, ch := range channels {
+		wg.Add(1)
+		go func(i int, ch *channel) {
+			defer wg.Done()
+			errs[i] = d.deliver(ctx, ch, e)
+		}(i, ch)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// route выбирает каналы по маршрутам alert-processor; неизвестный канал пропускается с сообщением в лог
+func (d *Dispatcher) route(e tr181.AlertEvent) []*channel {
+	channels := make([]*channel, 0, len(d.channels))
+	if len(e.Receivers) == 0 {
+		for i := range d.channels {
+			channels = append(channels, &d.channels[i])
+		}
+		return channels
+	}
+	for _, name := range e.Receivers {
+		found := false
+		for i := range d.channels {
+			if d.channels[i].cfg.Name == name {
+				channels = append(channels, &d.channels[i])
+				found = true
+				break
+			}
+		}
+		if !found {
+			log.Printf("notify %s: unknown receiver %q", e.ID, name)
+		}
+	}
+	return channels
+}
+
+// deliver отправляет событие в канал: 1 + Retries попыток, пауза Backoff удваивается (до maxBackoff).
+// Постоянная ошибка (4xx, 5xx SMTP, ошибка шаблона) попыток не продолжает
+func (d *Dispatcher) deliver(ctx context.Context, ch *channel, e tr181.AlertEvent) error {
+	id, ok, err := d.store.BeginDelivery(ctx, database.NotificationDelivery{
+		EventID:      e.ID,
+		Channel:      ch.cfg.Name,
+		SerialNumber: e.SerialNumber,
+		AlertType:    string(e.Type),
+	})
+	if err != nil {
+		return fmt.Errorf("begin delivery %s: %w", ch.cfg.Name, err)
+	}
+	if !ok {
+		return nil // уже доставлено (повторная доставка события из Pulsar)
+	}
+
+	backoff := ch.cfg.Backoff
+	attempts := 0
+	for {
+		attempts++
+		err = ch.sender.Send(ctx, e)
+		if err == nil || isPermanent(err) || attempts > *ch.cfg.Retries {
+			break
+		}
+		log.Printf("notify %s %s: attempt %d: %v", ch.cfg.Name, e.ID, attempts, err)
+		if !d.sleep(ctx, backoff) {
+			break // остановка: статус остаётся pending, событие будет доставлено повторно
+		}
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	status, lastError := database.DeliveryDelivered, ""
+	if err != nil {
+		status, lastError = database.DeliveryFailed, err.Error()
+		if ctx.Err() != nil {
+			status = database.DeliveryPending
+		}
+		d.notify(ch.cfg.Name, e, err)
+	}
+	// Статус пишем и после отмены ctx: попытки уже сделаны
+	if err := d.store.UpdateDelivery(context.WithoutCancel(ctx), id, status, attempts, lastError); err != nil {
+		return fmt.Errorf("update delivery %s: %w", ch.cfg.Name, err)
+	}
+	if status == database.DeliveryPending {
+		return ctx.Err()
+	}
+	return nil
+}
+
+// notify пишет неудачную доставку в лог и log-viewer (если подключён)
+func (d *Dispatcher) notify(channel string, e tr181.AlertEvent, err error) {
+	msg := fmt.Sprintf("notify %s %s failed: %v", channel, e.ID, err)
+	log.Print(msg)
+	if d.logColl != nil {
+		d.logColl.Send("notifier", "error", msg)
+	}
+}
+
+// sleepCtx ждёт d или отмены ctx; false — ctx отменён
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/tr181"
+)
+
+// deliveryRecord — доставка в fakeStore
+type deliveryRecord struct {
+	database.NotificationDelivery
+	Status    string
+	Attempts  int
+	LastError string
+}
+
+// fakeStore — DeliveryStore в памяти: доставленное событие канал повторно не получает
+type fakeStore struct {
+	mu         sync.Mutex
+	deliveries []deliveryRecord
+	updateErr  error
+}
+
+func (s *fakeStore) BeginDelivery(_ context.Context, d database.NotificationDelivery) (int64, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, r := range s.deliveries {
+		if r.EventID == d.EventID && r.Channel == d.Channel {
+			if r.Status == database.DeliveryDelivered {
+				return 0, false, nil
+			}
+			return int64(i + 1), true, nil
+		}
+	}
+	s.deliveries = append(s.deliveries, deliveryRecord{NotificationDelivery: d, Status: database.DeliveryPending})
+	return int64(len(s.deliveries)), true, nil
+}
+
+func (s *fakeStore) UpdateDelivery(_ context.Context, id int64, status string, attempts int, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updateErr != nil {
+		return s.updateErr
+	}
+	r := &s.deliveries[id-1]
+	r.Status, r.Attempts, r.LastError = status, attempts, lastError
+	return nil
+}
+
+// delivery возвращает доставку канала (ok — есть)
+func (s *fakeStore) delivery(channel string) (deliveryRecord, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.deliveries {
+		if r.Channel == channel {
+			return r, true
+		}
+	}
+	return deliveryRecord{}, false
+}
+
+// newTestDispatcher создаёт Dispatcher без пауз: паузы перед повторами записываются в *sleeps
+func newTestDispatcher(t *testing.T, store DeliveryStore, configs ...ChannelConfig) (*Dispatcher, *[]time.Duration) {
+	t.Helper()
+	for i := range configs {
+		if err := configs[i].validate(); err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+	}
+	d, err := NewDispatcher(store, configs, nil)
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	var mu sync.Mutex
+	sleeps := []time.Duration{}
+	d.sleep = func(ctx context.Context, dur time.Duration) bool {
+		mu.Lock()
+		sleeps = append(sleeps, dur)
+		mu.Unlock()
+		return ctx.Err() == nil
+	}
+	return d, &sleeps
+}
+
+func retries(n int) *int { return &n }
+
+func TestDispatchRetriesWithBackoff(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK)
+	store := &fakeStore{}
+	d, sleeps := newTestDispatcher(t, store,
+		ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL, Retries: retries(5), Backoff: time.Second})
+
+	e := testEvent()
+	if err := d.Dispatch(context.Background(), e); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if n := len(rec.Requests()); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+	if want := []time.Duration{time.Second, 2 * time.Second}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("backoff = %v, want %v", *sleeps, want)
+	}
+	got, _ := store.delivery("hook")
+	if got.Status != database.DeliveryDelivered || got.Attempts != 3 || got.LastError != "" {
+		t.Errorf("delivery = %+v, want delivered after 3 attempts", got)
+	}
+	if got.EventID != e.ID || got.SerialNumber != e.SerialNumber || got.AlertType != string(e.Type) {
+		t.Errorf("delivery event = %+v", got.NotificationDelivery)
+	}
+}
+
+func TestDispatchBackoffCapped(t *testing.T) {
+	_, srv := newRecorder(t, http.StatusInternalServerError)
+	store := &fakeStore{}
+	d, sleeps := newTestDispatcher(t, store,
+		ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL, Retries: retries(3), Backoff: 3 * time.Minute})
+
+	if err := d.Dispatch(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if want := []time.Duration{3 * time.Minute, maxBackoff, maxBackoff}; !reflect.DeepEqual(*sleeps, want) {
+		t.Errorf("backoff = %v, want %v", *sleeps, want)
+	}
+	got, _ := store.delivery("hook")
+	if got.Status != database.DeliveryFailed || got.Attempts != 4 || !strings.Contains(got.LastError, "http 500") {
+		t.Errorf("delivery = %+v, want failed after 4 attempts", got)
+	}
+}
+
+func TestDispatchPermanentErrorNotRetried(t *testing.T) {
+	rec, srv := newRecorder(t, http.StatusBadRequest)
+	store := &fakeStore{}
+	d, sleeps := newTestDispatcher(t, store,
+		ChannelConfig{Name: "ops", Type: channelSlack, URL: srv.URL, Retries: retries(5)})
+
+	if err := d.Dispatch(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if n := len(rec.Requests()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+	if len(*sleeps) != 0 {
+		t.Errorf("backoff = %v, want none", *sleeps)
+	}
+	got, _ := store.delivery("ops")
+	if got.Status != database.DeliveryFailed || got.Attempts != 1 || !strings.Contains(got.LastError, "http 400") {
+		t.Errorf("delivery = %+v, want failed after 1 attempt", got)
+	}
+}
+
+func TestDispatchSkipsDelivered(t *testing.T) {
+	rec, srv := newRecorder(t)
+	store := &fakeStore{}
+	d, _ := newTestDispatcher(t, store, ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL})
+
+	e := testEvent()
+	for i := 0; i < 2; i++ { // повторная доставка события из Pulsar
+		if err := d.Dispatch(context.Background(), e); err != nil {
+			t.Fatalf("Dispatch %d: %v", i, err)
+		}
+	}
+	if n := len(rec.Requests()); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestDispatchRoutesReceivers(t *testing.T) {
+	hookRec, hookSrv := newRecorder(t)
+	slackRec, slackSrv := newRecorder(t)
+	store := &fakeStore{}
+	d, _ := newTestDispatcher(t, store,
+		ChannelConfig{Name: "hook", Type: channelWebhook, URL: hookSrv.URL},
+		ChannelConfig{Name: "ops", Type: channelSlack, URL: slackSrv.URL})
+
+	e := testEvent()
+	e.Receivers = []string{"ops", "unknown"}
+	if err := d.Dispatch(context.Background(), e); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if n := len(hookRec.Requests()); n != 0 {
+		t.Errorf("hook requests = %d, want 0", n)
+	}
+	if n := len(slackRec.Requests()); n != 1 {
+		t.Errorf("slack requests = %d, want 1", n)
+	}
+	if _, ok := store.delivery("hook"); ok {
+		t.Error("hook delivery recorded, want none")
+	}
+}
+
+func TestDispatchCanceledStaysPending(t *testing.T) {
+	_, srv := newRecorder(t, http.StatusServiceUnavailable)
+	store := &fakeStore{}
+	d, _ := newTestDispatcher(t, store, ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	d.sleep = func(context.Context, time.Duration) bool {
+		cancel() // остановка notifier во время паузы
+		return false
+	}
+	if err := d.Dispatch(ctx, testEvent()); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Dispatch = %v, want context.Canceled", err)
+	}
+	got, _ := store.delivery("hook")
+	if got.Status != database.DeliveryPending || got.Attempts != 1 {
+		t.Errorf("delivery = %+v, want pending after 1 attempt", got)
+	}
+}
+
+func TestDispatchUpdateError(t *testing.T) {
+	_, srv := newRecorder(t)
+	store := &fakeStore{updateErr: errors.New("db down")}
+	d, _ := newTestDispatcher(t, store, ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL})
+
+	if err := d.Dispatch(context.Background(), testEvent()); err == nil || !strings.Contains(err.Error(), "db down") {
+		t.Fatalf("Dispatch = %v, want update error", err)
+	}
+}
+
+func TestDispatchResolvedEvent(t *testing.T) {
+	rec, srv := newRecorder(t)
+	d, _ := newTestDispatcher(t, &fakeStore{}, ChannelConfig{Name: "ops", Type: channelSlack, URL: srv.URL})
+
+	e := testEvent()
+	e.Status = tr181.AlertResolved
+	if err := d.Dispatch(context.Background(), e); err != nil {
+		t.Fatalf("Dispatch: %v", err)
+	}
+	if body := string(rec.Requests()[0].Body); !strings.Contains(body, "[RESOLVED] DEV-1: high-cpu-usage") {
+		t.Errorf("body = %s", body)
+	}
+}
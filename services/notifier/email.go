@@ -0,0 +1,121 @@
+// Канал email: письмо через SMTP (STARTTLS, если сервер поддерживает, и PLAIN авторизация).
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// emailSender отправляет письмо получателям канала
+type emailSender struct {
+	smtp    SMTPConfig
+	to      []string
+	msg     *messageTemplate
+	timeout time.Duration
+}
+
+func newEmailSender(c ChannelConfig, msg *messageTemplate) *emailSender {
+	return &emailSender{smtp: c.SMTP, to: c.To, msg: msg, timeout: c.Timeout}
+}
+
+// Send отправляет событие
+func (s *emailSender) Send(ctx context.Context, e tr181.AlertEvent) error {
+	subject, err := s.msg.Subject(e)
+	if err != nil {
+		return err
+	}
+	text, err := s.msg.Text(e)
+	if err != nil {
+		return err
+	}
+	return smtpError(s.send(ctx, s.message(e, subject, text)))
+}
+
+// message собирает письмо (text/plain, UTF-8)
+func (s *emailSender) message(e tr181.AlertEvent, subject, text string) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(&b, "X-Event-ID: %s\r\n", e.ID)
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n\r\n")
+	b.WriteString(strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\n", "\r\n"))
+	b.WriteString("\r\n")
+	return []byte(b.String())
+}
+
+// send — SMTP диалог с таймаутом на всю отправку (smtp.SendMail таймаута не поддерживает)
+func (s *emailSender) send(ctx context.Context, msg []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", s.smtp.Addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	host, _, err := net.SplitHostPort(s.smtp.Addr)
+	if err != nil {
+		conn.Close()
+		return &permanentError{err: err}
+	}
+	c, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.smtp.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.smtp.From); err != nil {
+		return err
+	}
+	for _, to := range s.to {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
+
+// smtpError — ответ 5xx (неверный получатель, отказ в авторизации) повторять бесполезно
+func smtpError(err error) error {
+	var tpErr *textproto.Error
+	if errors.As(err, &tpErr) && tpErr.Code >= 500 {
+		return &permanentError{err: err}
+	}
+	return err
+}
@@ -0,0 +1,223 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// smtpMessage — письмо, принятое fakeSMTP
+type smtpMessage struct {
+	Auth string // AUTH PLAIN: "\x00user\x00password"
+	From string
+	To   []string
+	Data string
+}
+
+// fakeSMTP — SMTP сервер для тестов (без STARTTLS): принимает письма, на RCPT TO отвечает rcptReply
+type fakeSMTP struct {
+	ln        net.Listener
+	rcptReply string
+
+	mu       sync.Mutex
+	messages []smtpMessage
+}
+
+func newFakeSMTP(t *testing.T, rcptReply string) *fakeSMTP {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeSMTP{ln: ln, rcptReply: rcptReply}
+	t.Cleanup(func() { ln.Close() })
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTP) Addr() string { return s.ln.Addr().String() }
+
+func (s *fakeSMTP) Messages() []smtpMessage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpMessage(nil), s.messages...)
+}
+
+func (s *fakeSMTP) serve() {
+	for {
+		conn, err := s.ln.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *fakeSMTP) handle(conn net.Conn) {
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	reply := func(line string) { fmt.Fprintf(conn, "%s\r\n", line) }
+
+	reply("220 fake ESMTP")
+	var msg smtpMessage
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		cmd := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"):
+			reply("250-fake")
+			reply("250 AUTH PLAIN")
+		case strings.HasPrefix(cmd, "AUTH PLAIN "):
+			auth, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			msg.Auth = string(auth)
+			reply("235 2.7.0 Authentication successful")
+		case strings.HasPrefix(cmd, "MAIL FROM:"):
+			msg.From = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			if s.rcptReply != "" {
+				reply(s.rcptReply)
+				continue
+			}
+			msg.To = append(msg.To, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case cmd == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			msg.Data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, msg)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case cmd == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("250 OK")
+		}
+	}
+}
+
+func TestEmailSend(t *testing.T) {
+	srv := newFakeSMTP(t, "")
+	s := testSender(t, ChannelConfig{
+		Name: "mail",
+		Type: channelEmail,
+		SMTP: SMTPConfig{Addr: srv.Addr(), Username: "notifier", Password: "pa55", From: "alerts@example.com"},
+		To:   []string{"ops@example.com", "oncall@example.com"},
+	})
+	e := testEvent()
+	if err := s.Send(context.Background(), e); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	msgs := srv.Messages()
+	if len(msgs) != 1 {
+		t.Fatalf("messages = %d, want 1", len(msgs))
+	}
+	got := msgs[0]
+	if got.Auth != "\x00notifier\x00pa55" {
+		t.Errorf("auth = %q", got.Auth)
+	}
+	if got.From != "alerts@example.com" {
+		t.Errorf("MAIL FROM = %q", got.From)
+	}
+	if want := []string{"ops@example.com", "oncall@example.com"}; strings.Join(got.To, ",") != strings.Join(want, ",") {
+		t.Errorf("RCPT TO = %v, want %v", got.To, want)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(got.Data))
+	if err != nil {
+		t.Fatalf("message: %v", err)
+	}
+	if subject := m.Header.Get("Subject"); subject != "[ERROR] high-cpu-usage on DEV-1" {
+		t.Errorf("Subject = %q", subject)
+	}
+	if id := m.Header.Get("X-Event-ID"); id != e.ID {
+		t.Errorf("X-Event-ID = %q, want %q", id, e.ID)
+	}
+	if ct := m.Header.Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if body := readAll(t, m); strings.TrimSpace(body) != testText {
+		t.Errorf("body = %q, want %q", body, testText)
+	}
+}
+
+func TestEmailRejectedRecipientIsPermanent(t *testing.T) {
+	srv := newFakeSMTP(t, "550 5.1.1 No such user")
+	s := testSender(t, ChannelConfig{
+		Name: "mail",
+		Type: channelEmail,
+		SMTP: SMTPConfig{Addr: srv.Addr(), From: "alerts@example.com"},
+		To:   []string{"nobody@example.com"},
+	})
+	err := s.Send(context.Background(), testEvent())
+	if err == nil || !isPermanent(err) {
+		t.Fatalf("Send = %v, want permanent error", err)
+	}
+}
+
+func TestEmailTemporaryFailure(t *testing.T) {
+	srv := newFakeSMTP(t, "451 4.3.0 Try again later")
+	s := testSender(t, ChannelConfig{
+		Name: "mail",
+		Type: channelEmail,
+		SMTP: SMTPConfig{Addr: srv.Addr(), From: "alerts@example.com"},
+		To:   []string{"ops@example.com"},
+	})
+	err := s.Send(context.Background(), testEvent())
+	if err == nil || isPermanent(err) {
+		t.Fatalf("Send = %v, want temporary error", err)
+	}
+}
+
+func TestEmailUnreachableIsTemporary(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := ln.Addr().String()
+	ln.Close() // соединение не установится
+	s := testSender(t, ChannelConfig{
+		Name:    "mail",
+		Type:    channelEmail,
+		SMTP:    SMTPConfig{Addr: addr, From: "alerts@example.com"},
+		To:      []string{"ops@example.com"},
+		Timeout: time.Second,
+	})
+	if err := s.Send(context.Background(), testEvent()); err == nil || isPermanent(err) {
+		t.Fatalf("Send = %v, want temporary error", err)
+	}
+}
+
+// readAll читает тело письма
+func readAll(t *testing.T, m *mail.Message) string {
+	t.Helper()
+	var b strings.Builder
+	if _, err := bufio.NewReader(m.Body).WriteTo(&b); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	return b.String()
+}
@@ -0,0 +1,121 @@
+// Сервис notifier: читает изменения алертов из Pulsar (топик alerts) и доставляет уведомления в каналы —
+// webhook, Slack, Telegram, email.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	pulsarclient "github.com/apache/pulsar-client-go/pulsar"
+	"golang-test-dev/pkg/database"
+	"golang-test-dev/pkg/logcollector"
+	"golang-test-dev/pkg/pulsar"
+	"golang-test-dev/pkg/tr181"
+)
+
+func main() {
+	// Загружаем конфигурацию из env и каналы из NOTIFY_CHANNELS_FILE
+	cfg, err := LoadConfig()
+	if err != nil {
+		log.Fatalf("config: %v", err)
+	}
+	channels, err := loadChannels(cfg.ChannelsFile)
+	if err != nil {
+		log.Fatalf("channels: %v", err)
+	}
+
+	// Подключаемся к PostgreSQL
+	db, err := database.NewPostgresDB(cfg.PostgresConnStr)
+	if err != nil {
+		log.Fatalf("postgres: %v", err)
+	}
+	defer db.Close()
+
+	// Создаём таблицы (если ещё не созданы)
+	if err := db.InitSchema(context.Background()); err != nil {
+		log.Printf("schema: %v", err)
+	}
+
+	// Подключаемся к Pulsar
+	client, err := pulsar.NewClient(cfg.PulsarURL)
+	if err != nil {
+		log.Fatalf("pulsar: %v", err)
+	}
+	defer client.Close()
+
+	// Лог-producer создаём первым (до consumer) — иначе может не подключиться
+	logColl := logcollector.NewFromClient(client, "notifier", false)
+
+	dispatcher, err := NewDispatcher(db, channels, logColl)
+	if err != nil {
+		log.Fatalf("channels: %v", err)
+	}
+
+	// Подписываемся на топик изменений алертов
+	consumer, err := client.Subscribe(pulsarclient.ConsumerOptions{
+		Topic:            pulsar.TopicAlerts,
+		SubscriptionName: "notifier-sub",
+		Type:             pulsarclient.Shared, // Shared — несколько экземпляров делят нагрузку
+	})
+	if err != nil {
+		log.Fatalf("consumer: %v", err)
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, cfg.Workers) // не больше Workers событий одновременно
+
+	// Горутина: приём сообщений, каждое доставляется в своей горутине
+	go func() {
+		for {
+			msg, err := consumer.Receive(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("receive: %v", err)
+				time.Sleep(time.Second)
+				continue
+			}
+			var event tr181.AlertEvent
+			if err := json.Unmarshal(msg.Payload(), &event); err != nil || event.ID == "" {
+				log.Printf("invalid alert event: %v", err)
+				consumer.Ack(msg) // повтор не поможет
+				continue
+			}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			wg.Add(1)
+			go func() {
+				defer func() { <-sem; wg.Done() }()
+				if err := dispatcher.Dispatch(ctx, event); err != nil {
+					log.Printf("dispatch %s: %v", event.ID, err)
+					consumer.Nack(msg)
+					return
+				}
+				consumer.Ack(msg)
+			}()
+		}
+	}()
+
+	log.Printf("notifier started: %d channels", len(channels))
+
+	// Ожидаем сигнал завершения
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Println("shutting down")
+	cancel()
+	wg.Wait()
+}
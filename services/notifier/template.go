@@ -0,0 +1,69 @@
+// Шаблоны текста уведомлений (text/template над tr181.AlertEvent).
+package main
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// defaultTextTemplate — текст уведомления по умолчанию
+const defaultTextTemplate = `{{if eq .Status "resolved"}}[RESOLVED] {{.SerialNumber}}: {{.Type}}` +
+	`{{else}}[{{upper .Severity}}] {{.SerialNumber}}: {{.Type}} value={{.Value}}{{with .Metric}} ({{.}}){{end}}{{end}}` +
+	` at {{.Time.UTC.Format "2006-01-02 15:04:05"}} UTC`
+
+// defaultSubjectTemplate — тема письма по умолчанию
+const defaultSubjectTemplate = `[{{if eq .Status "resolved"}}RESOLVED{{else}}{{upper .Severity}}{{end}}] {{.Type}} on {{.SerialNumber}}`
+
+// templateFuncs — функции, доступные в шаблонах
+var templateFuncs = template.FuncMap{
+	"upper": func(v any) string { return strings.ToUpper(fmt.Sprint(v)) },
+	"lower": func(v any) string { return strings.ToLower(fmt.Sprint(v)) },
+}
+
+// messageTemplate — шаблоны текста и темы канала
+type messageTemplate struct {
+	text    *template.Template
+	subject *template.Template
+}
+
+// newMessageTemplate разбирает шаблоны канала (пустые — по умолчанию)
+func newMessageTemplate(text, subject string) (*messageTemplate, error) {
+	if text == "" {
+		text = defaultTextTemplate
+	}
+	if subject == "" {
+		subject = defaultSubjectTemplate
+	}
+	t, err := template.New("text").Funcs(templateFuncs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	s, err := template.New("subject").Funcs(templateFuncs).Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("subject: %w", err)
+	}
+	return &messageTemplate{text: t, subject: s}, nil
+}
+
+// Text возвращает текст уведомления о событии
+func (m *messageTemplate) Text(e tr181.AlertEvent) (string, error) {
+	return execute(m.text, e)
+}
+
+// Subject возвращает тему письма (одной строкой)
+func (m *messageTemplate) Subject(e tr181.AlertEvent) (string, error) {
+	s, err := execute(m.subject, e)
+	return strings.Join(strings.Fields(s), " "), err
+}
+
+// execute выполняет шаблон; ошибка шаблона — постоянная: повтор даст тот же результат
+func execute(t *template.Template, e tr181.AlertEvent) (string, error) {
+	var b strings.Builder
+	if err := t.Execute(&b, e); err != nil {
+		return "", &permanentError{err: fmt.Errorf("template: %w", err)}
+	}
+	return b.String(), nil
+}
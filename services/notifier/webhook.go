@@ -0,0 +1,163 @@
+// HTTP каналы: webhook (JSON события с подписью), Slack и Telegram.
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// defaultTelegramURL — адрес Bot API
+const defaultTelegramURL = "https://api.telegram.org"
+
+// webhookPayload — тело webhook: событие и готовый текст
+type webhookPayload struct {
+	tr181.AlertEvent
+	Text string `json:"text"`
+}
+
+// webhookSender отправляет JSON события. С secret тело подписывается: заголовок
+// X-Signature-256: sha256=<hex HMAC-SHA256 тела>; X-Event-ID — для идемпотентности на стороне получателя
+type webhookSender struct {
+	url    string
+	secret []byte
+	msg    *messageTemplate
+	client *http.Client
+}
+
+func newWebhookSender(c ChannelConfig, msg *messageTemplate) *webhookSender {
+	return &webhookSender{url: c.URL, secret: []byte(c.Secret), msg: msg, client: &http.Client{Timeout: c.Timeout}}
+}
+
+// Send отправляет событие
+func (s *webhookSender) Send(ctx context.Context, e tr181.AlertEvent) error {
+	text, err := s.msg.Text(e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(webhookPayload{AlertEvent: e, Text: text})
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	headers := map[string]string{"X-Event-ID": e.ID}
+	if len(s.secret) > 0 {
+		headers["X-Signature-256"] = "sha256=" + sign(s.secret, body)
+	}
+	return postJSON(ctx, s.client, s.url, body, headers)
+}
+
+// sign возвращает hex HMAC-SHA256 тела
+func sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// slackSender отправляет текст в incoming webhook Slack
+type slackSender struct {
+	url    string
+	msg    *messageTemplate
+	client *http.Client
+}
+
+func newSlackSender(c ChannelConfig, msg *messageTemplate) *slackSender {
+	return &slackSender{url: c.URL, msg: msg, client: &http.Client{Timeout: c.Timeout}}
+}
+
+// Send отправляет событие
+func (s *slackSender) Send(ctx context.Context, e tr181.AlertEvent) error {
+	text, err := s.msg.Text(e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	return postJSON(ctx, s.client, s.url, body, nil)
+}
+
+// telegramSender отправляет текст через Bot API sendMessage
+type telegramSender struct {
+	url    string // {api}/bot{token}/sendMessage
+	chatID string
+	msg    *messageTemplate
+	client *http.Client
+}
+
+func newTelegramSender(c ChannelConfig, msg *messageTemplate) *telegramSender {
+	api := c.URL
+	if api == "" {
+		api = defaultTelegramURL
+	}
+	return &telegramSender{
+		url:    strings.TrimRight(api, "/") + "/bot" + c.Token + "/sendMessage",
+		chatID: c.ChatID,
+		msg:    msg,
+		client: &http.Client{Timeout: c.Timeout},
+	}
+}
+
+// Send отправляет событие
+func (s *telegramSender) Send(ctx context.Context, e tr181.AlertEvent) error {
+	text, err := s.msg.Text(e)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(map[string]string{"chat_id": s.chatID, "text": text})
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	return postJSON(ctx, s.client, s.url, body, nil)
+}
+
+// postJSON отправляет POST с JSON телом. Ответ 4xx (кроме 408 и 429) — постоянная ошибка,
+// 5xx и сетевые ошибки — временные
+func postJSON(ctx context.Context, client *http.Client, target string, body []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return &permanentError{err: err}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "tr181-notifier")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return redactURL(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body) // соединение переиспользуется
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err = fmt.Errorf("http %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+		resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+		return &permanentError{err: err}
+	}
+	return err
+}
+
+// redactURL убирает адрес из ошибки HTTP клиента: в нём может быть токен (telegram, slack),
+// а ошибка пишется в лог и notification_deliveries
+func redactURL(err error) error {
+	var uerr *url.Error
+	if errors.As(err, &uerr) {
+		return fmt.Errorf("%s: %w", uerr.Op, uerr.Err)
+	}
+	return err
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang-test-dev/pkg/tr181"
+)
+
+// testEvent — событие для тестов каналов
+func testEvent() tr181.AlertEvent {
+	return tr181.AlertEvent{
+		ID:           "DEV-1:high-cpu-usage:firing:1704103200000",
+		Status:       tr181.AlertFiring,
+		SerialNumber: "DEV-1",
+		Type:         tr181.AlertHighCPUUsage,
+		Severity:     tr181.SeverityError,
+		Value:        93,
+		Metric:       tr181.MetricCPUUsage,
+		Time:         time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC),
+	}
+}
+
+// testText — текст testEvent по шаблону по умолчанию
+const testText = "[ERROR] DEV-1: high-cpu-usage value=93 (cpu-usage) at 2024-01-01 10:00:00 UTC"
+
+// recordedRequest — запрос, полученный тестовым сервером
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   []byte
+}
+
+// recorder — тестовый HTTP сервер: отвечает статусами statuses по очереди (дальше — последним)
+// и запоминает запросы
+type recorder struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []recordedRequest
+}
+
+func newRecorder(t *testing.T, statuses ...int) (*recorder, *httptest.Server) {
+	rec := &recorder{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, recordedRequest{Path: r.URL.Path, Header: r.Header.Clone(), Body: body})
+		status := http.StatusOK
+		if n := len(rec.requests); len(rec.statuses) >= n {
+			status = rec.statuses[n-1]
+		} else if len(rec.statuses) > 0 {
+			status = rec.statuses[len(rec.statuses)-1]
+		}
+		rec.mu.Unlock()
+		w.WriteHeader(status)
+		io.WriteString(w, http.StatusText(status))
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv
+}
+
+func (r *recorder) Requests() []recordedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]recordedRequest(nil), r.requests...)
+}
+
+// testSender создаёт отправку канала c (с проверкой и значениями по умолчанию)
+func testSender(t *testing.T, c ChannelConfig) Sender {
+	t.Helper()
+	if err := c.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	s, err := newSender(c)
+	if err != nil {
+		t.Fatalf("newSender: %v", err)
+	}
+	return s
+}
+
+func TestWebhookSignature(t *testing.T) {
+	rec, srv := newRecorder(t)
+	s := testSender(t, ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL, Secret: "s3cret"})
+	e := testEvent()
+	if err := s.Send(context.Background(), e); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	reqs := rec.Requests()
+	if len(reqs) != 1 {
+		t.Fatalf("requests = %d, want 1", len(reqs))
+	}
+	req := reqs[0]
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write(req.Body)
+	if got, want := req.Header.Get("X-Signature-256"), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("X-Signature-256 = %q, want %q", got, want)
+	}
+	if got := req.Header.Get("X-Event-ID"); got != e.ID {
+		t.Errorf("X-Event-ID = %q, want %q", got, e.ID)
+	}
+	if got := req.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q", got)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(req.Body, &payload); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if payload.ID != e.ID || payload.SerialNumber != e.SerialNumber || payload.Value != e.Value || !payload.Time.Equal(e.Time) {
+		t.Errorf("payload event = %+v, want %+v", payload.AlertEvent, e)
+	}
+	if payload.Text != testText {
+		t.Errorf("payload text = %q, want %q", payload.Text, testText)
+	}
+}
+
+func TestWebhookWithoutSecret(t *testing.T) {
+	rec, srv := newRecorder(t)
+	s := testSender(t, ChannelConfig{Name: "hook", Type: channelWebhook, URL: srv.URL})
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if sig := rec.Requests()[0].Header.Get("X-Signature-256"); sig != "" {
+		t.Errorf("X-Signature-256 = %q, want none", sig)
+	}
+}
+
+func TestSlackSend(t *testing.T) {
+	rec, srv := newRecorder(t)
+	s := testSender(t, ChannelConfig{Name: "ops", Type: channelSlack, URL: srv.URL + "/services/T0/B0/X0"})
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := rec.Requests()[0]
+	if req.Path != "/services/T0/B0/X0" {
+		t.Errorf("path = %q", req.Path)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if body["text"] != testText || len(body) != 1 {
+		t.Errorf("body = %v, want text %q", body, testText)
+	}
+}
+
+func TestSlackCustomTemplate(t *testing.T) {
+	rec, srv := newRecorder(t)
+	s := testSender(t, ChannelConfig{Name: "ops", Type: channelSlack, URL: srv.URL,
+		Template: `{{.SerialNumber}} {{lower .Severity}} {{.Value}}`})
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	var body map[string]string
+	json.Unmarshal(rec.Requests()[0].Body, &body)
+	if body["text"] != "DEV-1 error 93" {
+		t.Errorf("text = %q", body["text"])
+	}
+}
+
+func TestTelegramSend(t *testing.T) {
+	rec, srv := newRecorder(t)
+	s := testSender(t, ChannelConfig{Name: "tg", Type: channelTelegram, URL: srv.URL + "/", Token: "123:ABC", ChatID: "-100500"})
+	if err := s.Send(context.Background(), testEvent()); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+
+	req := rec.Requests()[0]
+	if req.Path != "/bot123:ABC/sendMessage" {
+		t.Errorf("path = %q", req.Path)
+	}
+	var body map[string]string
+	if err := json.Unmarshal(req.Body, &body); err != nil {
+		t.Fatalf("body: %v", err)
+	}
+	if body["chat_id"] != "-100500" || body["text"] != testText {
+		t.Errorf("body = %v", body)
+	}
+}
+
+func TestTelegramErrorRedactsToken(t *testing.T) {
+	_, srv := newRecorder(t)
+	srv.Close() // соединение не установится
+	s := testSender(t, ChannelConfig{Name: "tg", Type: channelTelegram, URL: srv.URL, Token: "123:SECRET", ChatID: "1"})
+	err := s.Send(context.Background(), testEvent())
+	if err == nil {
+		t.Fatal("Send: want error")
+	}
+	if strings.Contains(err.Error(), "SECRET") {
+		t.Errorf("error contains token: %v", err)
+	}
+	if isPermanent(err) {
+		t.Errorf("connection error must be temporary: %v", err)
+	}
+}
+
+func TestPostJSONStatus(t *testing.T) {
+	tests := []struct {
+		status    int
+		wantErr   bool
+		permanent bool
+	}{
+		{http.StatusOK, false, false},
+		{http.StatusNoContent, false, false},
+		{http.StatusBadRequest, true, true},
+		{http.StatusForbidden, true, true},
+		{http.StatusNotFound, true, true},
+		{http.StatusRequestTimeout, true, false},
+		{http.StatusTooManyRequests, true, false},
+		{http.StatusInternalServerError, true, false},
+		{http.StatusServiceUnavailable, true, false},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			_, srv := newRecorder(t, tt.status)
+			err := postJSON(context.Background(), srv.Client(), srv.URL, []byte(`{}`), nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && isPermanent(err) != tt.permanent {
+				t.Errorf("permanent = %v, want %v (%v)", isPermanent(err), tt.permanent, err)
+			}
+		})
+	}
+}
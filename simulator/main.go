@@ -178,7 +178,7 @@ func (s *Simulator) generateAndQueueData(device *Device) {
 	if rand.Float32() < 0.05 {
 		data.CPUUsage = 65 + rand.Intn(30) // 65-95%
 	}
-	// 3% вероятность - слабый WiFi (для генерации алерта low-wifi)
+	// 3% вероятность - слабый WiFi (для генерации алертов low-wifi-2ghz)
 	if rand.Float32() < 0.03 {
 		data.WiFi2GHzSignalStrength = -105 + rand.Intn(5) // -105..-100 dBm
 	}